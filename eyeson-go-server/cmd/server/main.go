@@ -6,12 +6,21 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"eyeson-go-server/internal/config"
 	"eyeson-go-server/internal/database"
 	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/handlers"
 	"eyeson-go-server/internal/jobs"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+	"eyeson-go-server/internal/reactive"
 	"eyeson-go-server/internal/routes"
 	"eyeson-go-server/internal/services"
 	"eyeson-go-server/internal/syncer"
@@ -19,6 +28,9 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+// shutdownTimeout - сколько ждать завершения HTTP-запросов и logout при остановке
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -29,7 +41,7 @@ func main() {
 	// Connect to local database (DB-first architecture)
 	database.Connect(cfg)
 
-	// Resolve upstream selection (persisted in DB). Switching applies after restart.
+	// Resolve upstream selection (persisted in DB). PUT /upstream switches it at runtime.
 	selectedUpstream, err := services.GetUpstreamSelected()
 	if err != nil {
 		log.Printf("[Upstream] WARNING: could not read selection, defaulting to pelephone: %v", err)
@@ -38,24 +50,107 @@ func main() {
 	resolvedBaseURL := services.ResolveUpstreamBaseURL(cfg, selectedUpstream)
 	log.Printf("[Upstream] Selected=%s BaseURL=%s", selectedUpstream, resolvedBaseURL)
 
-	// Initialize EyesOnT API client (connects to external Pelephone server or simulator)
-	eyesont.InitWithConfig(resolvedBaseURL, cfg.ApiUsername, cfg.ApiPassword, cfg.ApiDelayMs, cfg.ApiInsecureTLS)
+	// Record/replay of EyesOnT traffic (replay runs the server from a cassette with no network)
+	if err := eyesont.ConfigureCassette(eyesont.CassetteMode(cfg.ApiCassetteMode), cfg.ApiCassettePath); err != nil {
+		log.Fatalf("Could not configure API cassette: %v", err)
+	}
+
+	// Circuit breaker per upstream: worker/syncer wait instead of failing every request while it is open
+	eyesont.ConfigureBreakers(cfg.BreakerFailureThreshold, time.Duration(cfg.BreakerOpenSec)*time.Second)
+	eyesont.SetBreakerListener(func(snapshot eyesont.BreakerSnapshot, from eyesont.BreakerState) {
+		if broadcaster := handlers.GetEventBroadcaster(); broadcaster != nil {
+			broadcaster.Emit(reactive.EventUpstreamCircuit, fiber.Map{
+				"upstream": snapshot.Name,
+				"from":     from,
+				"state":    snapshot.State,
+				"breaker":  snapshot,
+			}, "")
+		}
+	})
+
+	// Upstream accounts: credentials are stored encrypted; on first start the default
+	// account is seeded from EYESON_API_USERNAME / EYESON_API_PASSWORD
+	if err := services.Accounts.Configure(cfg, resolvedBaseURL); err != nil {
+		log.Fatalf("Could not initialize upstream accounts: %v", err)
+	}
+
+	// Initialize upstream provider of the default account (EyesOnT: external Pelephone server or simulator)
+	active, err := services.Accounts.Provider(0)
+	if err != nil {
+		log.Fatalf("Could not initialize upstream provider: %v", err)
+	}
+	provider.SetActive(active)
+	log.Printf("[Upstream] Provider=%s", active.Name())
+
+	if client, ok := active.(*eyesont.Client); ok {
+		eyesont.Use(client)
+	}
+
+	// Контекст сервиса: отменяется по SIGINT/SIGTERM и прерывает фоновые запросы к провайдеру
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	handlers.SetBaseContext(ctx)
+
+	// SIMs that disappeared upstream are tombstoned after a grace period and announced over SSE
+	syncer.ConfigureTombstones(time.Duration(cfg.SyncDeleteGraceHours) * time.Hour)
+	syncer.SetDeletedListener(func(sims []models.SimCard) {
+		if broadcaster := handlers.GetEventBroadcaster(); broadcaster != nil {
+			for _, sim := range sims {
+				broadcaster.Emit(reactive.EventSimDeleted, fiber.Map{
+					"id":            sim.ID,
+					"msisdn":        sim.MSISDN,
+					"cli":           sim.CLI,
+					"account_id":    sim.AccountID,
+					"missing_since": sim.MissingSince,
+					"reason":        "missing upstream",
+				}, "")
+			}
+		}
+		handlers.InvalidateStatsCache()
+	})
+
+	// Full sweeps fetch pages concurrently; the client's rate limiter still paces the requests
+	syncer.ConfigureFetch(cfg.SyncFetchWorkers)
+
+	// Sync keeps values written by in-flight tasks until the provider reports them
+	services.Queue.ConflictWindow = time.Duration(cfg.SyncConflictWindowMin) * time.Minute
 
 	// Start background sync service (synchronizes data from API to local DB)
 	syncService := syncer.New(database.DB)
-	syncService.Start()
+	syncService.Interval = time.Duration(cfg.SyncIntervalMin) * time.Minute
+	syncService.FullInterval = time.Duration(cfg.SyncFullIntervalMin) * time.Minute
+	syncService.Start(ctx)
+	handlers.SetScheduledSyncer(syncService)
 
 	// Start job worker (processes queued tasks)
 	jobWorker := jobs.New(database.DB)
-	jobWorker.Start()
+	jobWorker.Start(ctx)
+
+	// Start provider job reconciler (confirms AWAITING_PROVIDER tasks via getProvisioningJobList)
+	jobs.NewProviderReconciler(jobWorker).Start(ctx)
 
 	// Create and configure Fiber app
 	app := fiber.New()
 	routes.SetupRoutes(app, cfg)
 
+	// Graceful shutdown: останавливаем HTTP сервер по SIGINT/SIGTERM
+	go func() {
+		<-ctx.Done()
+		log.Println("Shutdown signal received, stopping server...")
+		if err := app.ShutdownWithTimeout(shutdownTimeout); err != nil {
+			log.Printf("Server shutdown error: %v", err)
+		}
+	}()
+
 	// Start HTTP server
 	log.Printf("Server starting on port %s", cfg.Port)
 	if err := app.Listen(":" + cfg.Port); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
+
+	// Закрываем сессии всех аккаунтов (не дольше shutdownTimeout, даже если провайдер не отвечает)
+	logoutCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	services.Accounts.Close(logoutCtx)
+	log.Println("Server stopped")
 }
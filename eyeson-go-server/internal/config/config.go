@@ -25,11 +25,63 @@ type Config struct {
 	JwtSecret        string
 	ApiDelayMs       int
 
+	// Token bucket клиента: запросов в секунду (0 - из EYESON_API_DELAY_MS) и запас burst
+	ApiRatePerSec int
+	ApiRateBurst  int
+
+	// Максимум абонентов в одном action updateProvisioningData; больше - отправляется частями
+	ApiBulkChunkSize int
+
+	// Имя провайдера в реестре internal/provider (pelephone, ...)
+	Provider string
+
+	// Ключ шифрования паролей upstream-аккаунтов (пусто - производный от JWT_SECRET, только в dev)
+	CredentialsKey string
+
 	CorsAllowOrigins string
 	EnableSwagger    bool
 
 	ApiInsecureTLS bool
 
+	// Исходящий прокси к upstream (http://, https://, socks5://) и исключения (формат NO_PROXY)
+	ApiProxyURL string
+	ApiNoProxy  string
+
+	// PEM-бандл корневых сертификатов upstream (вместо EYESON_API_INSECURE_TLS)
+	ApiCAFile string
+
+	// Клиентский сертификат/ключ для mTLS (PEM)
+	ApiClientCertFile string
+	ApiClientKeyFile  string
+
+	// Сессия EyesOnT переоткрывается, когда становится старше этого значения
+	ApiSessionMaxAgeMin int
+
+	// Дедлайн одного запроса к EyesOnT (секунды)
+	ApiRequestTimeoutSec int
+
+	// Запись/воспроизведение обращений к EyesOnT: off | record | replay
+	ApiCassetteMode string
+	ApiCassettePath string
+
+	// Circuit breaker: сколько сбоев подряд открывают его и на сколько секунд
+	BreakerFailureThreshold int
+	BreakerOpenSec          int
+
+	// Плановая синхронизация: период цикла и период полного обхода (минуты).
+	// Между полными обходами циклы инкрементальные; SyncFullIntervalMin = 0 - всегда полный.
+	SyncIntervalMin     int
+	SyncFullIntervalMin int
+
+	// Сколько часов SIM может отсутствовать у провайдера до удаления (0 - не удалять)
+	SyncDeleteGraceHours int
+
+	// Сколько страниц аккаунта полный обход загружает параллельно
+	SyncFetchWorkers int
+
+	// Сколько минут после завершения задачи синхронизация не откатывает изменённые ею поля
+	SyncConflictWindowMin int
+
 	SeedDefaultAdmin     bool
 	DefaultAdminPassword string
 
@@ -62,12 +114,40 @@ func LoadConfig() (*Config, error) {
 		ApiPassword:      getEnv("EYESON_API_PASSWORD", "admin"),
 		JwtSecret:        getEnv("JWT_SECRET", "change-me-in-prod"),
 		ApiDelayMs:       getEnvInt("EYESON_API_DELAY_MS", 10),
+		ApiRatePerSec:    getEnvInt("EYESON_API_RATE_PER_SEC", 0),
+		ApiRateBurst:     getEnvInt("EYESON_API_RATE_BURST", 1),
+		ApiBulkChunkSize: getEnvInt("EYESON_API_BULK_CHUNK_SIZE", 100),
+		Provider:         strings.ToLower(strings.TrimSpace(getEnv("EYESON_PROVIDER", "pelephone"))),
+		CredentialsKey:   getEnv("EYESON_CREDENTIALS_KEY", ""),
 
 		CorsAllowOrigins: getEnv("EYESON_CORS_ORIGINS", "http://localhost:5173,http://127.0.0.1:5173,http://localhost:5000,http://127.0.0.1:5000"),
 		EnableSwagger:    getEnvBool("EYESON_ENABLE_SWAGGER", appEnv == "dev"),
 
 		ApiInsecureTLS: getEnvBool("EYESON_API_INSECURE_TLS", appEnv == "dev"),
 
+		ApiProxyURL:       strings.TrimSpace(getEnv("EYESON_API_PROXY_URL", "")),
+		ApiNoProxy:        getEnv("EYESON_API_NO_PROXY", ""),
+		ApiCAFile:         strings.TrimSpace(getEnv("EYESON_API_CA_FILE", "")),
+		ApiClientCertFile: strings.TrimSpace(getEnv("EYESON_API_CLIENT_CERT_FILE", "")),
+		ApiClientKeyFile:  strings.TrimSpace(getEnv("EYESON_API_CLIENT_KEY_FILE", "")),
+
+		ApiSessionMaxAgeMin:  getEnvInt("EYESON_API_SESSION_MAX_AGE_MIN", 25),
+		ApiRequestTimeoutSec: getEnvInt("EYESON_API_REQUEST_TIMEOUT_SEC", 30),
+
+		ApiCassetteMode: strings.ToLower(strings.TrimSpace(getEnv("EYESON_API_CASSETTE_MODE", "off"))),
+		ApiCassettePath: getEnv("EYESON_API_CASSETTE_PATH", "cassettes/eyesont.json"),
+
+		BreakerFailureThreshold: getEnvInt("EYESON_BREAKER_FAILURE_THRESHOLD", 5),
+		BreakerOpenSec:          getEnvInt("EYESON_BREAKER_OPEN_SEC", 30),
+
+		SyncIntervalMin:     getEnvInt("EYESON_SYNC_INTERVAL_MIN", 5),
+		SyncFullIntervalMin: getEnvInt("EYESON_SYNC_FULL_INTERVAL_MIN", 60),
+
+		SyncDeleteGraceHours: getEnvInt("EYESON_SYNC_DELETE_GRACE_HOURS", 24),
+		SyncFetchWorkers:     getEnvInt("EYESON_SYNC_FETCH_WORKERS", 4),
+
+		SyncConflictWindowMin: getEnvInt("EYESON_SYNC_CONFLICT_WINDOW_MIN", 10),
+
 		SeedDefaultAdmin:     getEnvBool("EYESON_SEED_DEFAULT_ADMIN", appEnv == "dev"),
 		DefaultAdminPassword: getEnv("EYESON_DEFAULT_ADMIN_PASSWORD", "admin"),
 
@@ -86,6 +166,11 @@ func LoadConfig() (*Config, error) {
 }
 
 func (c *Config) Validate() error {
+	// Сертификат без ключа (и наоборот) - ошибка в любом окружении
+	if (c.ApiClientCertFile == "") != (c.ApiClientKeyFile == "") {
+		return fmt.Errorf("EYESON_API_CLIENT_CERT_FILE and EYESON_API_CLIENT_KEY_FILE must be set together")
+	}
+
 	// In dev we allow convenience defaults (but other layers should still be safe-by-default).
 	if c.AppEnv == "dev" {
 		return nil
@@ -95,6 +180,9 @@ func (c *Config) Validate() error {
 	if strings.TrimSpace(c.JwtSecret) == "" || c.JwtSecret == "change-me-in-prod" {
 		return fmt.Errorf("JWT_SECRET is not set or uses default; refusing to start in prod")
 	}
+	if strings.TrimSpace(c.CredentialsKey) == "" {
+		return fmt.Errorf("EYESON_CREDENTIALS_KEY is not set; refusing to start in prod (set it to the current JWT_SECRET to keep existing accounts readable)")
+	}
 	if c.ApiInsecureTLS {
 		return fmt.Errorf("EYESON_API_INSECURE_TLS must be false in prod")
 	}
@@ -39,6 +39,11 @@ func Connect(cfg *config.Config) {
 		&models.SimHistory{},
 		&models.AuditLog{},
 		&models.SyncTaskExtended{},
+		&models.TaskChunk{},
+		&models.SpareSim{},
+		&models.UpstreamAccount{},
+		&models.SyncPage{},
+		&models.SyncRun{},
 	)
 	if err != nil {
 		log.Fatalf("Migration failed: %v", err)
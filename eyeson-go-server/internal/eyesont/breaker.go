@@ -0,0 +1,286 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════
+// CIRCUIT BREAKER
+// ═══════════════════════════════════════════════════════════
+
+// BreakerState - состояние circuit breaker'а
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "CLOSED"    // Запросы идут как обычно
+	BreakerOpen     BreakerState = "OPEN"      // Провайдер недоступен, запросы не отправляются
+	BreakerHalfOpen BreakerState = "HALF_OPEN" // Пробный запрос после паузы
+)
+
+// Значения по умолчанию (EYESON_BREAKER_FAILURE_THRESHOLD / EYESON_BREAKER_OPEN_SEC)
+const (
+	DefaultBreakerFailureThreshold = 5
+	DefaultBreakerOpenTimeout      = 30 * time.Second
+)
+
+// BreakerSnapshot - состояние breaker'а для диагностики и SSE
+type BreakerSnapshot struct {
+	Name                string       `json:"name"`
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	FailureThreshold    int          `json:"failure_threshold"`
+	Trips               int          `json:"trips"`
+	LastError           string       `json:"last_error,omitempty"`
+	ChangedAt           time.Time    `json:"changed_at"`
+	RetryAt             *time.Time   `json:"retry_at,omitempty"`
+}
+
+// CircuitBreaker защищает провайдера от потока запросов, пока он недоступен.
+// После FailureThreshold сбоев подряд (сеть, 5xx) breaker открывается на OpenTimeout,
+// затем пропускает один пробный запрос: успех закрывает его, сбой - открывает снова.
+// Один breaker на upstream (BaseURL) - общий для всех аккаунтов, syncer'а, worker'а и диагностики.
+type CircuitBreaker struct {
+	Name             string
+	FailureThreshold int
+	OpenTimeout      time.Duration
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	changedAt time.Time
+	probing   bool
+	trips     int
+	lastError string
+}
+
+// BreakerListener получает уведомление о смене состояния (SSE подключается в main)
+type BreakerListener func(snapshot BreakerSnapshot, from BreakerState)
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*CircuitBreaker{}
+
+	breakerThreshold   = DefaultBreakerFailureThreshold
+	breakerOpenTimeout = DefaultBreakerOpenTimeout
+	breakerListener    BreakerListener
+)
+
+// ConfigureBreakers задаёт параметры breaker'ов (в т.ч. уже созданных); 0 - значение по умолчанию
+func ConfigureBreakers(failureThreshold int, openTimeout time.Duration) {
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultBreakerFailureThreshold
+	}
+	if openTimeout <= 0 {
+		openTimeout = DefaultBreakerOpenTimeout
+	}
+
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breakerThreshold = failureThreshold
+	breakerOpenTimeout = openTimeout
+	for _, b := range breakers {
+		b.mu.Lock()
+		b.FailureThreshold = failureThreshold
+		b.OpenTimeout = openTimeout
+		b.mu.Unlock()
+	}
+}
+
+// SetBreakerListener подписывает listener на смену состояний всех breaker'ов
+func SetBreakerListener(listener BreakerListener) {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breakerListener = listener
+}
+
+// BreakerFor возвращает breaker upstream'а (создаёт при первом обращении)
+func BreakerFor(baseURL string) *CircuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	if b, ok := breakers[baseURL]; ok {
+		return b
+	}
+	b := &CircuitBreaker{
+		Name:             baseURL,
+		FailureThreshold: breakerThreshold,
+		OpenTimeout:      breakerOpenTimeout,
+		state:            BreakerClosed,
+		changedAt:        time.Now(),
+	}
+	breakers[baseURL] = b
+	return b
+}
+
+// Breakers возвращает состояние всех breaker'ов
+func Breakers() []BreakerSnapshot {
+	breakersMu.Lock()
+	list := make([]*CircuitBreaker, 0, len(breakers))
+	for _, b := range breakers {
+		list = append(list, b)
+	}
+	breakersMu.Unlock()
+
+	snapshots := make([]BreakerSnapshot, 0, len(list))
+	for _, b := range list {
+		snapshots = append(snapshots, b.Snapshot())
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Name < snapshots[j].Name })
+	return snapshots
+}
+
+// Allow решает, можно ли отправить запрос. В OPEN возвращает ошибку ErrKindCircuitOpen;
+// по истечении OpenTimeout переводит breaker в HALF_OPEN и пропускает один пробный запрос.
+func (b *CircuitBreaker) Allow(op string) error {
+	b.mu.Lock()
+	var from BreakerState
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.OpenTimeout {
+			b.mu.Unlock()
+			return b.openError(op)
+		}
+		from = b.transitionLocked(BreakerHalfOpen)
+		b.probing = true
+	case BreakerHalfOpen:
+		if b.probing {
+			b.mu.Unlock()
+			return b.openError(op)
+		}
+		b.probing = true
+	}
+	b.mu.Unlock()
+
+	if from != "" {
+		b.notify(from)
+	}
+	return nil
+}
+
+// Ready - запрос сейчас будет пропущен (CLOSED, либо пауза OPEN истекла и проба свободна).
+// Worker и syncer не берут работу, пока Ready() = false.
+func (b *CircuitBreaker) Ready() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		return time.Since(b.openedAt) >= b.OpenTimeout
+	case BreakerHalfOpen:
+		return !b.probing
+	}
+	return true
+}
+
+// Record учитывает результат запроса: nil - успех, иначе сбой upstream'а
+func (b *CircuitBreaker) Record(err error) {
+	b.mu.Lock()
+	var from BreakerState
+	if err == nil {
+		b.failures = 0
+		b.probing = false
+		if b.state != BreakerClosed {
+			from = b.transitionLocked(BreakerClosed)
+		}
+	} else {
+		b.failures++
+		b.lastError = err.Error()
+		halfOpen := b.state == BreakerHalfOpen
+		b.probing = false
+		if halfOpen || (b.state == BreakerClosed && b.failures >= b.FailureThreshold) {
+			from = b.transitionLocked(BreakerOpen)
+			b.openedAt = time.Now()
+			b.trips++
+		}
+	}
+	b.mu.Unlock()
+
+	if from != "" {
+		b.notify(from)
+	}
+}
+
+// release снимает флаг пробы, если запрос прерван до ответа (отмена ctx)
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+// State возвращает текущее состояние
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Snapshot возвращает состояние для диагностики
+func (b *CircuitBreaker) Snapshot() BreakerSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.snapshotLocked()
+}
+
+func (b *CircuitBreaker) snapshotLocked() BreakerSnapshot {
+	s := BreakerSnapshot{
+		Name:                b.Name,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		FailureThreshold:    b.FailureThreshold,
+		Trips:               b.trips,
+		LastError:           b.lastError,
+		ChangedAt:           b.changedAt,
+	}
+	if b.state == BreakerOpen {
+		retryAt := b.openedAt.Add(b.OpenTimeout)
+		s.RetryAt = &retryAt
+	}
+	return s
+}
+
+// transitionLocked меняет состояние и возвращает предыдущее
+func (b *CircuitBreaker) transitionLocked(to BreakerState) BreakerState {
+	from := b.state
+	b.state = to
+	b.changedAt = time.Now()
+	log.Printf("[EyesOnT API] Circuit breaker %s: %s -> %s (failures=%d)", b.Name, from, to, b.failures)
+	return from
+}
+
+func (b *CircuitBreaker) notify(from BreakerState) {
+	breakersMu.Lock()
+	listener := breakerListener
+	breakersMu.Unlock()
+	if listener != nil {
+		listener(b.Snapshot(), from)
+	}
+}
+
+func (b *CircuitBreaker) openError(op string) *APIError {
+	return &APIError{Kind: ErrKindCircuitOpen, Op: op, Message: "circuit breaker open for " + b.Name, Retryable: true}
+}
+
+// isBreakerFailure - сбои, говорящие о недоступности upstream'а. Отказы по бизнес-логике
+// (result != SUCCESS, валидация) и отмена вызывающим breaker не открывают.
+func isBreakerFailure(statusCode int, err error) bool {
+	if err != nil {
+		return !IsCanceled(err)
+	}
+	return statusCode >= http.StatusInternalServerError
+}
+
+// IsCircuitOpen - запрос не отправлен, потому что breaker открыт
+func IsCircuitOpen(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.Kind == ErrKindCircuitOpen
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(threshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Name: "test", FailureThreshold: threshold, OpenTimeout: openTimeout, state: BreakerClosed}
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	b := newTestBreaker(3, time.Hour)
+	failure := errors.New("connection refused")
+
+	for i := 0; i < 2; i++ {
+		b.Record(failure)
+	}
+	b.Record(nil) // Успех сбрасывает счётчик
+	for i := 0; i < 2; i++ {
+		b.Record(failure)
+	}
+	if b.State() != BreakerClosed {
+		t.Fatalf("state = %s after 2 consecutive failures, want CLOSED", b.State())
+	}
+
+	b.Record(failure)
+	if b.State() != BreakerOpen {
+		t.Fatalf("state = %s after 3 consecutive failures, want OPEN", b.State())
+	}
+	err := b.Allow("GetSims")
+	if !IsCircuitOpen(err) || !IsRetryable(err) {
+		t.Fatalf("Allow = %v, want retryable circuit-open error", err)
+	}
+	if b.Ready() {
+		t.Error("Ready() = true while open")
+	}
+	snap := b.Snapshot()
+	if snap.Trips != 1 || snap.RetryAt == nil || snap.LastError != failure.Error() {
+		t.Errorf("snapshot = %+v", snap)
+	}
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	b := newTestBreaker(1, 10*time.Millisecond)
+	b.Record(errors.New("timeout"))
+	time.Sleep(15 * time.Millisecond)
+
+	if !b.Ready() {
+		t.Fatal("Ready() = false after open timeout")
+	}
+	if err := b.Allow("probe"); err != nil {
+		t.Fatalf("probe rejected: %v", err)
+	}
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("state = %s, want HALF_OPEN", b.State())
+	}
+	// Пока идёт проба, остальные запросы не пропускаются
+	if err := b.Allow("second"); !IsCircuitOpen(err) {
+		t.Fatalf("second request during probe: %v", err)
+	}
+
+	// Сбой пробы снова открывает breaker
+	b.Record(errors.New("timeout"))
+	if b.State() != BreakerOpen || b.Snapshot().Trips != 2 {
+		t.Fatalf("failed probe: state = %s, trips = %d", b.State(), b.Snapshot().Trips)
+	}
+
+	time.Sleep(15 * time.Millisecond)
+	if err := b.Allow("probe"); err != nil {
+		t.Fatalf("second probe rejected: %v", err)
+	}
+	b.Record(nil)
+	if b.State() != BreakerClosed {
+		t.Fatalf("successful probe: state = %s, want CLOSED", b.State())
+	}
+}
+
+func TestBreakerReleaseFreesProbe(t *testing.T) {
+	b := newTestBreaker(1, time.Millisecond)
+	b.Record(errors.New("timeout"))
+	time.Sleep(2 * time.Millisecond)
+
+	if err := b.Allow("probe"); err != nil {
+		t.Fatal(err)
+	}
+	b.release() // Запрос отменён до ответа
+	if err := b.Allow("retry"); err != nil {
+		t.Fatalf("probe not released: %v", err)
+	}
+}
+
+func TestBreakerFor(t *testing.T) {
+	a := BreakerFor("http://breaker-for.test")
+	if BreakerFor("http://breaker-for.test") != a {
+		t.Error("BreakerFor returned a new breaker for the same upstream")
+	}
+	if BreakerFor("http://other.test") == a {
+		t.Error("BreakerFor shares a breaker between upstreams")
+	}
+	if a.State() != BreakerClosed || a.FailureThreshold != breakerThreshold {
+		t.Errorf("new breaker = %+v", a.Snapshot())
+	}
+}
+
+func TestIsBreakerFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		want   bool
+	}{
+		{"ok", http.StatusOK, nil, false},
+		{"business error", http.StatusBadRequest, nil, false},
+		{"server error", http.StatusBadGateway, nil, true},
+		{"transport error", 0, errors.New("connection reset"), true},
+		{"canceled", 0, &APIError{Kind: ErrKindCanceled}, false},
+	}
+	for _, tt := range tests {
+		if got := isBreakerFailure(tt.status, tt.err); got != tt.want {
+			t.Errorf("%s: isBreakerFailure = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
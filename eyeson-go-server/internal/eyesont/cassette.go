@@ -0,0 +1,351 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════
+// RECORD / REPLAY TRANSPORT (CASSETTES)
+// ═══════════════════════════════════════════════════════════
+
+// CassetteMode - режим транспорта EyesOnT (EYESON_API_CASSETTE_MODE)
+type CassetteMode string
+
+const (
+	CassetteOff    CassetteMode = "off"    // Обычная сеть
+	CassetteRecord CassetteMode = "record" // Реальные запросы + запись пар запрос/ответ в файл
+	CassetteReplay CassetteMode = "replay" // Ответы только из файла, без сети
+
+	// Как replay, но запрос с незаписанным телом получает первую запись с тем же методом и путём.
+	// Только для демо: регрессии в телах запросов такой режим не ловит.
+	CassetteReplayLoose CassetteMode = "replay-loose"
+)
+
+// cassetteVersion - версия формата файла
+const cassetteVersion = 1
+
+// Cassette - файл с записанными обращениями к провайдеру
+type Cassette struct {
+	Version      int           `json:"version"`
+	RecordedAt   time.Time     `json:"recorded_at"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction - одна пара запрос/ответ. Логин и пароль в теле запроса замаскированы
+// (maskPasswordInBody), значения session cookies заменены.
+type Interaction struct {
+	Request struct {
+		Method string          `json:"method"`
+		Path   string          `json:"path"` // без хоста: кассета подходит и для симулятора, и для портала
+		Body   json.RawMessage `json:"body,omitempty"`
+	} `json:"request"`
+	Response struct {
+		StatusCode int                 `json:"status_code"`
+		Header     map[string][]string `json:"header,omitempty"`
+		Body       string              `json:"body"`
+	} `json:"response"`
+}
+
+// recordedHeaders - заголовки ответа, которые сохраняются в кассете
+var recordedHeaders = []string{"Content-Type", "Set-Cookie"}
+
+// Кассета общая для всех клиентов (аккаунтов); включён не больше одного режима
+var (
+	cassetteMu       sync.Mutex
+	cassetteRecorder *recorder
+	cassetteReplayer *replayer
+)
+
+// ConfigureCassette включает запись или воспроизведение для всех клиентов, созданных после вызова.
+// Вызывается в main до создания клиентов.
+func ConfigureCassette(mode CassetteMode, path string) error {
+	cassetteMu.Lock()
+	defer cassetteMu.Unlock()
+
+	cassetteRecorder, cassetteReplayer = nil, nil
+	switch mode {
+	case "", CassetteOff:
+		return nil
+	case CassetteRecord:
+		rec, err := newRecorder(path)
+		if err != nil {
+			return err
+		}
+		cassetteRecorder = rec
+		log.Printf("[EyesOnT API] CASSETTE RECORD: %s (%d interactions already recorded)", path, len(rec.cassette.Interactions))
+		return nil
+	case CassetteReplay, CassetteReplayLoose:
+		rep, err := newReplayer(path)
+		if err != nil {
+			return err
+		}
+		rep.loose = mode == CassetteReplayLoose
+		cassetteReplayer = rep
+		log.Printf("[EyesOnT API] CASSETTE REPLAY: %s (%d interactions, loose=%v), network disabled", path, len(rep.interactions), rep.loose)
+		return nil
+	}
+	return fmt.Errorf("unknown cassette mode %q (expected off|record|replay|replay-loose)", mode)
+}
+
+// wrapTransport подменяет транспорт клиента, если включена кассета
+func wrapTransport(base http.RoundTripper) http.RoundTripper {
+	cassetteMu.Lock()
+	defer cassetteMu.Unlock()
+
+	if cassetteReplayer != nil {
+		return cassetteReplayer
+	}
+	if cassetteRecorder != nil {
+		return &recordingTransport{recorder: cassetteRecorder, base: base}
+	}
+	return base
+}
+
+// ─── ЗАПИСЬ ────────────────────────────────────────────────
+
+type recorder struct {
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// newRecorder дописывает в существующую кассету или создаёт новую
+func newRecorder(path string) (*recorder, error) {
+	r := &recorder{path: path, cassette: Cassette{Version: cassetteVersion}}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("cassette %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return r, nil
+}
+
+// append добавляет пару и сразу сохраняет файл (запись не теряется при аварийной остановке)
+func (r *recorder) append(it Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, it)
+	r.cassette.RecordedAt = time.Now()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		log.Printf("[EyesOnT API] Cassette marshal failed: %v", err)
+		return
+	}
+	if dir := filepath.Dir(r.path); dir != "" {
+		_ = os.MkdirAll(dir, 0o755)
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		log.Printf("[EyesOnT API] Cassette write failed: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		log.Printf("[EyesOnT API] Cassette write failed: %v", err)
+	}
+}
+
+// recordingTransport выполняет реальный запрос и записывает пару в кассету
+type recordingTransport struct {
+	recorder *recorder
+	base     http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := drainBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := drainBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var it Interaction
+	it.Request.Method = req.Method
+	it.Request.Path = req.URL.RequestURI()
+	it.Request.Body = maskedRequestBody(reqBody)
+	it.Response.StatusCode = resp.StatusCode
+	it.Response.Header = recordHeaders(resp.Header)
+	it.Response.Body = string(respBody)
+	t.recorder.append(it)
+
+	return resp, nil
+}
+
+// ─── ВОСПРОИЗВЕДЕНИЕ ───────────────────────────────────────
+
+type replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         map[string]int // ключ запроса -> сколько пар с этим ключом уже выдано
+	loose        bool           // CassetteReplayLoose: без совпадения тела - первая запись с тем же путём
+}
+
+func newReplayer(path string) (*replayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("cassette %s: unsupported version %d", path, c.Version)
+	}
+	// MarshalIndent переформатировал тела запросов - для сравнения приводим к компактному виду
+	for i := range c.Interactions {
+		var compact bytes.Buffer
+		if json.Compact(&compact, c.Interactions[i].Request.Body) == nil {
+			c.Interactions[i].Request.Body = compact.Bytes()
+		}
+	}
+	return &replayer{interactions: c.Interactions, used: map[string]int{}}, nil
+}
+
+// RoundTrip отдаёт записанный ответ. Одинаковые запросы получают записанные ответы
+// по порядку, после последнего повторяется последний. Запрос, тело которого не совпало
+// ни с одной записью, - ошибка (в режиме replay-loose - первая запись с тем же методом и путём).
+func (p *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := drainBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	path := req.URL.RequestURI()
+	body := string(maskedRequestBody(reqBody))
+	key := req.Method + " " + path + " " + body
+
+	p.mu.Lock()
+	var matches []int
+	for i, it := range p.interactions {
+		if it.Request.Method == req.Method && it.Request.Path == path && string(it.Request.Body) == body {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 && p.loose {
+		for i, it := range p.interactions {
+			if it.Request.Method == req.Method && it.Request.Path == path {
+				matches = append(matches, i)
+				break
+			}
+		}
+	}
+	if len(matches) == 0 {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("cassette: no recorded interaction for %s %s with body %s", req.Method, path, bodyPreview([]byte(body), 200))
+	}
+	n := p.used[key]
+	p.used[key] = n + 1
+	p.mu.Unlock()
+
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	it := p.interactions[matches[n]]
+
+	header := http.Header{}
+	for name, values := range it.Response.Header {
+		for _, v := range values {
+			header.Add(name, v)
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", it.Response.StatusCode, http.StatusText(it.Response.StatusCode)),
+		StatusCode:    it.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(it.Response.Body)),
+		ContentLength: int64(len(it.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// ─── ВСПОМОГАТЕЛЬНОЕ ───────────────────────────────────────
+
+// drainBody читает тело и подставляет вместо него копию
+func drainBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// maskedRequestBody - тело запроса с замаскированными логином/паролем (канонический JSON).
+// Не-JSON тело сохраняется как строка.
+func maskedRequestBody(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		quoted, _ := json.Marshal(string(body))
+		return quoted
+	}
+	masked, err := json.Marshal(maskPasswordInBody(parsed))
+	if err != nil {
+		return nil
+	}
+	return masked
+}
+
+// recordHeaders сохраняет нужные заголовки; значения cookies заменяются
+func recordHeaders(h http.Header) map[string][]string {
+	out := map[string][]string{}
+	for _, name := range recordedHeaders {
+		values := h.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		if name == "Set-Cookie" {
+			values = redactCookies(values)
+		}
+		out[name] = values
+	}
+	return out
+}
+
+func redactCookies(values []string) []string {
+	redacted := make([]string, 0, len(values))
+	for _, v := range values {
+		nameValue, attrs, _ := strings.Cut(v, ";")
+		name, _, _ := strings.Cut(nameValue, "=")
+		cookie := name + "=REDACTED"
+		if attrs != "" {
+			cookie += ";" + attrs
+		}
+		redacted = append(redacted, cookie)
+	}
+	return redacted
+}
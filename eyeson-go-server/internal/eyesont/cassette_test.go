@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ═══════════════════════════════════════════════════════════
+// REPLAY ЗАПИСАННОЙ СЕССИИ С ПРОВАЙДЕРОМ
+// ═══════════════════════════════════════════════════════════
+//
+// По умолчанию клиент работает по кассете testdata/session.json без сети.
+// Перезаписать кассету против симулятора или портала:
+//
+//	go test ./internal/eyesont -run TestCassetteSession -record http://127.0.0.1:8888
+
+var recordUpstream = flag.String("record", "", "upstream base URL to re-record testdata cassettes against")
+
+const replayBaseURL = "http://cassette.invalid"
+
+// cassetteClient включает кассету и создаёт клиент admin/admin (пароль в кассете замаскирован)
+func cassetteClient(t *testing.T, name string) *Client {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+
+	mode, baseURL := CassetteReplay, replayBaseURL
+	if *recordUpstream != "" {
+		mode, baseURL = CassetteRecord, *recordUpstream
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+	}
+	if err := ConfigureCassette(mode, path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ConfigureCassette(CassetteOff, "") })
+	return NewClient(baseURL, "admin", "admin", 0, false)
+}
+
+func TestCassetteSession(t *testing.T) {
+	client := cassetteClient(t, "session.json")
+	ctx := context.Background()
+
+	if err := client.Login(ctx); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	params, err := client.GetParameters(ctx)
+	if err != nil {
+		t.Fatalf("GetParameters: %v", err)
+	}
+	ratePlans := params.FindParameter("RATE_PLAN_FULL_NAME", "RATE_PLAN", "RATE_PLAN_CHANGE")
+	if ratePlans == nil || len(ratePlans.AvailableValues) < 2 {
+		t.Fatalf("GetParameters: rate plan list missing or too short: %+v", ratePlans)
+	}
+
+	sims, err := client.GetSims(ctx, 0, 5, nil, "", "")
+	if err != nil {
+		t.Fatalf("GetSims: %v", err)
+	}
+	if len(sims.Data) == 0 || sims.Count < len(sims.Data) {
+		t.Fatalf("GetSims: count %d, %d rows", sims.Count, len(sims.Data))
+	}
+	sim := sims.Data[0]
+	if sim.MSISDN == "" {
+		t.Fatalf("GetSims: first row has no MSISDN: %+v", sim)
+	}
+
+	// Новый план - любой, кроме текущего
+	plan := ratePlans.AvailableValues[0].Name
+	if plan == sim.RatePlanFullName {
+		plan = ratePlans.AvailableValues[1].Name
+	}
+	update, err := client.ChangeRatePlan(ctx, []string{sim.MSISDN}, plan)
+	if err != nil {
+		t.Fatalf("ChangeRatePlan: %v", err)
+	}
+	if update.RequestId == 0 {
+		t.Fatal("ChangeRatePlan: no requestId")
+	}
+
+	jobs, err := client.GetJobs(ctx, 0, 1, update.RequestId, "")
+	if err != nil {
+		t.Fatalf("GetJobs: %v", err)
+	}
+	if len(jobs.Jobs) != 1 || jobs.Jobs[0].JobId != update.RequestId {
+		t.Fatalf("GetJobs(%d): %+v", update.RequestId, jobs.Jobs)
+	}
+}
+
+func TestCassetteReplayUnrecordedRequest(t *testing.T) {
+	if *recordUpstream != "" {
+		t.Skip("replay only")
+	}
+	client := cassetteClient(t, "session.json")
+	ctx := context.Background()
+	if err := client.Login(ctx); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	// Logout в кассете не записан - ответа без сети не будет
+	if err := client.Logout(ctx); err == nil {
+		t.Fatal("Logout: expected error for a request missing from the cassette")
+	}
+}
+
+func TestCassetteReplayBodyMismatch(t *testing.T) {
+	if *recordUpstream != "" {
+		t.Skip("replay only")
+	}
+	t.Cleanup(func() { _ = ConfigureCassette(CassetteOff, "") })
+	ctx := context.Background()
+
+	tests := []struct {
+		mode    CassetteMode
+		wantErr bool
+	}{
+		{CassetteReplay, true},       // Тело не записано - ошибка, а не чужой ответ
+		{CassetteReplayLoose, false}, // Демо-режим: первая запись с тем же путём
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			if err := ConfigureCassette(tt.mode, filepath.Join("testdata", "session.json")); err != nil {
+				t.Fatal(err)
+			}
+			client := NewClient(replayBaseURL, "admin", "admin", 0, false)
+
+			// Абонент и план, которых нет в кассете
+			_, err := client.ChangeRatePlan(ctx, []string{"0500000000"}, "UNRECORDED_PLAN")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ChangeRatePlan error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+)
+
+// ═══════════════════════════════════════════════════════════
+// CHANGE SETS (MULTI-ACTION updateProvisioningData)
+// ═══════════════════════════════════════════════════════════
+
+var _ provider.ChangeSetUpdater = (*Client)(nil)
+
+// UpdateProvisioningSet отправляет несколько действий над одними абонентами в одном запросе
+// updateProvisioningData: массив actions, по одному на изменение, с общим списком subscribers.
+// Провайдер возвращает один requestId на весь набор.
+func (c *Client) UpdateProvisioningSet(ctx context.Context, msisdns []string, changes []provider.Change) (*models.BulkUpdateResponse, error) {
+	if len(changes) == 0 {
+		return nil, NewValidationError("UpdateProvisioningSet", "change set is empty")
+	}
+
+	url := fmt.Sprintf("%s/ipa/apis/json/provisioning/updateProvisioningData", c.BaseURL)
+
+	// Нормализуем MSISDN для Pelephone API (972xxx -> 0xxx)
+	subscribers := make([]map[string]string, len(msisdns))
+	for i, m := range msisdns {
+		subscribers[i] = map[string]string{"neId": NormalizeMSISDN(m)}
+	}
+
+	type Action struct {
+		ActionType  string              `json:"actionType"`
+		TargetValue string              `json:"targetValue"`
+		TargetId    string              `json:"targetId"`
+		Subscribers []map[string]string `json:"subscribers"`
+	}
+
+	type RequestBody struct {
+		Actions  []Action `json:"actions"`
+		Username string   `json:"username"`
+		Password string   `json:"password"`
+	}
+
+	reqBody := RequestBody{Username: c.Username, Password: c.Password}
+	names := make([]string, 0, len(changes))
+	for _, ch := range changes {
+		reqBody.Actions = append(reqBody.Actions, Action{
+			ActionType:  ch.ActionType,
+			TargetValue: ch.TargetValue,
+			Subscribers: subscribers,
+		})
+		names = append(names, ch.ActionType)
+	}
+
+	log.Printf("[EyesOnT API] UpdateProvisioningSet REQUEST: subscribers=%d, actions=%s", len(subscribers), strings.Join(names, ","))
+
+	resp, err := c.doRequest(ctx, "POST", url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	log.Printf("[EyesOnT API] UpdateProvisioningSet RESPONSE (status=%d, bytes=%d)", resp.StatusCode, len(body))
+
+	var result models.BulkUpdateResponse
+	if err := decodeResponse("UpdateProvisioningSet", resp.StatusCode, body, &result); err != nil {
+		log.Printf("[EyesOnT API] UpdateProvisioningSet FAILED: %v", err)
+		return nil, err
+	}
+
+	log.Printf("[EyesOnT API] UpdateProvisioningSet SUCCESS: requestId=%d", result.RequestId)
+	return &result, nil
+}
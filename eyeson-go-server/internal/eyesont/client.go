@@ -7,26 +7,19 @@ package eyesont
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
-	"net/http/cookiejar"
 	"strings"
 	"sync"
 	"time"
 )
 
-// Instance - глобальный экземпляр клиента API
-var Instance *Client
-
-// Глобальный rate limiter для API запросов (WAF protection)
-var apiRateMutex sync.Mutex
-var lastApiCall time.Time
-
 // Client представляет API-клиент EyesOnT с сессионной авторизацией
 type Client struct {
 	BaseURL    string
@@ -34,24 +27,53 @@ type Client struct {
 	Password   string
 	ApiDelayMs int
 	httpClient *http.Client
-	sessionMu  sync.RWMutex
-	loggedIn   bool
-	loginTime  time.Time
+	jar        *sessionJar // cookies сессии; Logout очищает их, не трогая httpClient
+
+	// transport - прокси и TLS клиента (transport.go)
+	transport TransportConfig
+
+	// limiter - собственный token bucket клиента (аккаунта), защита от WAF
+	limiter *RateLimiter
+
+	// breaker - общий для всех клиентов одного BaseURL (BreakerFor)
+	breaker *CircuitBreaker
+
+	// Дедлайн одного запроса: RequestTimeout = 0 означает DefaultRequestTimeout
+	RequestTimeout time.Duration
+
+	// Максимум абонентов в одном action: 0 означает provider.DefaultMaxSubscribersPerAction
+	MaxSubscribers int
+
+	// Сессия: SessionMaxAge = 0 означает DefaultSessionMaxAge
+	SessionMaxAge   time.Duration
+	sessionMu       sync.RWMutex
+	loggedIn        bool
+	loginTime       time.Time
+	relogins        int
+	lastLoginTry    time.Time
+	lastAuthFailure string
+	lastAuthFailAt  time.Time
 }
 
-// InitWithConfig инициализирует клиент с прямыми значениями
-func InitWithConfig(baseURL, username, password string, apiDelayMs int, insecureTLS bool) {
-	Instance = NewClient(baseURL, username, password, apiDelayMs, insecureTLS)
-	maskedPassword := maskPassword(password)
-	if insecureTLS {
-		log.Printf("[EyesOnT API] Initialized (INSECURE TLS): URL=%s, User=%s, Password=%s, Delay=%dms", baseURL, username, maskedPassword, apiDelayMs)
+// Use пишет в лог настройки клиента и выполняет стартовый login.
+// Клиент создаётся реестром провайдеров (provider.New) или NewClient;
+// текущий провайдер сервера хранит provider.Active.
+func Use(client *Client) {
+	maskedPassword := maskPassword(client.Password)
+	limit := client.limiter.Stats()
+	if client.transport.InsecureTLS {
+		log.Printf("[EyesOnT API] Initialized (INSECURE TLS): URL=%s, User=%s, Password=%s, Rate=%.1f/s Burst=%d", client.BaseURL, client.Username, maskedPassword, limit.RatePerSec, limit.Burst)
 	} else {
-		log.Printf("[EyesOnT API] Initialized: URL=%s, User=%s, Password=%s, Delay=%dms", baseURL, username, maskedPassword, apiDelayMs)
+		log.Printf("[EyesOnT API] Initialized: URL=%s, User=%s, Password=%s, Rate=%.1f/s Burst=%d", client.BaseURL, client.Username, maskedPassword, limit.RatePerSec, limit.Burst)
+	}
+
+	if tr := client.Transport(); tr.Proxy != "" || tr.CACerts > 0 || tr.ClientCertSubject != "" {
+		log.Printf("[EyesOnT API] Transport: proxy=%q, custom CA certs=%d, client cert=%q", tr.Proxy, tr.CACerts, tr.ClientCertSubject)
 	}
 
 	// Выполняем login при старте
 	log.Println("[EyesOnT API] Performing initial startup login...")
-	if err := Instance.Login(); err != nil {
+	if err := client.Login(context.Background()); err != nil {
 		log.Printf("[EyesOnT API] WARNING: Initial login failed: %v", err)
 	} else {
 		log.Println("[EyesOnT API] Initial login successful")
@@ -96,16 +118,26 @@ func maskPasswordInBody(body interface{}) interface{} {
 	return m
 }
 
-// NewClient создает новый клиент с cookie-jar для сессий
+// NewClient создает новый клиент с cookie-jar для сессий (прямое подключение, без прокси и mTLS)
 func NewClient(baseURL, username, password string, apiDelayMs int, insecureTLS bool) *Client {
-	jar, _ := cookiejar.New(nil)
+	// Без файлов сертификатов и прокси NewTransport ошибок не возвращает
+	client, _ := NewClientWithTransport(baseURL, username, password, apiDelayMs, TransportConfig{InsecureTLS: insecureTLS})
+	return client
+}
+
+// NewClientWithTransport создает клиент с прокси, собственным CA и клиентским сертификатом (transport.go)
+func NewClientWithTransport(baseURL, username, password string, apiDelayMs int, tc TransportConfig) (*Client, error) {
+	transport, err := NewTransport(tc)
+	if err != nil {
+		return nil, &APIError{Kind: ErrKindValidation, Op: "NewClient", Message: "invalid transport configuration", Err: err}
+	}
+	jar := newSessionJar()
 
 	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: insecureTLS},
-		},
-		Jar:     jar,
-		Timeout: 30 * time.Second,
+		// EYESON_API_CASSETTE_MODE: запись/воспроизведение обращений к провайдеру (cassette.go)
+		Transport: wrapTransport(transport),
+		// Общий Timeout не задаём: дедлайн ставится на каждый вызов (withCallDeadline)
+		Jar: jar,
 	}
 
 	return &Client{
@@ -114,15 +146,63 @@ func NewClient(baseURL, username, password string, apiDelayMs int, insecureTLS b
 		Password:   password,
 		ApiDelayMs: apiDelayMs,
 		httpClient: client,
+		jar:        jar,
 		loggedIn:   false,
+
+		transport: tc,
+		breaker:   BreakerFor(baseURL),
+		limiter:   NewRateLimiter(RateFromDelay(apiDelayMs), 1),
+	}, nil
+}
+
+// Transport возвращает действующие сетевые настройки клиента для диагностики
+func (c *Client) Transport() TransportInfo {
+	return c.transport.Describe()
+}
+
+// SetRateLimit задаёт скорость (запросов/сек, 0 - без ограничения) и burst лимитера клиента
+func (c *Client) SetRateLimit(ratePerSec float64, burst int) {
+	c.limiter.SetLimit(ratePerSec, burst)
+}
+
+// RateLimit возвращает состояние лимитера для диагностики
+func (c *Client) RateLimit() RateLimiterStats {
+	return c.limiter.Stats()
+}
+
+// Breaker возвращает circuit breaker upstream'а клиента
+func (c *Client) Breaker() *CircuitBreaker {
+	return c.breaker
+}
+
+// recordOutcome сообщает breaker'у итог HTTP-запроса. Отменённый запрос не считается ни успехом, ни сбоем.
+func (c *Client) recordOutcome(statusCode int, err error) {
+	if IsCanceled(err) {
+		c.breaker.release()
+		return
+	}
+	if isBreakerFailure(statusCode, err) {
+		if err == nil {
+			err = fmt.Errorf("HTTP %d", statusCode)
+		}
+		c.breaker.Record(err)
+		return
 	}
+	c.breaker.Record(nil)
 }
 
 // Login выполняет авторизацию и сохраняет сессионные cookies
-func (c *Client) Login() error {
+func (c *Client) Login(ctx context.Context) error {
 	c.sessionMu.Lock()
 	defer c.sessionMu.Unlock()
 
+	return c.loginLocked(ctx)
+}
+
+// loginLocked выполняет login; вызывающий держит sessionMu
+func (c *Client) loginLocked(ctx context.Context) error {
+	c.lastLoginTry = time.Now()
+
 	loginReq := struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
@@ -142,26 +222,37 @@ func (c *Client) Login() error {
 
 	log.Printf("[EyesOnT API] Sending LOGIN request to %s", loginURL)
 
+	ctx, cancel := c.withCallDeadline(ctx)
+	defer cancel()
+
+	if err := c.breaker.Allow("Login"); err != nil {
+		return err
+	}
+
 	// Создаём запрос с browser-like заголовками для обхода Incapsula WAF
-	req, err := http.NewRequest("POST", loginURL, bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", loginURL, bytes.NewBuffer(body))
 	if err != nil {
-		return fmt.Errorf("login request creation failed: %w", err)
+		c.breaker.release()
+		return &APIError{Kind: ErrKindValidation, Op: "Login", Message: "request creation failed", Err: err}
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-	req.Header.Set("Accept", "application/json, text/plain, */*")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9,he;q=0.8")
-	req.Header.Set("Origin", c.BaseURL)
-	req.Header.Set("Referer", c.BaseURL+"/")
-	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	setBrowserHeaders(req, c.BaseURL)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("login request failed: %w", err)
+		apiErr := transportError("Login", err)
+		c.recordOutcome(0, apiErr)
+		return apiErr
 	}
 	defer resp.Body.Close()
 
-	respBody, _ := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		apiErr := transportError("Login", err)
+		c.recordOutcome(0, apiErr)
+		return apiErr
+	}
+	c.recordOutcome(resp.StatusCode, nil)
 	log.Printf("[EyesOnT API] LOGIN RESPONSE (status=%d, bytes=%d)", resp.StatusCode, len(respBody))
 
 	// Проверяем, что ответ - JSON, а не HTML
@@ -172,15 +263,11 @@ func (c *Client) Login() error {
 			preview = preview[:500] + "..."
 		}
 		log.Printf("[EyesOnT API] LOGIN ERROR: response is not JSON:\n%s", preview)
-		return fmt.Errorf("login failed: API returned non-JSON response (possibly HTML error page)")
+		return &APIError{Kind: ErrKindNonJSON, Op: "Login", StatusCode: resp.StatusCode, Message: bodyPreview(respBody, 200), Retryable: true}
 	}
 
 	if resp.StatusCode != 200 {
-		errBody := respStr
-		if len(errBody) > 200 {
-			errBody = errBody[:200] + "..."
-		}
-		return fmt.Errorf("login failed with status %d: %s", resp.StatusCode, errBody)
+		return httpStatusError("Login", resp.StatusCode, respBody)
 	}
 
 	var result struct {
@@ -197,31 +284,25 @@ func (c *Client) Login() error {
 	}
 
 	// Логин не удался - логируем причину и возвращаем ошибку
+	c.loggedIn = false
 	log.Printf("[EyesOnT API] LOGIN FAILED: result=%s, message=%s, response=%s", result.Result, result.Message, respStr)
-	return fmt.Errorf("login failed: result=%s, message=%s", result.Result, result.Message)
+	return resultError("Login", resp.StatusCode, result.Result, result.Message)
 }
 
-// doRequest выполняет HTTP запрос с rate limiting для защиты от WAF
-func (c *Client) doRequest(method, url string, body interface{}) (*http.Response, error) {
-	// Rate limiting для защиты от WAF
-	if c.ApiDelayMs > 0 {
-		apiRateMutex.Lock()
-		elapsed := time.Since(lastApiCall)
-		delay := time.Duration(c.ApiDelayMs) * time.Millisecond
-		if elapsed < delay {
-			time.Sleep(delay - elapsed)
-		}
-		lastApiCall = time.Now()
-		apiRateMutex.Unlock()
-	}
+// doRequest выполняет HTTP запрос с rate limiting для защиты от WAF.
+// Перед запросом проверяет возраст сессии; при ответе "сессия недействительна"
+// (401, i_result=-1, HTML-страница логина) один раз перелогинивается и повторяет запрос.
+// Отмена ctx прерывает как ожидание rate limiter'а, так и сам HTTP-запрос.
+func (c *Client) doRequest(ctx context.Context, method, url string, body interface{}) (*http.Response, error) {
+	op := url[strings.LastIndex(url, "/")+1:]
 
-	var bodyReader io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("marshal error: %w", err)
+			return nil, &APIError{Kind: ErrKindValidation, Op: op, Message: "marshal error", Err: err}
 		}
-		bodyReader = bytes.NewBuffer(jsonBody)
 
 		// Логируем запрос (скрываем логин/пароль)
 		logBody := maskPasswordInBody(body)
@@ -232,28 +313,97 @@ func (c *Client) doRequest(method, url string, body interface{}) (*http.Response
 		log.Printf("[EyesOnT API] REQUEST %s %s\nPayload: %s", method, url, string(jsonIndented))
 	}
 
-	req, err := http.NewRequest(method, url, bodyReader)
+	c.ensureSession(ctx)
+
+	for attempt := 0; ; attempt++ {
+		c.sessionMu.RLock()
+		sessionLoginTime := c.loginTime
+		c.sessionMu.RUnlock()
+
+		resp, respBody, err := c.send(ctx, op, method, url, jsonBody)
+		if err != nil {
+			return nil, err
+		}
+
+		authFailed, reason := isAuthFailure(resp.StatusCode, respBody)
+		if !authFailed {
+			resp.Body = io.NopCloser(bytes.NewReader(respBody))
+			return resp, nil
+		}
+
+		log.Printf("[EyesOnT API] %s: session rejected (%s)", op, reason)
+		if attempt > 0 {
+			return nil, &APIError{Kind: ErrKindAuth, Op: op, StatusCode: resp.StatusCode, Message: reason, Retryable: true}
+		}
+		if loginErr := c.relogin(ctx, sessionLoginTime, reason); loginErr != nil {
+			return nil, &APIError{Kind: ErrKindAuth, Op: op, StatusCode: resp.StatusCode, Message: reason, Retryable: true, Err: loginErr}
+		}
+	}
+}
+
+// send отправляет один HTTP запрос и полностью читает тело ответа.
+// Очередь rate limiter'а ограничена только ctx вызывающего; дедлайн вызова начинается
+// после неё и охватывает сам запрос и чтение тела - ожидание в bulk-очереди не съедает таймаут.
+func (c *Client) send(ctx context.Context, op, method, url string, jsonBody []byte) (*http.Response, []byte, error) {
+	// Пока breaker открыт, запрос не отправляем вовсе
+	if err := c.breaker.Allow(op); err != nil {
+		return nil, nil, err
+	}
+
+	// Rate limiting для защиты от WAF: интерактивные запросы идут раньше bulk (WithBulk)
+	if err := c.limiter.Wait(ctx, laneFrom(ctx)); err != nil {
+		c.breaker.release()
+		return nil, nil, transportError(op, err)
+	}
+
+	ctx, cancel := c.withCallDeadline(ctx)
+	defer cancel()
+
+	var bodyReader io.Reader
+	if jsonBody != nil {
+		bodyReader = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
-		return nil, err
+		c.breaker.release()
+		return nil, nil, &APIError{Kind: ErrKindValidation, Op: op, Message: "request creation failed", Err: err}
 	}
 
-	// Browser-like headers для обхода Incapsula WAF
+	setBrowserHeaders(req, c.BaseURL)
+	if jsonBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		apiErr := transportError(op, err)
+		c.recordOutcome(0, apiErr)
+		return nil, nil, apiErr
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		apiErr := transportError(op, err)
+		c.recordOutcome(0, apiErr)
+		return nil, nil, apiErr
+	}
+	c.recordOutcome(resp.StatusCode, nil)
+	return resp, respBody, nil
+}
+
+// setBrowserHeaders - browser-like заголовки для обхода Incapsula WAF
+func setBrowserHeaders(req *http.Request, baseURL string) {
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
 	req.Header.Set("Accept", "application/json, text/plain, */*")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9,he;q=0.8")
-	req.Header.Set("Origin", c.BaseURL)
-	req.Header.Set("Referer", c.BaseURL+"/")
+	req.Header.Set("Origin", baseURL)
+	req.Header.Set("Referer", baseURL+"/")
 	req.Header.Set("X-Requested-With", "XMLHttpRequest")
-
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-
-	return c.httpClient.Do(req)
 }
 
 // GetSims получает список SIM-карт
-func (c *Client) GetSims(start, limit int, search []models.SearchParam, sortBy, sortDirection string) (*models.GetProvisioningDataResponse, error) {
+func (c *Client) GetSims(ctx context.Context, start, limit int, search []models.SearchParam, sortBy, sortDirection string) (*models.GetProvisioningDataResponse, error) {
 	url := fmt.Sprintf("%s/ipa/apis/json/provisioning/getProvisioningData", c.BaseURL)
 
 	// Формируем запрос с username и password (формат по swagger)
@@ -286,7 +436,7 @@ func (c *Client) GetSims(start, limit int, search []models.SearchParam, sortBy,
 		Search:        search,
 	}
 
-	resp, err := c.doRequest("POST", url, reqBody)
+	resp, err := c.doRequest(ctx, "POST", url, reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -295,26 +445,11 @@ func (c *Client) GetSims(start, limit int, search []models.SearchParam, sortBy,
 	body, _ := io.ReadAll(resp.Body)
 	log.Printf("[EyesOnT API] GetSims RESPONSE (status=%d, bytes=%d)", resp.StatusCode, len(body))
 
-	// Проверяем, что ответ - JSON, а не HTML (ошибка API/прокси)
-	bodyStr := strings.TrimSpace(string(body))
-	if len(bodyStr) > 0 && bodyStr[0] != '{' && bodyStr[0] != '[' {
-		// Ответ не JSON - логируем для диагностики
-		preview := bodyStr
-		if len(preview) > 500 {
-			preview = preview[:500] + "..."
-		}
-		log.Printf("[EyesOnT API] GetSims ERROR: response is not JSON:\n%s", preview)
-		return nil, fmt.Errorf("API returned non-JSON response (possibly HTML error page): %s", preview)
-	}
-
+	// Проверяем формат (HTML от WAF/прокси), HTTP статус и result
 	var result models.GetProvisioningDataResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		// Логируем тело ответа при ошибке парсинга
-		preview := bodyStr
-		if len(preview) > 300 {
-			preview = preview[:300] + "..."
-		}
-		return nil, fmt.Errorf("unmarshal error: %w\nResponse body: %s", err, preview)
+	if err := decodeResponse("GetSims", resp.StatusCode, body, &result); err != nil {
+		log.Printf("[EyesOnT API] GetSims ERROR: %v", err)
+		return nil, err
 	}
 
 	log.Printf("[EyesOnT API] GetSims PARSED: result=%s, count=%d, dataLen=%d", result.Result, result.Count, len(result.Data))
@@ -329,9 +464,18 @@ func NormalizeMSISDN(msisdn string) string {
 	return msisdn
 }
 
+// MaxSubscribersPerAction - сколько абонентов принимается в одном action (provider.Chunker).
+// Клиент список не делит: bulk-задачи отправляются частями в jobs.submitChunks.
+func (c *Client) MaxSubscribersPerAction() int {
+	if c.MaxSubscribers > 0 {
+		return c.MaxSubscribers
+	}
+	return provider.DefaultMaxSubscribersPerAction
+}
+
 // BulkUpdate выполняет массовое обновление SIM-карт
 // API формат: {"actions": [{"actionType": "...", "targetValue": "...", "subscribers": [{"neId": "..."}]}]}
-func (c *Client) BulkUpdate(msisdns []string, actionType, targetValue string) (*models.BulkUpdateResponse, error) {
+func (c *Client) BulkUpdate(ctx context.Context, msisdns []string, actionType, targetValue string) (*models.BulkUpdateResponse, error) {
 	url := fmt.Sprintf("%s/ipa/apis/json/provisioning/updateProvisioningData", c.BaseURL)
 
 	// Нормализуем MSISDN для Pelephone API (972xxx -> 0xxx)
@@ -369,7 +513,7 @@ func (c *Client) BulkUpdate(msisdns []string, actionType, targetValue string) (*
 		Password: c.Password,
 	}
 
-	resp, err := c.doRequest("POST", url, reqBody)
+	resp, err := c.doRequest(ctx, "POST", url, reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -380,35 +524,33 @@ func (c *Client) BulkUpdate(msisdns []string, actionType, targetValue string) (*
 
 	// Simulator compatibility: some dev simulators only implement /updateSIMStatusChange.
 	// If /updateProvisioningData is missing, fall back for SIM_STATE_CHANGE.
-	if (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed) && actionType == "SIM_STATE_CHANGE" {
-		legacyErr := c.bulkUpdateStatusLegacy(msisdns, targetValue)
+	if (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed) && actionType == provider.ActionSimStateChange {
+		legacyErr := c.bulkUpdateStatusLegacy(ctx, msisdns, targetValue)
 		if legacyErr == nil {
+			// The legacy endpoint returns no requestId: 0 tells the worker there is no
+			// provider job to confirm, so the task does not wait in AWAITING_PROVIDER.
 			return &models.BulkUpdateResponse{
 				ResponseBase: models.ResponseBase{Result: "succeeded", Message: "legacy simulator endpoint"},
-				RequestId:    int(time.Now().Unix()),
+				RequestId:    0,
 			}, nil
 		}
 		return nil, legacyErr
 	}
 
 	var result models.BulkUpdateResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("unmarshal error: %w", err)
-	}
-
-	if result.Result == "SUCCESS" || result.Result == "succeeded" {
-		log.Printf("[EyesOnT API] BulkUpdate SUCCESS: requestId=%d", result.RequestId)
-	} else {
-		log.Printf("[EyesOnT API] BulkUpdate FAILED: %s - %s", result.Result, result.Message)
+	if err := decodeResponse("BulkUpdate", resp.StatusCode, body, &result); err != nil {
+		log.Printf("[EyesOnT API] BulkUpdate FAILED: %v", err)
+		return nil, err
 	}
 
+	log.Printf("[EyesOnT API] BulkUpdate SUCCESS: requestId=%d", result.RequestId)
 	return &result, nil
 }
 
 // BulkUpdateLabel обновляет метку SIM-карт
 // labelNum: "1", "2", "3" для label_1, label_2, label_3
 // Согласно спецификации PDF v1.5.2: actionType = "CUSTOMER_LABEL_1" или "CUSTOMER_LABEL_2"
-func (c *Client) BulkUpdateLabel(msisdns []string, labelNum, targetValue string) (*models.BulkUpdateResponse, error) {
+func (c *Client) BulkUpdateLabel(ctx context.Context, msisdns []string, labelNum, targetValue string) (*models.BulkUpdateResponse, error) {
 	url := fmt.Sprintf("%s/ipa/apis/json/provisioning/updateProvisioningData", c.BaseURL)
 
 	// Нормализуем MSISDN для Pelephone API (972xxx -> 0xxx)
@@ -450,7 +592,7 @@ func (c *Client) BulkUpdateLabel(msisdns []string, labelNum, targetValue string)
 		Password: c.Password,
 	}
 
-	resp, err := c.doRequest("POST", url, reqBody)
+	resp, err := c.doRequest(ctx, "POST", url, reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -460,25 +602,41 @@ func (c *Client) BulkUpdateLabel(msisdns []string, labelNum, targetValue string)
 	log.Printf("[EyesOnT API] BulkUpdateLabel RESPONSE (status=%d, bytes=%d)", resp.StatusCode, len(body))
 
 	var result models.BulkUpdateResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("unmarshal error: %w", err)
+	if err := decodeResponse("BulkUpdateLabel", resp.StatusCode, body, &result); err != nil {
+		log.Printf("[EyesOnT API] BulkUpdateLabel FAILED: %v", err)
+		return nil, err
 	}
 
-	if result.Result == "SUCCESS" || result.Result == "succeeded" {
-		log.Printf("[EyesOnT API] BulkUpdateLabel SUCCESS: requestId=%d", result.RequestId)
-	} else {
-		log.Printf("[EyesOnT API] BulkUpdateLabel FAILED: %s - %s", result.Result, result.Message)
+	log.Printf("[EyesOnT API] BulkUpdateLabel SUCCESS: requestId=%d", result.RequestId)
+	return &result, nil
+}
+
+// ChangeRatePlan назначает новый (future) тарифный план SIM-картам
+// Согласно спецификации PDF v1.5.2: actionType = "RATE_PLAN_CHANGE", targetValue = имя плана
+// из getProvisioningParameterList
+func (c *Client) ChangeRatePlan(ctx context.Context, msisdns []string, ratePlan string) (*models.BulkUpdateResponse, error) {
+	if strings.TrimSpace(ratePlan) == "" {
+		return nil, NewValidationError("ChangeRatePlan", "rate plan is required")
 	}
+	return c.BulkUpdate(ctx, msisdns, provider.ActionRatePlanChange, ratePlan)
+}
 
-	return &result, nil
+// SwapSim заменяет ICCID абонента (SIM_SWAP)
+// Согласно спецификации PDF v1.5.2: targetValue = новый ICCID (free text), один абонент
+func (c *Client) SwapSim(ctx context.Context, msisdn, newICCID string) (*models.BulkUpdateResponse, error) {
+	newICCID = strings.TrimSpace(newICCID)
+	if newICCID == "" {
+		return nil, NewValidationError("SwapSim", "new ICCID is required")
+	}
+	return c.BulkUpdate(ctx, []string{msisdn}, provider.ActionSimSwap, newICCID)
 }
 
-func (c *Client) bulkUpdateStatusLegacy(msisdns []string, newStatus string) error {
+func (c *Client) bulkUpdateStatusLegacy(ctx context.Context, msisdns []string, newStatus string) error {
 	url := fmt.Sprintf("%s/ipa/apis/json/provisioning/updateSIMStatusChange", c.BaseURL)
 
 	for _, m := range msisdns {
 		cli := NormalizeMSISDN(m)
-		resp, err := c.doRequest("POST", url, map[string]string{"cli": cli, "status": newStatus})
+		resp, err := c.doRequest(ctx, "POST", url, map[string]string{"cli": cli, "status": newStatus})
 		if err != nil {
 			return err
 		}
@@ -486,7 +644,7 @@ func (c *Client) bulkUpdateStatusLegacy(msisdns []string, newStatus string) erro
 		resp.Body.Close()
 
 		if resp.StatusCode >= 400 {
-			return fmt.Errorf("legacy updateSIMStatusChange failed (status=%d, body=%s)", resp.StatusCode, string(body))
+			return httpStatusError("updateSIMStatusChange", resp.StatusCode, body)
 		}
 
 		var parsed struct {
@@ -495,10 +653,7 @@ func (c *Client) bulkUpdateStatusLegacy(msisdns []string, newStatus string) erro
 		}
 		if err := json.Unmarshal(body, &parsed); err == nil {
 			if parsed.Result != "" && parsed.Result != "succeeded" {
-				if parsed.Error != "" {
-					return fmt.Errorf("legacy updateSIMStatusChange failed: %s", parsed.Error)
-				}
-				return fmt.Errorf("legacy updateSIMStatusChange failed: result=%s", parsed.Result)
+				return resultError("updateSIMStatusChange", resp.StatusCode, parsed.Result, parsed.Error)
 			}
 		}
 	}
@@ -508,7 +663,7 @@ func (c *Client) bulkUpdateStatusLegacy(msisdns []string, newStatus string) erro
 
 // UpdateSIMLabel обновляет метку SIM-карты (CUSTOMER_LABEL_UPDATE)
 // field: "label_1", "label_2", "label_3"
-func (c *Client) UpdateSIMLabel(cli, field, value string) (*models.BulkUpdateResponse, error) {
+func (c *Client) UpdateSIMLabel(ctx context.Context, cli, field, value string) (*models.BulkUpdateResponse, error) {
 	// Маппинг полей на API targetId (номер метки)
 	targetIdMap := map[string]string{
 		"label_1": "1",
@@ -521,11 +676,11 @@ func (c *Client) UpdateSIMLabel(cli, field, value string) (*models.BulkUpdateRes
 		targetId = "1" // Default to label_1
 	}
 
-	return c.BulkUpdateLabel([]string{cli}, targetId, value)
+	return c.BulkUpdateLabel(ctx, []string{cli}, targetId, value)
 }
 
 // GetJobs получает список задач провизионирования
-func (c *Client) GetJobs(start, limit, jobId int, jobStatus string) (*models.GetJobsResponse, error) {
+func (c *Client) GetJobs(ctx context.Context, start, limit, jobId int, jobStatus string) (*models.GetJobsResponse, error) {
 	url := fmt.Sprintf("%s/ipa/apis/json/provisioning/getProvisioningJobList", c.BaseURL)
 
 	// Формируем запрос с username и password
@@ -551,7 +706,7 @@ func (c *Client) GetJobs(start, limit, jobId int, jobStatus string) (*models.Get
 		reqBody.JobStatus = jobStatus
 	}
 
-	resp, err := c.doRequest("POST", url, reqBody)
+	resp, err := c.doRequest(ctx, "POST", url, reqBody)
 	if err != nil {
 		return nil, err
 	}
@@ -572,30 +727,63 @@ func (c *Client) GetJobs(start, limit, jobId int, jobStatus string) (*models.Get
 		}, nil
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("GetJobs provider error: status=%d", resp.StatusCode)
-	}
-
 	var result models.GetJobsResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		log.Printf("[EyesOnT API] GetJobs DECODE ERROR: %v", err)
+	if err := decodeResponse("GetJobs", resp.StatusCode, body, &result); err != nil {
+		log.Printf("[EyesOnT API] GetJobs ERROR: %v", err)
 		return nil, err
 	}
 
+	// PDF v1.5.2 возвращает статус job в поле "status", фронтенд ожидает "jobStatus"
+	for i := range result.Jobs {
+		if result.Jobs[i].JobStatus == "" {
+			result.Jobs[i].JobStatus = result.Jobs[i].Status
+		}
+	}
+
 	log.Printf("[EyesOnT API] GetJobs PARSED: result=%s, count=%d, jobsLen=%d", result.Result, result.Count, len(result.Jobs))
 	return &result, nil
 }
 
+// GetParameters получает список параметров провизионирования и уровни доступа к ним
+func (c *Client) GetParameters(ctx context.Context) (*models.GetProvisioningParameterListResponse, error) {
+	url := fmt.Sprintf("%s/ipa/apis/json/provisioning/getProvisioningParameterList", c.BaseURL)
+
+	reqBody := struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{
+		Username: c.Username,
+		Password: c.Password,
+	}
+
+	resp, err := c.doRequest(ctx, "POST", url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	log.Printf("[EyesOnT API] GetParameters RESPONSE (status=%d, bytes=%d)", resp.StatusCode, len(body))
+
+	var result models.GetProvisioningParameterListResponse
+	if err := decodeResponse("GetParameters", resp.StatusCode, body, &result); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[EyesOnT API] GetParameters PARSED: result=%s, parameters=%d", result.Result, len(result.Parameters))
+	return &result, nil
+}
+
 // GetSimStatus queries the upstream API for a single SIM's current status.
 // Returns the SIM_STATUS_CHANGE value or empty string if not found.
 // This is used for pre-validation before sending status change requests.
-func (c *Client) GetSimStatus(msisdn string) (string, error) {
+func (c *Client) GetSimStatus(ctx context.Context, msisdn string) (string, error) {
 	normalizedID := NormalizeMSISDN(msisdn)
 	searchCriteria := []models.SearchParam{
 		{FieldName: "MSISDN", FieldValue: normalizedID},
 	}
 
-	resp, err := c.GetSims(0, 1, searchCriteria, "", "")
+	resp, err := c.GetSims(ctx, 0, 1, searchCriteria, "", "")
 	if err != nil {
 		return "", fmt.Errorf("failed to query SIM status from upstream: %w", err)
 	}
@@ -605,27 +793,26 @@ func (c *Client) GetSimStatus(msisdn string) (string, error) {
 		searchCriteria = []models.SearchParam{
 			{FieldName: "CLI", FieldValue: normalizedID},
 		}
-		resp, err = c.GetSims(0, 1, searchCriteria, "", "")
+		resp, err = c.GetSims(ctx, 0, 1, searchCriteria, "", "")
 		if err != nil {
 			return "", fmt.Errorf("failed to query SIM status by CLI from upstream: %w", err)
 		}
 	}
 
 	if len(resp.Data) == 0 {
-		return "", fmt.Errorf("subscriber %s not found in upstream API", normalizedID)
+		return "", resultError("GetSimStatus", http.StatusOK, ResultMissingEntity, fmt.Sprintf("subscriber %s not found in upstream API", normalizedID))
 	}
 
 	return resp.Data[0].SimStatusChange, nil
 }
 
 // CheckConnection checks if the API is reachable
-func (c *Client) CheckConnection() bool {
+func (c *Client) CheckConnection(ctx context.Context) bool {
 	// Simple health check using login endpoint (assuming session is valid, or just checking reachability)
 	// We can use a lightweight call, or just try to connect to base URL.
 	// Since there isn't a dedicated "ping", we'll check if we can reach the server.
 
-	resp, err := c.httpClient.Get(c.BaseURL + "/ipa/apis/json/general/login") // Method Not Allowed is fine, means we reached it.
-	// Actually, our simulator only has POST.
+	// A GET would do (Method Not Allowed is fine, means we reached it), but our simulator only has POST.
 	// Let's try to perform a dummy POST to login with bad credentials, or just check connectivity.
 	// Or we can assume if the last request was < X seconds ago and successful, we are good.
 	// But "real-time" check requires a request.
@@ -640,12 +827,12 @@ func (c *Client) CheckConnection() bool {
 	// NOTE: Since our simulator expects POST, a GET might return 404 or 405, but that implies connectivity = ONLINE.
 	// Connection Refused means OFFLINE.
 
-	req, _ := http.NewRequest("POST", c.BaseURL+"/ipa/apis/json/general/login", nil)
 	// Short timeout for check
-	client := *c.httpClient
-	client.Timeout = 2 * time.Second
+	ctx, cancel := context.WithTimeout(ctx, connectionCheckTimeout)
+	defer cancel()
 
-	resp, err = client.Do(req)
+	req, _ := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/ipa/apis/json/general/login", nil)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,53 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"context"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════
+// CANCELLATION & DEADLINES
+// ═══════════════════════════════════════════════════════════
+
+// DefaultRequestTimeout - дедлайн одного HTTP-запроса к провайдеру (включая чтение тела)
+const DefaultRequestTimeout = 30 * time.Second
+
+// connectionCheckTimeout - дедлайн проверки доступности в CheckConnection
+const connectionCheckTimeout = 2 * time.Second
+
+// requestTimeout - RequestTimeout = 0 означает DefaultRequestTimeout
+func (c *Client) requestTimeout() time.Duration {
+	if c.RequestTimeout > 0 {
+		return c.RequestTimeout
+	}
+	return DefaultRequestTimeout
+}
+
+// withCallDeadline ограничивает один вызов дедлайном клиента.
+// Более ранний дедлайн вызывающего (например, при shutdown) остаётся в силе.
+func (c *Client) withCallDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, c.requestTimeout())
+}
+
+// SleepContext ждёт d или отмены ctx. Возвращает ctx.Err(), если ожидание прервано.
+func SleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
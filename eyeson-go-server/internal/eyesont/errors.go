@@ -0,0 +1,245 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"eyeson-go-server/internal/models"
+)
+
+// ═══════════════════════════════════════════════════════════
+// UPSTREAM ERROR TAXONOMY
+// ═══════════════════════════════════════════════════════════
+
+// ErrorKind - класс ошибки при обращении к провайдеру
+type ErrorKind string
+
+const (
+	ErrKindTransport   ErrorKind = "TRANSPORT"    // Сеть: DNS, connect, TLS, timeout
+	ErrKindHTTPStatus  ErrorKind = "HTTP_STATUS"  // Не-2xx без разборчивого JSON
+	ErrKindNonJSON     ErrorKind = "NON_JSON"     // HTML вместо JSON (WAF / прокси / страница логина)
+	ErrKindDecode      ErrorKind = "DECODE"       // JSON, который не удалось разобрать
+	ErrKindResult      ErrorKind = "RESULT"       // result != SUCCESS (enum из PDF v1.5.2)
+	ErrKindValidation  ErrorKind = "VALIDATION"   // Некорректный запрос, отклонён до отправки
+	ErrKindAuth        ErrorKind = "AUTH"         // Сессия недействительна даже после повторного логина
+	ErrKindCanceled    ErrorKind = "CANCELED"     // Запрос прерван вызывающим (отмена задачи, sync, shutdown)
+	ErrKindCircuitOpen ErrorKind = "CIRCUIT_OPEN" // Не отправлен: circuit breaker upstream'а открыт
+)
+
+// Значения поля result (PDF v1.5.2)
+const (
+	ResultSuccess       = "SUCCESS"
+	ResultRejected      = "REJECTED"
+	ResultInvalidReq    = "INVALID_REQ"
+	ResultMissingEntity = "MISSING_ENTITY"
+	ResultFailed        = "FAILED"
+)
+
+// APIError - структурированная ошибка EyesOnT API
+type APIError struct {
+	Kind       ErrorKind
+	Op         string // Метод клиента: GetSims, BulkUpdate, ...
+	StatusCode int    // HTTP статус (0 для транспортных ошибок)
+	Result     string // Значение result из ответа (для ErrKindResult)
+	Message    string
+	Retryable  bool
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	b.WriteString(": ")
+	switch e.Kind {
+	case ErrKindResult:
+		fmt.Fprintf(&b, "API Error: %s", e.Result)
+		if e.Message != "" {
+			fmt.Fprintf(&b, " - %s", e.Message)
+		}
+	case ErrKindHTTPStatus:
+		fmt.Fprintf(&b, "provider error: status=%d", e.StatusCode)
+		if e.Message != "" {
+			fmt.Fprintf(&b, " - %s", e.Message)
+		}
+	case ErrKindNonJSON:
+		fmt.Fprintf(&b, "API returned non-JSON response (status=%d, possibly HTML error page): %s", e.StatusCode, e.Message)
+	default:
+		b.WriteString(e.Message)
+	}
+	if e.Err != nil {
+		if e.Message != "" || e.Kind == ErrKindResult || e.Kind == ErrKindHTTPStatus || e.Kind == ErrKindNonJSON {
+			b.WriteString(": ")
+		}
+		b.WriteString(e.Err.Error())
+	}
+	return b.String()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// AsAPIError извлекает *APIError из цепочки ошибок
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// IsRetryable сообщает, имеет ли смысл повторять запрос.
+// Нетипизированные ошибки (не от провайдера) не повторяются: повтор их не исправит.
+func IsRetryable(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.Retryable
+}
+
+// IsResult проверяет, что ошибка - отказ провайдера с указанным result
+func IsResult(err error, result string) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.Kind == ErrKindResult && apiErr.Result == result
+}
+
+// IsCanceled сообщает, что запрос прерван отменой контекста, а не сбоем провайдера
+func IsCanceled(err error) bool {
+	if apiErr, ok := AsAPIError(err); ok && apiErr.Kind == ErrKindCanceled {
+		return true
+	}
+	return errors.Is(err, context.Canceled)
+}
+
+// NewValidationError - запрос некорректен, повторять бессмысленно
+func NewValidationError(op, message string) *APIError {
+	return &APIError{Kind: ErrKindValidation, Op: op, Message: message}
+}
+
+// transportError - сетевой сбой. Истёкший дедлайн вызова повторяем как таймаут,
+// отмену контекста - нет: её инициировал вызывающий.
+func transportError(op string, err error) *APIError {
+	if errors.Is(err, context.Canceled) {
+		return &APIError{Kind: ErrKindCanceled, Op: op, Message: "request canceled", Err: err}
+	}
+	return &APIError{Kind: ErrKindTransport, Op: op, Retryable: true, Err: err}
+}
+
+// httpStatusError - 5xx, 408, 429 и 401/403 (WAF, истёкшая сессия) повторяемы, остальные 4xx - нет
+func httpStatusError(op string, statusCode int, body []byte) *APIError {
+	retryable := statusCode >= 500 ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode == http.StatusUnauthorized ||
+		statusCode == http.StatusForbidden
+	return &APIError{
+		Kind:       ErrKindHTTPStatus,
+		Op:         op,
+		StatusCode: statusCode,
+		Message:    bodyPreview(body, 300),
+		Retryable:  retryable,
+	}
+}
+
+// resultError - провайдер ответил, но result != SUCCESS.
+// FAILED - сбой на стороне провайдера, его повторяем; REJECTED / INVALID_REQ / MISSING_ENTITY
+// не изменятся от повтора.
+func resultError(op string, statusCode int, result, message string) *APIError {
+	normalized := strings.ToUpper(strings.TrimSpace(result))
+	retryable := true
+	switch normalized {
+	case ResultRejected, ResultInvalidReq, ResultMissingEntity:
+		retryable = false
+	}
+	return &APIError{
+		Kind:       ErrKindResult,
+		Op:         op,
+		StatusCode: statusCode,
+		Result:     normalized,
+		Message:    message,
+		Retryable:  retryable,
+	}
+}
+
+// isSuccessResult - SUCCESS по спецификации, "succeeded" у старого симулятора
+func isSuccessResult(result string) bool {
+	return strings.EqualFold(result, ResultSuccess) || strings.EqualFold(result, "succeeded")
+}
+
+// optionalResultOps - чтения, на которые провайдер может ответить без поля result.
+// Для записи (updateProvisioningData) и сессии ответ без result - не подтверждение:
+// так выглядит и JSON от WAF или шлюза.
+var optionalResultOps = map[string]bool{
+	"GetSims":       true,
+	"GetJobs":       true,
+	"GetParameters": true,
+}
+
+// resultCarrier - ответы, содержащие models.ResponseBase
+type resultCarrier interface {
+	GetBase() models.ResponseBase
+}
+
+// decodeResponse проверяет формат и статус ответа, разбирает JSON в out и
+// превращает result != SUCCESS в ErrKindResult
+func decodeResponse(op string, statusCode int, body []byte, out interface{}) error {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] != '{' && trimmed[0] != '[' {
+		return &APIError{
+			Kind:       ErrKindNonJSON,
+			Op:         op,
+			StatusCode: statusCode,
+			Message:    bodyPreview(trimmed, 500),
+			Retryable:  true,
+		}
+	}
+
+	if len(trimmed) == 0 {
+		if statusCode < 200 || statusCode >= 300 {
+			return httpStatusError(op, statusCode, body)
+		}
+		return &APIError{Kind: ErrKindDecode, Op: op, StatusCode: statusCode, Message: "empty response body", Retryable: true}
+	}
+
+	if err := json.Unmarshal(trimmed, out); err != nil {
+		if statusCode < 200 || statusCode >= 300 {
+			return httpStatusError(op, statusCode, body)
+		}
+		return &APIError{
+			Kind:       ErrKindDecode,
+			Op:         op,
+			StatusCode: statusCode,
+			Message:    "unmarshal error (response: " + bodyPreview(trimmed, 300) + ")",
+			Retryable:  true,
+			Err:        err,
+		}
+	}
+
+	if rc, ok := out.(resultCarrier); ok {
+		base := rc.GetBase()
+		if !isSuccessResult(base.Result) && !(base.Result == "" && optionalResultOps[op]) {
+			return resultError(op, statusCode, base.Result, base.Message)
+		}
+	}
+
+	if statusCode < 200 || statusCode >= 300 {
+		return httpStatusError(op, statusCode, body)
+	}
+	return nil
+}
+
+func bodyPreview(body []byte, max int) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) > max {
+		return s[:max] + "..."
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"eyeson-go-server/internal/models"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"untyped", errors.New("record not found"), false},
+		{"typed retryable", &APIError{Kind: ErrKindTransport, Retryable: true}, true},
+		{"typed not retryable", NewValidationError("BulkUpdate", "empty list"), false},
+		{"wrapped retryable", fmt.Errorf("sync: %w", &APIError{Kind: ErrKindHTTPStatus, Retryable: true}), true},
+		{"transport", transportError("GetSims", errors.New("connection reset")), true},
+		{"canceled", transportError("GetSims", context.Canceled), false},
+		{"result failed", resultError("GetJobs", 200, "failed", ""), true},
+		{"result rejected", resultError("BulkUpdate", 200, "REJECTED", "not allowed"), false},
+		{"missing entity", resultError("GetSims", 200, "MISSING_ENTITY", ""), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPStatusErrorRetryable(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusNotFound, false},
+		{http.StatusUnauthorized, true},
+		{http.StatusForbidden, true},
+		{http.StatusRequestTimeout, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		if got := httpStatusError("op", tt.status, nil).Retryable; got != tt.want {
+			t.Errorf("status %d: retryable = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		op     string
+		status int
+		body   string
+		kind   ErrorKind // Пусто - ошибки нет
+	}{
+		{"success", "BulkUpdate", 200, `{"result":"SUCCESS"}`, ""},
+		{"old simulator", "BulkUpdate", 200, `{"result":"succeeded"}`, ""},
+		{"rejected", "BulkUpdate", 200, `{"result":"REJECTED","message":"no"}`, ErrKindResult},
+		{"update without result", "BulkUpdate", 200, `{}`, ErrKindResult},
+		{"gateway json on update", "UpdateProvisioningSet", 200, `{"status":"ok"}`, ErrKindResult},
+		{"read without result", "GetSims", 200, `{}`, ""},
+		{"html", "GetSims", 200, "<html>login</html>", ErrKindNonJSON},
+		{"empty", "GetSims", 200, "", ErrKindDecode},
+		{"empty 502", "GetSims", 502, "", ErrKindHTTPStatus},
+		{"broken json", "GetSims", 200, `{"result":`, ErrKindDecode},
+		{"5xx with json", "GetSims", 500, `{"result":"SUCCESS"}`, ErrKindHTTPStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out models.ResponseBase
+			err := decodeResponse(tt.op, tt.status, []byte(tt.body), &out)
+			if tt.kind == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			apiErr, ok := AsAPIError(err)
+			if !ok || apiErr.Kind != tt.kind {
+				t.Fatalf("error = %v, want kind %s", err, tt.kind)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"context"
+	"strings"
+
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+)
+
+// ═══════════════════════════════════════════════════════════
+// PROVIDER IMPLEMENTATION (PELEPHONE EYESONT)
+// ═══════════════════════════════════════════════════════════
+
+// ProviderName - имя EyesOnT в реестре провайдеров. Симулятор говорит на том же API,
+// поэтому SIM, полученные из него, тоже помечаются как pelephone.
+const ProviderName = "pelephone"
+
+var (
+	_ provider.Provider     = (*Client)(nil)
+	_ provider.StatusLookup = (*Client)(nil)
+	_ provider.Session      = (*Client)(nil)
+	_ provider.Availability = (*Client)(nil)
+	_ provider.Chunker      = (*Client)(nil)
+)
+
+func init() {
+	provider.Register(ProviderName, func(cfg provider.Config) (provider.Provider, error) {
+		client, err := NewClientWithTransport(cfg.BaseURL, cfg.Username, cfg.Password, cfg.ApiDelayMs, TransportOf(cfg))
+		if err != nil {
+			return nil, err
+		}
+		client.SessionMaxAge = cfg.SessionMaxAge
+		client.RequestTimeout = cfg.RequestTimeout
+		client.MaxSubscribers = cfg.BulkChunkSize
+		rate := cfg.RatePerSec
+		if rate <= 0 {
+			rate = RateFromDelay(cfg.ApiDelayMs)
+		}
+		client.SetRateLimit(rate, cfg.RateBurst)
+		return client, nil
+	})
+}
+
+// TransportOf - сетевые параметры клиента из конфигурации провайдера
+func TransportOf(cfg provider.Config) TransportConfig {
+	return TransportConfig{
+		InsecureTLS:    cfg.InsecureTLS,
+		ProxyURL:       cfg.ProxyURL,
+		NoProxy:        cfg.NoProxy,
+		CAFile:         cfg.CAFile,
+		ClientCertFile: cfg.ClientCertFile,
+		ClientKeyFile:  cfg.ClientKeyFile,
+	}
+}
+
+// Name - имя провайдера
+func (c *Client) Name() string {
+	return ProviderName
+}
+
+// ListSubscribers - getProvisioningData с сортировкой по умолчанию
+func (c *Client) ListSubscribers(ctx context.Context, start, limit int, search []models.SearchParam) (*models.GetProvisioningDataResponse, error) {
+	return c.GetSims(ctx, start, limit, search, "", "")
+}
+
+// UpdateProvisioning - updateProvisioningData; CUSTOMER_LABEL_N идёт через BulkUpdateLabel
+func (c *Client) UpdateProvisioning(ctx context.Context, msisdns []string, actionType, targetValue string) (*models.BulkUpdateResponse, error) {
+	if provider.IsLabelAction(actionType) {
+		return c.BulkUpdateLabel(ctx, msisdns, strings.TrimPrefix(actionType, "CUSTOMER_LABEL_"), targetValue)
+	}
+	return c.BulkUpdate(ctx, msisdns, actionType, targetValue)
+}
+
+// ListJobs - getProvisioningJobList
+func (c *Client) ListJobs(ctx context.Context, start, limit, jobID int, jobStatus string) (*models.GetJobsResponse, error) {
+	return c.GetJobs(ctx, start, limit, jobID, jobStatus)
+}
+
+// Health проверяет доступность API (см. CheckConnection)
+func (c *Client) Health(ctx context.Context) error {
+	if !c.CheckConnection(ctx) {
+		return &APIError{Kind: ErrKindTransport, Op: "Health", Message: "provider unreachable: " + c.BaseURL, Retryable: true}
+	}
+	return nil
+}
+
+// Available - false, пока circuit breaker upstream'а открыт
+func (c *Client) Available() bool {
+	return c.breaker.Ready()
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════
+// RATE LIMITER (TOKEN BUCKET WITH PRIORITY LANES)
+// ═══════════════════════════════════════════════════════════
+
+// Lane - очередь ожидания токена. Интерактивные запросы (действия пользователя,
+// предварительная проверка статуса) получают токен раньше страниц фоновой синхронизации.
+type Lane int
+
+const (
+	LaneInteractive Lane = iota
+	LaneBulk
+)
+
+func (l Lane) String() string {
+	if l == LaneBulk {
+		return "bulk"
+	}
+	return "interactive"
+}
+
+type laneKey struct{}
+
+// WithLane помечает запросы ctx очередью лимитера. Без пометки запрос интерактивный.
+func WithLane(ctx context.Context, lane Lane) context.Context {
+	return context.WithValue(ctx, laneKey{}, lane)
+}
+
+// WithBulk - сокращение для фоновых запросов (syncer, reconciler, отложенные синхронизации)
+func WithBulk(ctx context.Context) context.Context {
+	return WithLane(ctx, LaneBulk)
+}
+
+func laneFrom(ctx context.Context) Lane {
+	if lane, ok := ctx.Value(laneKey{}).(Lane); ok {
+		return lane
+	}
+	return LaneInteractive
+}
+
+// utilizationWindow - окно, за которое считается загрузка лимитера
+const utilizationWindow = time.Minute
+
+// RateLimiter - token bucket одного клиента: Rate токенов в секунду, не больше Burst в запасе.
+// Ожидание не держит мьютекс; пока есть интерактивные ожидающие, bulk-запросы токен не берут.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // токенов в секунду; 0 - без ограничения
+	burst  int
+	tokens float64
+	last   time.Time
+
+	waiting  [2]int
+	acquired [2]int64
+	waitSum  [2]time.Duration
+
+	windowStart time.Time
+	windowCount int64
+	prevRate    float64 // загрузка предыдущего окна (0..1)
+}
+
+// RateLimiterStats - состояние лимитера для диагностики
+type RateLimiterStats struct {
+	RatePerSec  float64         `json:"rate_per_sec"`
+	Burst       int             `json:"burst"`
+	Available   float64         `json:"available_tokens"`
+	Utilization float64         `json:"utilization"` // доля пропускной способности за последнюю минуту (0..1)
+	Lanes       []RateLaneStats `json:"lanes"`
+}
+
+// RateLaneStats - статистика одной очереди
+type RateLaneStats struct {
+	Lane      string  `json:"lane"`
+	Waiting   int     `json:"waiting"`
+	Acquired  int64   `json:"acquired"`
+	AvgWaitMs float64 `json:"avg_wait_ms"`
+}
+
+// NewRateLimiter создаёт лимитер; ratePerSec <= 0 - без ограничения, burst < 1 считается 1
+func NewRateLimiter(ratePerSec float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	now := time.Now()
+	return &RateLimiter{
+		rate:        math.Max(ratePerSec, 0),
+		burst:       burst,
+		tokens:      float64(burst),
+		last:        now,
+		windowStart: now,
+	}
+}
+
+// RateFromDelay переводит прежний EYESON_API_DELAY_MS (пауза между запросами) в токены/сек
+func RateFromDelay(delayMs int) float64 {
+	if delayMs <= 0 {
+		return 0
+	}
+	return 1000 / float64(delayMs)
+}
+
+// SetLimit меняет скорость и burst на лету
+func (l *RateLimiter) SetLimit(ratePerSec float64, burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refillLocked(time.Now())
+	l.rate = math.Max(ratePerSec, 0)
+	l.burst = burst
+	if l.tokens > float64(burst) {
+		l.tokens = float64(burst)
+	}
+}
+
+// Wait ждёт токен в очереди lane. Возвращает ctx.Err(), если ожидание прервано.
+func (l *RateLimiter) Wait(ctx context.Context, lane Lane) error {
+	start := time.Now()
+	registered := false
+	defer func() {
+		if registered {
+			l.mu.Lock()
+			l.waiting[lane]--
+			l.mu.Unlock()
+		}
+	}()
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.refillLocked(now)
+
+		// Bulk уступает, пока интерактивные запросы ждут
+		yield := lane == LaneBulk && l.waiting[LaneInteractive] > 0
+		if l.rate == 0 || (l.tokens >= 1 && !yield) {
+			if l.rate > 0 {
+				l.tokens--
+			}
+			l.recordLocked(lane, now, now.Sub(start))
+			l.mu.Unlock()
+			return nil
+		}
+
+		if !registered {
+			l.waiting[lane]++
+			registered = true
+		}
+		delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		if yield || delay < time.Millisecond {
+			delay = time.Millisecond
+		}
+		l.mu.Unlock()
+
+		if err := SleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// Stats возвращает текущее состояние лимитера
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.refillLocked(now)
+	l.rollWindowLocked(now)
+
+	stats := RateLimiterStats{
+		RatePerSec: l.rate,
+		Burst:      l.burst,
+		Available:  math.Round(l.tokens*100) / 100,
+	}
+	if l.rate > 0 {
+		elapsed := now.Sub(l.windowStart).Seconds()
+		current := 0.0
+		if elapsed > 0 {
+			current = float64(l.windowCount) / (l.rate * elapsed)
+		}
+		// Начало окна - усредняем с предыдущим, чтобы значение не скакало
+		weight := math.Min(elapsed/utilizationWindow.Seconds(), 1)
+		stats.Utilization = math.Round(math.Min(current*weight+l.prevRate*(1-weight), 1)*1000) / 1000
+	}
+	for _, lane := range []Lane{LaneInteractive, LaneBulk} {
+		ls := RateLaneStats{Lane: lane.String(), Waiting: l.waiting[lane], Acquired: l.acquired[lane]}
+		if l.acquired[lane] > 0 {
+			ls.AvgWaitMs = math.Round(float64(l.waitSum[lane].Milliseconds())/float64(l.acquired[lane])*10) / 10
+		}
+		stats.Lanes = append(stats.Lanes, ls)
+	}
+	return stats
+}
+
+func (l *RateLimiter) refillLocked(now time.Time) {
+	if l.rate > 0 {
+		l.tokens = math.Min(float64(l.burst), l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+}
+
+func (l *RateLimiter) recordLocked(lane Lane, now time.Time, waited time.Duration) {
+	l.acquired[lane]++
+	l.waitSum[lane] += waited
+	l.rollWindowLocked(now)
+	l.windowCount++
+}
+
+func (l *RateLimiter) rollWindowLocked(now time.Time) {
+	if now.Sub(l.windowStart) < utilizationWindow {
+		return
+	}
+	if l.rate > 0 {
+		l.prevRate = math.Min(float64(l.windowCount)/(l.rate*now.Sub(l.windowStart).Seconds()), 1)
+	}
+	l.windowStart = now
+	l.windowCount = 0
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateFromDelay(t *testing.T) {
+	tests := []struct {
+		delayMs int
+		want    float64
+	}{
+		{0, 0},
+		{-5, 0},
+		{100, 10},
+		{250, 4},
+		{2000, 0.5},
+	}
+	for _, tt := range tests {
+		if got := RateFromDelay(tt.delayMs); got != tt.want {
+			t.Errorf("RateFromDelay(%d) = %v, want %v", tt.delayMs, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	l := NewRateLimiter(0, 0)
+	for i := 0; i < 100; i++ {
+		if err := l.Wait(context.Background(), LaneBulk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := l.Stats()
+	if stats.Burst != 1 || stats.Lanes[LaneBulk].Acquired != 100 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestRateLimiterBurstThenWait(t *testing.T) {
+	l := NewRateLimiter(50, 3) // Токен каждые 20ms
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background(), LaneInteractive); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 15*time.Millisecond {
+		t.Fatalf("burst took %s, want immediate", elapsed)
+	}
+
+	if err := l.Wait(context.Background(), LaneInteractive); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("fourth token after %s, want a wait of about 20ms", elapsed)
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	l := NewRateLimiter(0.1, 1)
+	if err := l.Wait(context.Background(), LaneBulk); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, LaneBulk); err == nil {
+		t.Fatal("Wait returned nil after context deadline")
+	}
+	if waiting := l.Stats().Lanes[LaneBulk].Waiting; waiting != 0 {
+		t.Errorf("waiting = %d after canceled wait, want 0", waiting)
+	}
+}
+
+func TestRateLimiterBulkYields(t *testing.T) {
+	l := NewRateLimiter(20, 1) // Токен каждые 50ms
+	if err := l.Wait(context.Background(), LaneBulk); err != nil {
+		t.Fatal(err) // Забрали единственный токен
+	}
+
+	order := make(chan Lane, 2)
+	go func() {
+		_ = l.Wait(context.Background(), LaneBulk)
+		order <- LaneBulk
+	}()
+	time.Sleep(2 * time.Millisecond) // Bulk встал в очередь первым
+	go func() {
+		_ = l.Wait(context.Background(), LaneInteractive)
+		order <- LaneInteractive
+	}()
+
+	if first := <-order; first != LaneInteractive {
+		t.Errorf("first token went to %s, want interactive", first)
+	}
+	<-order
+}
+
+func TestRateLimiterSetLimitClampsTokens(t *testing.T) {
+	l := NewRateLimiter(10, 10)
+	l.SetLimit(5, 2)
+	stats := l.Stats()
+	if stats.RatePerSec != 5 || stats.Burst != 2 || stats.Available > 2 {
+		t.Errorf("stats after SetLimit = %+v", stats)
+	}
+}
+
+func TestSendDeadlineExcludesLimiterWait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "user", "secret", 150, false) // Запрос раз в 150ms
+	c.RequestTimeout = 50 * time.Millisecond
+	ctx := WithBulk(context.Background())
+
+	// Второй запрос ждёт в очереди дольше своего таймаута, но сам отвечает сразу
+	for i := 0; i < 2; i++ {
+		if _, _, err := c.send(ctx, "GetJobs", http.MethodPost, srv.URL, nil); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════
+// SESSION LIFECYCLE
+// ═══════════════════════════════════════════════════════════
+
+// DefaultSessionMaxAge - возраст сессии, после которого логинимся заново до следующего запроса
+const DefaultSessionMaxAge = 25 * time.Minute
+
+// loginRetryInterval - пауза между попытками логина после неудачи
+const loginRetryInterval = 30 * time.Second
+
+// SessionState - состояние сессии EyesOnT для диагностики
+type SessionState struct {
+	LoggedIn        bool      `json:"logged_in"`
+	LoginTime       time.Time `json:"login_time,omitempty"`
+	Age             string    `json:"age,omitempty"`
+	ExpiresIn       string    `json:"expires_in,omitempty"`
+	Relogins        int       `json:"relogins"`
+	LastAuthFailure string    `json:"last_auth_failure,omitempty"`
+	LastAuthFailAt  time.Time `json:"last_auth_fail_at,omitempty"`
+}
+
+// Session возвращает текущее состояние сессии
+func (c *Client) Session() SessionState {
+	c.sessionMu.RLock()
+	defer c.sessionMu.RUnlock()
+
+	state := SessionState{
+		LoggedIn:        c.loggedIn,
+		Relogins:        c.relogins,
+		LastAuthFailure: c.lastAuthFailure,
+		LastAuthFailAt:  c.lastAuthFailAt,
+	}
+	if c.loggedIn {
+		age := time.Since(c.loginTime)
+		state.LoginTime = c.loginTime
+		state.Age = age.Truncate(time.Second).String()
+		state.ExpiresIn = (c.sessionMaxAge() - age).Truncate(time.Second).String()
+	}
+	return state
+}
+
+func (c *Client) sessionMaxAge() time.Duration {
+	if c.SessionMaxAge > 0 {
+		return c.SessionMaxAge
+	}
+	return DefaultSessionMaxAge
+}
+
+// ensureSession логинится, если сессии нет или она старше SessionMaxAge
+func (c *Client) ensureSession(ctx context.Context) {
+	c.sessionMu.RLock()
+	valid := c.loggedIn && time.Since(c.loginTime) < c.sessionMaxAge()
+	recentlyTried := !c.loggedIn && time.Since(c.lastLoginTry) < loginRetryInterval
+	loginTime := c.loginTime
+	c.sessionMu.RUnlock()
+
+	// Провайдер недоступен - не логинимся перед каждым запросом, логин/пароль и так в теле
+	if valid || recentlyTried {
+		return
+	}
+
+	reason := "no active session"
+	if !loginTime.IsZero() {
+		reason = "session age exceeded " + c.sessionMaxAge().String()
+	}
+	if err := c.relogin(ctx, loginTime, reason); err != nil {
+		log.Printf("[EyesOnT API] Proactive session refresh failed: %v", err)
+	}
+}
+
+// relogin заново открывает сессию. staleLoginTime - время логина сессии, которую
+// считаем недействительной: если другой запрос уже перелогинился, повторно не логинимся.
+func (c *Client) relogin(ctx context.Context, staleLoginTime time.Time, reason string) error {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	if c.loggedIn && c.loginTime.After(staleLoginTime) {
+		return nil
+	}
+
+	log.Printf("[EyesOnT API] Re-authenticating: %s", reason)
+	c.lastAuthFailure = reason
+	c.lastAuthFailAt = time.Now()
+	c.loggedIn = false
+
+	if err := c.loginLocked(ctx); err != nil {
+		return err
+	}
+	c.relogins++
+	return nil
+}
+
+// Logout закрывает сессию (/general/logout) и сбрасывает cookies
+func (c *Client) Logout(ctx context.Context) error {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	if !c.loggedIn {
+		return nil
+	}
+
+	logoutURL := fmt.Sprintf("%s/ipa/apis/json/general/logout", c.BaseURL)
+	body, _ := json.Marshal(struct {
+		Username string `json:"username"`
+	}{Username: c.Username})
+
+	ctx, cancel := c.withCallDeadline(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", logoutURL, bytes.NewBuffer(body))
+	if err != nil {
+		return &APIError{Kind: ErrKindValidation, Op: "Logout", Message: "request creation failed", Err: err}
+	}
+	setBrowserHeaders(req, c.BaseURL)
+	req.Header.Set("Content-Type", "application/json")
+
+	// Logout уходит с cookie сессии, которую закрывает. Сессию считаем закрытой в любом
+	// случае - при следующем запросе будет новый логин
+	defer func() {
+		c.loggedIn = false
+		c.jar.Reset()
+	}()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return transportError("Logout", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return transportError("Logout", err)
+	}
+	var result struct {
+		Result  string `json:"result"`
+		Message string `json:"message"`
+	}
+	if err := decodeResponse("Logout", resp.StatusCode, respBody, &result); err != nil {
+		return err
+	}
+	if !isSuccessResult(result.Result) {
+		return resultError("Logout", resp.StatusCode, result.Result, result.Message)
+	}
+
+	log.Printf("[EyesOnT API] LOGOUT SUCCESS")
+	return nil
+}
+
+// sessionJar - cookie jar, который можно очистить после logout. Сам http.Client не меняется,
+// поэтому запросы, уже ушедшие без sessionMu, не гоняются с заменой jar.
+type sessionJar struct {
+	mu  sync.RWMutex
+	jar *cookiejar.Jar
+}
+
+func newSessionJar() *sessionJar {
+	jar, _ := cookiejar.New(nil)
+	return &sessionJar{jar: jar}
+}
+
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	j.jar.SetCookies(u, cookies)
+}
+
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.jar.Cookies(u)
+}
+
+// Reset удаляет все cookies
+func (j *sessionJar) Reset() {
+	jar, _ := cookiejar.New(nil)
+	j.mu.Lock()
+	j.jar = jar
+	j.mu.Unlock()
+}
+
+// isAuthFailure распознаёт ответы, означающие потерю сессии:
+// 401, {"i_result":-1,"w_misc_msg":"Session is not valid"} и HTML-страницу логина
+func isAuthFailure(statusCode int, body []byte) (bool, string) {
+	if statusCode == http.StatusUnauthorized {
+		return true, "HTTP 401"
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return false, ""
+	}
+
+	if trimmed[0] == '{' {
+		var legacy struct {
+			IResult  *int   `json:"i_result"`
+			WMiscMsg string `json:"w_misc_msg"`
+		}
+		if json.Unmarshal(trimmed, &legacy) == nil && legacy.IResult != nil && *legacy.IResult < 0 {
+			msg := legacy.WMiscMsg
+			if msg == "" {
+				msg = "i_result=" + fmt.Sprint(*legacy.IResult)
+			}
+			return true, msg
+		}
+		return false, ""
+	}
+
+	lower := strings.ToLower(string(trimmed))
+	if strings.Contains(lower, "<form") && strings.Contains(lower, "password") &&
+		(strings.Contains(lower, "login") || strings.Contains(lower, "sign in")) {
+		return true, "HTML login page"
+	}
+	return false, ""
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sessionServer - httptest-сервер EyesOnT: считает логины и запросы, на остальные пути
+// отвечает data (номер запроса с 1)
+type sessionServer struct {
+	*httptest.Server
+	logins    atomic.Int32
+	requests  atomic.Int32
+	failLogin atomic.Bool
+}
+
+func newSessionServer(t *testing.T, data func(w http.ResponseWriter, n int32)) *sessionServer {
+	t.Helper()
+	s := &sessionServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/ipa/apis/json/general/login" {
+			s.logins.Add(1)
+			if s.failLogin.Load() {
+				w.Write([]byte(`{"result":"FAILED","message":"invalid credentials"}`))
+				return
+			}
+			w.Write([]byte(`{"result":"SUCCESS"}`))
+			return
+		}
+		data(w, s.requests.Add(1))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *sessionServer) get(ctx context.Context, c *Client) error {
+	resp, err := c.doRequest(ctx, http.MethodPost, s.URL+"/ipa/apis/json/provisioning/getProvisioningData", nil)
+	if err == nil {
+		resp.Body.Close()
+	}
+	return err
+}
+
+func writeSuccess(w http.ResponseWriter, _ int32) {
+	w.Write([]byte(`{"result":"SUCCESS"}`))
+}
+
+func TestLogoutSendsSessionCookie(t *testing.T) {
+	var logoutCookie string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ipa/apis/json/general/login":
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "s1", Path: "/"})
+		case "/ipa/apis/json/general/logout":
+			if c, err := r.Cookie("JSESSIONID"); err == nil {
+				logoutCookie = c.Value
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result":"SUCCESS"}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "user", "secret", 0, false)
+	ctx := context.Background()
+	if err := c.Login(ctx); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if err := c.Logout(ctx); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+
+	if logoutCookie != "s1" {
+		t.Errorf("logout cookie = %q, want s1", logoutCookie)
+	}
+	u, _ := url.Parse(srv.URL)
+	if cookies := c.jar.Cookies(u); len(cookies) != 0 {
+		t.Errorf("cookies after logout = %v, want none", cookies)
+	}
+	if c.Session().LoggedIn {
+		t.Error("session still logged in after logout")
+	}
+}
+
+func TestReloginReplaysOnceAfter401(t *testing.T) {
+	srv := newSessionServer(t, func(w http.ResponseWriter, n int32) {
+		if n == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeSuccess(w, n)
+	})
+
+	c := NewClient(srv.URL, "user", "secret", 0, false)
+	ctx := context.Background()
+	if err := c.Login(ctx); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if err := srv.get(ctx, c); err != nil {
+		t.Fatalf("request after 401: %v", err)
+	}
+
+	if logins, requests := srv.logins.Load(), srv.requests.Load(); logins != 2 || requests != 2 {
+		t.Errorf("logins = %d, requests = %d, want 2 and 2", logins, requests)
+	}
+	if st := c.Session(); st.Relogins != 1 || st.LastAuthFailure != "HTTP 401" {
+		t.Errorf("session = %+v, want one relogin after HTTP 401", st)
+	}
+}
+
+func TestReloginGivesUpAfterOneReplay(t *testing.T) {
+	srv := newSessionServer(t, func(w http.ResponseWriter, _ int32) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	c := NewClient(srv.URL, "user", "secret", 0, false)
+	ctx := context.Background()
+	if err := c.Login(ctx); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	err := srv.get(ctx, c)
+	if apiErr, ok := AsAPIError(err); !ok || apiErr.Kind != ErrKindAuth {
+		t.Fatalf("err = %v, want %s error", err, ErrKindAuth)
+	}
+	if requests := srv.requests.Load(); requests != 2 {
+		t.Errorf("requests = %d, want the original and one replay", requests)
+	}
+}
+
+func TestIsAuthFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantFailed bool
+		wantReason string
+	}{
+		{"401", http.StatusUnauthorized, "", true, "HTTP 401"},
+		{"i_result -1", 200, `{"i_result":-1,"w_misc_msg":"Session expired"}`, true, "Session expired"},
+		{"i_result -1 without message", 200, `{"i_result":-1}`, true, "i_result=-1"},
+		{"i_result 0", 200, `{"i_result":0}`, false, ""},
+		{"json success", 200, `{"result":"SUCCESS","data":[]}`, false, ""},
+		{"html login page", 200, `<html><body><form action="/login"><input type="password" name="password"></form></body></html>`, true, "HTML login page"},
+		{"html error page", 502, `<html><body>Bad Gateway</body></html>`, false, ""},
+		{"empty", 200, "", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failed, reason := isAuthFailure(tt.status, []byte(tt.body))
+			if failed != tt.wantFailed || reason != tt.wantReason {
+				t.Errorf("isAuthFailure = (%v, %q), want (%v, %q)", failed, reason, tt.wantFailed, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestReloginOnLoginPageAndIResult(t *testing.T) {
+	for name, body := range map[string]string{
+		"html login page": `<html><form method="post"><input name="password"> Sign in</form></html>`,
+		"i_result":        `{"i_result":-1,"w_misc_msg":"not logged in"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := newSessionServer(t, func(w http.ResponseWriter, n int32) {
+				if n == 1 {
+					w.Write([]byte(body))
+					return
+				}
+				writeSuccess(w, n)
+			})
+
+			c := NewClient(srv.URL, "user", "secret", 0, false)
+			ctx := context.Background()
+			if err := c.Login(ctx); err != nil {
+				t.Fatalf("Login: %v", err)
+			}
+			if err := srv.get(ctx, c); err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			if logins := srv.logins.Load(); logins != 2 {
+				t.Errorf("logins = %d, want 2", logins)
+			}
+		})
+	}
+}
+
+func TestSessionRefreshAfterMaxAge(t *testing.T) {
+	srv := newSessionServer(t, writeSuccess)
+
+	c := NewClient(srv.URL, "user", "secret", 0, false)
+	c.SessionMaxAge = 20 * time.Millisecond
+	ctx := context.Background()
+	if err := c.Login(ctx); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	// Сессия ещё свежая - запрос идёт без логина
+	if err := srv.get(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if logins := srv.logins.Load(); logins != 1 {
+		t.Fatalf("logins = %d before max age, want 1", logins)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := srv.get(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if logins := srv.logins.Load(); logins != 2 {
+		t.Errorf("logins = %d after max age, want 2", logins)
+	}
+	if st := c.Session(); st.Relogins != 1 || !st.LoggedIn {
+		t.Errorf("session = %+v, want logged in after one relogin", st)
+	}
+}
+
+func TestLoginRetryBackoff(t *testing.T) {
+	srv := newSessionServer(t, writeSuccess)
+	srv.failLogin.Store(true)
+
+	c := NewClient(srv.URL, "user", "secret", 0, false)
+	ctx := context.Background()
+
+	// Логин не удался, но запрос всё равно уходит (логин/пароль в теле)
+	if err := srv.get(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.get(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if logins := srv.logins.Load(); logins != 1 {
+		t.Fatalf("logins = %d within %s, want 1", logins, loginRetryInterval)
+	}
+
+	// Прошло loginRetryInterval - следующий запрос снова пробует войти
+	c.sessionMu.Lock()
+	c.lastLoginTry = c.lastLoginTry.Add(-loginRetryInterval)
+	c.sessionMu.Unlock()
+	srv.failLogin.Store(false)
+
+	if err := srv.get(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	if logins := srv.logins.Load(); logins != 2 {
+		t.Errorf("logins = %d after %s, want 2", logins, loginRetryInterval)
+	}
+	if !c.Session().LoggedIn {
+		t.Error("session not logged in after successful retry")
+	}
+}
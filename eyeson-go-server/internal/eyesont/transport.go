@@ -0,0 +1,234 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package eyesont
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════
+// TRANSPORT (PROXY / TLS / mTLS)
+// ═══════════════════════════════════════════════════════════
+
+// TransportConfig - сетевые параметры подключения к upstream
+type TransportConfig struct {
+	InsecureTLS bool
+
+	// Исходящий прокси: http://, https:// или socks5:// (socks5h://), можно с user:pass@.
+	// Пусто - прямое подключение.
+	ProxyURL string
+	// Хосты без прокси, через запятую (формат NO_PROXY: host, .domain, CIDR, host:port)
+	NoProxy string
+
+	// PEM-бандл дополнительных корневых сертификатов (к системным)
+	CAFile string
+
+	// Клиентский сертификат для mTLS (PEM), задаются парой
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+// TransportInfo - действующие сетевые настройки для диагностики (без секретов)
+type TransportInfo struct {
+	InsecureTLS bool   `json:"insecure_tls"`
+	MinTLS      string `json:"min_tls_version"`
+
+	Proxy       string   `json:"proxy,omitempty"` // URL прокси без пароля
+	ProxyScheme string   `json:"proxy_scheme,omitempty"`
+	NoProxy     []string `json:"no_proxy,omitempty"`
+
+	CAFile  string `json:"ca_file,omitempty"`
+	CACerts int    `json:"ca_certs,omitempty"` // Сертификатов загружено из CAFile
+
+	ClientCertFile     string     `json:"client_cert_file,omitempty"`
+	ClientCertSubject  string     `json:"client_cert_subject,omitempty"`
+	ClientCertIssuer   string     `json:"client_cert_issuer,omitempty"`
+	ClientCertNotAfter *time.Time `json:"client_cert_not_after,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// proxySchemes - схемы прокси, которые понимает net/http
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// NewTransport собирает http.Transport по конфигурации. Ошибка - если прокси или
+// сертификаты заданы неверно: клиент с такой конфигурацией создавать нельзя.
+func NewTransport(tc TransportConfig) (*http.Transport, error) {
+	tlsConfig, _, err := tc.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{TLSClientConfig: tlsConfig}
+
+	proxy, err := tc.proxyURL()
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		noProxy := splitNoProxy(tc.NoProxy)
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if bypassProxy(req.URL, noProxy) {
+				return nil, nil
+			}
+			return proxy, nil
+		}
+	}
+	return transport, nil
+}
+
+// Describe возвращает действующие настройки; ошибка конфигурации попадает в Error
+func (tc TransportConfig) Describe() TransportInfo {
+	info := TransportInfo{
+		InsecureTLS:    tc.InsecureTLS,
+		MinTLS:         "TLS1.2",
+		NoProxy:        splitNoProxy(tc.NoProxy),
+		CAFile:         tc.CAFile,
+		ClientCertFile: tc.ClientCertFile,
+	}
+
+	var errs []string
+	if proxy, err := tc.proxyURL(); err != nil {
+		errs = append(errs, err.Error())
+	} else if proxy != nil {
+		info.Proxy = redactProxy(proxy)
+		info.ProxyScheme = proxy.Scheme
+	}
+
+	tlsConfig, caCerts, err := tc.tlsConfig()
+	if err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		info.CACerts = caCerts
+		if len(tlsConfig.Certificates) > 0 {
+			if leaf, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0]); err == nil {
+				notAfter := leaf.NotAfter
+				info.ClientCertSubject = leaf.Subject.String()
+				info.ClientCertIssuer = leaf.Issuer.String()
+				info.ClientCertNotAfter = &notAfter
+			}
+		}
+	}
+	info.Error = strings.Join(errs, "; ")
+	return info
+}
+
+// tlsConfig - TLS-настройки клиента и число сертификатов, загруженных из CAFile
+func (tc TransportConfig) tlsConfig() (*tls.Config, int, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: tc.InsecureTLS}
+
+	caCerts := 0
+	if tc.CAFile != "" {
+		data, err := os.ReadFile(tc.CAFile)
+		if err != nil {
+			return nil, 0, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		for rest := data; ; {
+			var block *pem.Block
+			if block, rest = pem.Decode(rest); block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, 0, fmt.Errorf("CA bundle %s: %w", tc.CAFile, err)
+			}
+			pool.AddCert(cert)
+			caCerts++
+		}
+		if caCerts == 0 {
+			return nil, 0, fmt.Errorf("CA bundle %s contains no PEM certificates", tc.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (tc.ClientCertFile == "") != (tc.ClientKeyFile == "") {
+		return nil, 0, fmt.Errorf("client certificate and key must be set together")
+	}
+	if tc.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(tc.ClientCertFile, tc.ClientKeyFile)
+		if err != nil {
+			return nil, 0, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, caCerts, nil
+}
+
+// proxyURL разбирает ProxyURL; nil - прокси не задан
+func (tc TransportConfig) proxyURL() (*url.URL, error) {
+	raw := strings.TrimSpace(tc.ProxyURL)
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if !proxySchemes[u.Scheme] || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: expected http://, https:// or socks5://host:port", redactProxy(u))
+	}
+	return u, nil
+}
+
+// redactProxy - URL прокси без пароля
+func redactProxy(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	masked := *u
+	masked.User = url.User(u.User.Username())
+	return masked.String()
+}
+
+func splitNoProxy(raw string) []string {
+	var list []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// bypassProxy - хост запроса попадает в NO_PROXY: точное имя, суффикс домена
+// (".example.com" или "example.com" для поддоменов), host:port, IP или CIDR
+func bypassProxy(target *url.URL, noProxy []string) bool {
+	host := strings.ToLower(target.Hostname())
+	hostPort := strings.ToLower(target.Host)
+	ip := net.ParseIP(host)
+	for _, entry := range noProxy {
+		switch {
+		case entry == "*":
+			return true
+		case entry == hostPort || entry == host:
+			return true
+		case strings.Contains(entry, "/"):
+			if _, network, err := net.ParseCIDR(entry); err == nil && ip != nil && network.Contains(ip) {
+				return true
+			}
+		case ip == nil && strings.HasSuffix(host, "."+strings.TrimPrefix(entry, ".")):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ═══════════════════════════════════════════════════════════
+// UPSTREAM ACCOUNT HANDLERS (ADMIN)
+// ═══════════════════════════════════════════════════════════
+
+// AccountResponse - аккаунт без пароля: только признак, что он задан
+type AccountResponse struct {
+	models.UpstreamAccount
+	PasswordSet bool  `json:"password_set"`
+	SimCount    int64 `json:"sim_count"`
+}
+
+func toAccountResponse(acc models.UpstreamAccount) AccountResponse {
+	resp := AccountResponse{UpstreamAccount: acc, PasswordSet: acc.PasswordEnc != ""}
+	database.DB.Model(&models.SimCard{}).Where("account_id = ?", acc.ID).Count(&resp.SimCount)
+	return resp
+}
+
+// GetAccounts - список аккаунтов провайдера
+// GET /api/v1/accounts
+func GetAccounts(c *fiber.Ctx) error {
+	accounts, err := services.Accounts.List()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	items := make([]AccountResponse, 0, len(accounts))
+	for _, acc := range accounts {
+		items = append(items, toAccountResponse(acc))
+	}
+	return c.JSON(fiber.Map{
+		"items": items,
+		"count": len(items),
+	})
+}
+
+// CreateAccount - добавить аккаунт; его SIM подтянет следующий цикл синхронизации
+// POST /api/v1/accounts
+func CreateAccount(c *fiber.Ctx) error {
+	var req services.AccountInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	acc, err := services.Accounts.Create(req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	services.Audit.NewLog(c).
+		Entity(models.EntitySystem, "upstream_account:"+strconv.FormatUint(uint64(acc.ID), 10)).
+		Action(models.ActionCreate).
+		Change("name", "", acc.Name).
+		SetDetails("Upstream account created: " + acc.Name + " (" + acc.Provider + ", user=" + acc.Username + ")").
+		SaveAsync()
+
+	return c.Status(201).JSON(toAccountResponse(*acc))
+}
+
+// UpdateAccount - изменить аккаунт; пустой password оставляет прежний
+// PUT /api/v1/accounts/:id
+func UpdateAccount(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid account ID"})
+	}
+
+	var req services.AccountInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	acc, err := services.Accounts.Update(uint(id), req)
+	if err != nil {
+		return c.Status(accountErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	details := "Upstream account updated: " + acc.Name
+	if req.Password != nil && *req.Password != "" {
+		details += " (password changed)"
+	}
+	services.Audit.NewLog(c).
+		Entity(models.EntitySystem, "upstream_account:"+strconv.Itoa(id)).
+		Action(models.ActionUpdate).
+		SetDetails(details).
+		SaveAsync()
+
+	return c.JSON(toAccountResponse(*acc))
+}
+
+// DeleteAccount - удалить аккаунт без SIM
+// DELETE /api/v1/accounts/:id
+func DeleteAccount(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid account ID"})
+	}
+
+	if err := services.Accounts.Delete(uint(id)); err != nil {
+		status := accountErrorStatus(err)
+		if status == 400 {
+			status = 409
+		}
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	services.Audit.NewLog(c).
+		Entity(models.EntitySystem, "upstream_account:"+strconv.Itoa(id)).
+		Action(models.ActionDelete).
+		SaveAsync()
+
+	return c.JSON(fiber.Map{"success": true})
+}
+
+func accountErrorStatus(err error) int {
+	if errors.Is(err, services.ErrAccountNotFound) {
+		return 404
+	}
+	return 400
+}
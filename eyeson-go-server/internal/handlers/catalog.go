@@ -0,0 +1,58 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package handlers
+
+import (
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ═══════════════════════════════════════════════════════════
+// PROVISIONING PARAMETER CATALOG HANDLERS
+// ═══════════════════════════════════════════════════════════
+
+// GetCatalog - закэшированный каталог параметров провизионирования аккаунта
+// GET /api/v1/catalog?account_id= (без account_id - аккаунт по умолчанию)
+func GetCatalog(c *fiber.Ctx) error {
+	accountID := uint(c.QueryInt("account_id"))
+	snapshot := services.Catalog.Get(accountID)
+	if snapshot == nil {
+		return c.JSON(fiber.Map{
+			"account_id": accountID,
+			"loaded":     false,
+			"parameters": []models.ProvisioningParameter{},
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"account_id": accountID,
+		"loaded":     true,
+		"parameters": snapshot.Parameters,
+		"updated_at": snapshot.UpdatedAt,
+	})
+}
+
+// RefreshCatalog - принудительно перечитать каталог аккаунта у провайдера (admin)
+// POST /api/v1/catalog/refresh?account_id=
+func RefreshCatalog(c *fiber.Ctx) error {
+	accountID := uint(c.QueryInt("account_id"))
+	p, err := services.Accounts.Provider(accountID)
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	params, err := p.GetParameters(c.UserContext())
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := services.Catalog.Store(accountID, params); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return GetCatalog(c)
+}
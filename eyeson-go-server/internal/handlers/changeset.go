@@ -0,0 +1,170 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package handlers
+
+import (
+	"log"
+	"strings"
+
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+	"eyeson-go-server/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ═══════════════════════════════════════════════════════════
+// CHANGE SET (НЕСКОЛЬКО ИЗМЕНЕНИЙ ОДНОЙ ЗАДАЧЕЙ)
+// ═══════════════════════════════════════════════════════════
+
+// ChangeSetRequest - набор изменений для одной или нескольких SIM
+type ChangeSetRequest struct {
+	MSISDN    string              `json:"msisdn,omitempty"`
+	CLI       string              `json:"cli,omitempty"`
+	Msisdns   []string            `json:"msisdns,omitempty"`
+	Items     []map[string]string `json:"items,omitempty"`
+	Changes   []provider.Change   `json:"changes"`
+	RequestID string              `json:"request_id,omitempty"`
+}
+
+// ChangeSet ставит в очередь одну задачу CHANGE_SET: все действия уходят провайдеру одним
+// updateProvisioningData и применяются вместе (например, статус + тариф + метка)
+// POST /api/v1/sims/change-set
+func ChangeSet(c *fiber.Ctx) error {
+	var req ChangeSetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(BulkStatusResponse{
+			Success: false,
+			Error:   "Invalid request",
+		})
+	}
+
+	for i := range req.Changes {
+		req.Changes[i].ActionType = strings.ToUpper(strings.TrimSpace(req.Changes[i].ActionType))
+		req.Changes[i].TargetValue = strings.TrimSpace(req.Changes[i].TargetValue)
+	}
+
+	var msisdns []string
+	switch {
+	case len(req.Items) > 0:
+		for _, item := range req.Items {
+			if m := item["msisdn"]; m != "" {
+				msisdns = append(msisdns, m)
+			} else if item["cli"] != "" {
+				msisdns = append(msisdns, item["cli"])
+			}
+		}
+	case len(req.Msisdns) > 0:
+		msisdns = req.Msisdns
+	case req.MSISDN != "":
+		msisdns = []string{req.MSISDN}
+	case req.CLI != "":
+		msisdns = []string{req.CLI}
+	}
+
+	if len(msisdns) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(BulkStatusResponse{
+			Success: false,
+			Error:   "No SIMs provided",
+		})
+	}
+
+	// Набор проверяется по каталогу каждого аккаунта, чьи SIM входят в запрос
+	if err := services.Catalog.ValidateChangeSetFor(msisdns, req.Changes); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(BulkStatusResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	userCtx := services.Audit.GetUserContext(c)
+	taskReq := services.CreateTaskRequest{
+		Type:      models.TaskTypeChangeSet,
+		Priority:  models.PriorityHigh,
+		Changes:   req.Changes,
+		UserID:    userCtx.UserID,
+		Username:  userCtx.Username,
+		IPAddress: c.IP(),
+		RequestID: req.RequestID,
+	}
+	// Статус и тариф набора видны в колонках задачи, как у одиночных изменений
+	for _, ch := range req.Changes {
+		switch ch.ActionType {
+		case provider.ActionSimStateChange:
+			taskReq.NewStatus = ch.TargetValue
+		case provider.ActionRatePlanChange:
+			taskReq.NewRatePlan = ch.TargetValue
+		}
+	}
+
+	log.Printf("[ChangeSet] Queueing %d actions for %d SIMs", len(req.Changes), len(msisdns))
+
+	if len(msisdns) == 1 {
+		// Старые значения - для записи аудита по одной SIM
+		oldValues := map[string]string{}
+		var sim models.SimCard
+		if err := database.DB.Where("msisdn = ? OR cli = ?", msisdns[0], msisdns[0]).First(&sim).Error; err == nil {
+			oldValues = map[string]string{
+				"status":    sim.Status,
+				"rate_plan": sim.RatePlan,
+				"label_1":   sim.Label1,
+				"label_2":   sim.Label2,
+				"label_3":   sim.Label3,
+			}
+			taskReq.MSISDN = sim.MSISDN
+			taskReq.CLI = sim.CLI
+			taskReq.OldStatus = sim.Status
+			taskReq.OldRatePlan = sim.RatePlan
+		} else {
+			taskReq.MSISDN = msisdns[0]
+		}
+		taskReq.Msisdns = []string{taskReq.MSISDN}
+
+		task, queueErr := services.Queue.CreateTask(taskReq)
+		if queueErr != nil {
+			return c.Status(500).JSON(BulkStatusResponse{
+				Success: false,
+				Error:   "Failed to queue task: " + queueErr.Error(),
+			})
+		}
+
+		services.Audit.LogChangeSetQueued(c, taskReq.Msisdns, req.Changes, oldValues, "")
+
+		return c.JSON(BulkStatusResponse{
+			Result:      "queued",
+			Queued:      true,
+			RequestID:   task.ID,
+			TaskIDs:     []uint{task.ID},
+			Success:     true,
+			TotalItems:  1,
+			QueuedCount: 1,
+		})
+	}
+
+	// Для нескольких SIM - задача на аккаунт, отправляется частями (EYESON_API_BULK_CHUNK_SIZE)
+	batchID, taskIDs, queueErr := services.Queue.CreateBulk(taskReq, msisdns)
+	if queueErr != nil {
+		return c.Status(500).JSON(BulkStatusResponse{
+			Success: false,
+			Error:   "Failed to queue batch: " + queueErr.Error(),
+		})
+	}
+
+	services.Audit.LogChangeSetQueued(c, msisdns, req.Changes, nil, batchID)
+
+	log.Printf("[ChangeSet] Created %d tasks in batch %s", len(taskIDs), batchID)
+
+	return c.JSON(BulkStatusResponse{
+		Result:      "queued",
+		Queued:      true,
+		Success:     true,
+		BatchID:     batchID,
+		TaskIDs:     taskIDs,
+		TotalItems:  len(msisdns),
+		QueuedCount: len(msisdns),
+	})
+}
@@ -11,10 +11,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"eyeson-go-server/internal/config"
+	"eyeson-go-server/internal/eyesont"
 	"eyeson-go-server/internal/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -93,6 +95,25 @@ func probeJSON(client *http.Client, method, url string, payload any) endpointPro
 	return res
 }
 
+// accountRateLimit - лимитер клиента одного аккаунта
+type accountRateLimit struct {
+	AccountID uint                     `json:"account_id"`
+	BaseURL   string                   `json:"base_url"`
+	RateLimit eyesont.RateLimiterStats `json:"rate_limit"`
+}
+
+// rateLimiterStats собирает загрузку лимитеров всех созданных клиентов
+func rateLimiterStats() []accountRateLimit {
+	var stats []accountRateLimit
+	for id, p := range services.Accounts.Active() {
+		if client, ok := p.(*eyesont.Client); ok {
+			stats = append(stats, accountRateLimit{AccountID: id, BaseURL: client.BaseURL, RateLimit: client.RateLimit()})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].AccountID < stats[j].AccountID })
+	return stats
+}
+
 // GetAPIDiagnostics returns provider endpoint reachability/capabilities for troubleshooting.
 // GET /api/v1/api-status/diagnostics (Admin only)
 func GetAPIDiagnostics(c *fiber.Ctx) error {
@@ -107,7 +128,20 @@ func GetAPIDiagnostics(c *fiber.Ctx) error {
 	}
 
 	base := strings.TrimRight(services.ResolveUpstreamBaseURL(cfg, selected), "/")
+
+	// Пробы идут через тот же прокси и TLS, что и клиент провайдера
+	tc := eyesont.TransportConfig{
+		InsecureTLS:    cfg.ApiInsecureTLS,
+		ProxyURL:       cfg.ApiProxyURL,
+		NoProxy:        cfg.ApiNoProxy,
+		CAFile:         cfg.ApiCAFile,
+		ClientCertFile: cfg.ApiClientCertFile,
+		ClientKeyFile:  cfg.ApiClientKeyFile,
+	}
 	client := &http.Client{Timeout: 2 * time.Second}
+	if transport, err := eyesont.NewTransport(tc); err == nil {
+		client.Transport = transport
+	}
 
 	loginURL := base + "/ipa/apis/json/general/login"
 	getSimsURL := base + "/ipa/apis/json/provisioning/getProvisioningData"
@@ -178,7 +212,12 @@ func GetAPIDiagnostics(c *fiber.Ctx) error {
 				"updateSIMStatusChange":  updateLegacyProbe,
 			},
 			"simulator": simCfg,
+			// Действующие прокси и TLS (пароль прокси скрыт); ошибка конфигурации - в transport.error
+			"transport": tc.Describe(),
 		},
-		"server_time": time.Now().Format(time.RFC3339),
+		// Пробы выше идут в обход breaker'а; здесь - его состояние для каждого upstream'а
+		"circuit_breakers": eyesont.Breakers(),
+		"rate_limiters":    rateLimiterStats(),
+		"server_time":      time.Now().Format(time.RFC3339),
 	})
 }
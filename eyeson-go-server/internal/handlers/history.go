@@ -27,7 +27,7 @@ func GetSimHistory(c *fiber.Ctx) error {
 
 	// Fetch pending SyncTasks for this MSISDN
 	var tasks []models.SyncTask
-	database.DB.Where("target_msisdn = ? AND status IN ?", msisdn, []string{"PENDING", "PROCESSING", "QUEUED"}).Find(&tasks)
+	database.DB.Where("target_msisdn = ? AND status IN ?", msisdn, []string{"PENDING", "PROCESSING", "QUEUED", "AWAITING_PROVIDER"}).Find(&tasks)
 
 	// Convert tasks to history-like items to show in UI
 	for _, task := range tasks {
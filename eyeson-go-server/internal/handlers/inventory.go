@@ -0,0 +1,161 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ═══════════════════════════════════════════════════════════
+// SIM SWAP & SPARE SIM INVENTORY HANDLERS
+// ═══════════════════════════════════════════════════════════
+
+type SimSwapRequest struct {
+	MSISDN    string `json:"msisdn"`
+	CLI       string `json:"cli"`
+	ICCID     string `json:"iccid,omitempty"` // Конкретный ICCID со склада (опционально)
+	RequestID string `json:"request_id,omitempty"`
+}
+
+type SimSwapResponse struct {
+	Success   bool   `json:"success"`
+	Queued    bool   `json:"queued"`
+	TaskID    uint   `json:"task_id,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	OldICCID  string `json:"old_iccid,omitempty"`
+	NewICCID  string `json:"new_iccid,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SwapSim - резервирует свободный ICCID со склада и ставит SIM_SWAP в очередь
+// POST /api/v1/sims/swap
+func SwapSim(c *fiber.Ctx) error {
+	var req SimSwapRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(SimSwapResponse{Success: false, Error: "Invalid request body"})
+	}
+	if req.MSISDN == "" {
+		return c.Status(400).JSON(SimSwapResponse{Success: false, Error: "msisdn is required"})
+	}
+
+	var sim models.SimCard
+	if err := database.DB.Where("msisdn = ?", req.MSISDN).First(&sim).Error; err != nil {
+		return c.Status(404).JSON(SimSwapResponse{Success: false, Error: "SIM not found in local DB"})
+	}
+	if req.CLI == "" {
+		req.CLI = sim.CLI
+	}
+
+	// Не допускаем две параллельные замены для одного абонента
+	var active int64
+	database.DB.Model(&models.SyncTaskExtended{}).
+		Where("target_msisdn = ? AND type = ? AND status IN ?", req.MSISDN, models.TaskTypeSimSwap,
+			[]models.TaskStatus{models.TaskStatusPending, models.TaskStatusProcessing, models.TaskStatusAwaitingProvider}).
+		Count(&active)
+	if active > 0 {
+		return c.Status(409).JSON(SimSwapResponse{Success: false, Error: "SIM swap already in progress for this SIM"})
+	}
+
+	userCtx := services.Audit.GetUserContext(c)
+
+	// Резерв ICCID, задача и их связь - одной транзакцией
+	task, spare, err := services.Inventory.QueueSwap(services.CreateTaskRequest{
+		Priority:  models.PriorityHigh,
+		MSISDN:    req.MSISDN,
+		CLI:       req.CLI,
+		OldICCID:  sim.ICCID,
+		UserID:    userCtx.UserID,
+		Username:  userCtx.Username,
+		IPAddress: c.IP(),
+		RequestID: req.RequestID,
+	}, req.ICCID)
+	if err != nil {
+		status := 409
+		switch {
+		case errors.Is(err, services.ErrNoSpareSim):
+			status = 422
+		case errors.Is(err, services.ErrSwapNotQueued):
+			status = 500
+		}
+		return c.Status(status).JSON(SimSwapResponse{Success: false, Error: err.Error()})
+	}
+
+	services.Audit.NewLog(c).
+		Entity(models.EntitySIM, req.MSISDN).
+		Action(models.ActionSimSwap).
+		Change("iccid", sim.ICCID, spare.ICCID).
+		Task(task.ID).
+		Queued().
+		SaveAsync()
+
+	return c.JSON(SimSwapResponse{
+		Success:   true,
+		Queued:    true,
+		TaskID:    task.ID,
+		RequestID: task.RequestID,
+		OldICCID:  sim.ICCID,
+		NewICCID:  spare.ICCID,
+	})
+}
+
+// GetInventory - список ICCID на складе
+// GET /api/v1/inventory/sims?status=AVAILABLE
+func GetInventory(c *fiber.Ctx) error {
+	status := models.SpareSimStatus(strings.ToUpper(c.Query("status", "")))
+	limit := c.QueryInt("limit", 100)
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	spares, err := services.Inventory.List(status, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"items":  spares,
+		"count":  len(spares),
+		"counts": services.Inventory.Counts(),
+	})
+}
+
+// AddInventory - добавить ICCID на склад (admin)
+// POST /api/v1/inventory/sims
+func AddInventory(c *fiber.Ctx) error {
+	var req struct {
+		ICCIDs []string `json:"iccids"`
+		Notes  string   `json:"notes"`
+	}
+	if err := c.BodyParser(&req); err != nil || len(req.ICCIDs) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "iccids is required"})
+	}
+
+	added, err := services.Inventory.AddSpares(req.ICCIDs, req.Notes)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error(), "added": added})
+	}
+
+	services.Audit.NewLog(c).
+		Entity(models.EntitySystem, "spare_sims").
+		Action(models.ActionCreate).
+		Change("count", "", strconv.Itoa(added)).
+		SetDetails("Spare ICCIDs added: " + strings.Join(req.ICCIDs, ", ")).
+		SaveAsync()
+
+	return c.JSON(fiber.Map{
+		"success":   true,
+		"added":     added,
+		"submitted": len(req.ICCIDs),
+	})
+}
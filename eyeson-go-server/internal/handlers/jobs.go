@@ -14,8 +14,9 @@ import (
 	"time"
 
 	"eyeson-go-server/internal/database"
-	"eyeson-go-server/internal/eyesont"
 	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+	"eyeson-go-server/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -28,7 +29,20 @@ func GetJobs(c *fiber.Ctx) error {
 
 	log.Printf("[GetJobs] REQUEST: start=%d, limit=%d, jobId=%d, jobStatus='%s'", start, limit, jobId, jobStatus)
 
-	resp, err := eyesont.Instance.GetJobs(start, limit, jobId, jobStatus)
+	// account_id - jobs другого аккаунта (requestId уникален только внутри аккаунта)
+	p := provider.Active()
+	if accountID := c.QueryInt("account_id", 0); accountID > 0 {
+		accountProvider, err := services.Accounts.Provider(uint(accountID))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		p = accountProvider
+	}
+	if p == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "upstream provider not initialized"})
+	}
+
+	resp, err := p.ListJobs(c.UserContext(), start, limit, jobId, jobStatus)
 	if err != nil {
 		log.Printf("[GetJobs] API ERROR: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
@@ -105,7 +119,7 @@ func GetLocalJob(c *fiber.Ctx) error {
 	// Frontend expects: PENDING, IN_PROGRESS, SUCCESS, FAILED, COMPLETED_WITH_ERROR
 	jobStatus := task.Status
 	errorMsg := ""
-	if jobStatus == "PROCESSING" {
+	if jobStatus == "PROCESSING" || jobStatus == "AWAITING_PROVIDER" {
 		jobStatus = "IN_PROGRESS"
 	}
 	if jobStatus == "COMPLETED" {
@@ -153,6 +167,13 @@ func ExecuteQueueTask(c *fiber.Ctx) error {
 		})
 	}
 
+	// Повтор FAILED bulk-задачи отправляет только не прошедшие части
+	if task.Status == "FAILED" {
+		if _, err := services.Queue.RetryFailedChunks(task.ID); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to reset chunks"})
+		}
+	}
+
 	// Update task to trigger immediate execution
 	task.NextRunAt = time.Now().Add(-1 * time.Second) // Set to past to trigger immediately
 	task.Status = "PENDING"
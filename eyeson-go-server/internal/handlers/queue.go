@@ -90,6 +90,7 @@ func GetMyQueue(c *fiber.Ctx) error {
 	tasks, err := services.Queue.GetUserTasks(userID, []models.TaskStatus{
 		models.TaskStatusPending,
 		models.TaskStatusProcessing,
+		models.TaskStatusAwaitingProvider,
 	})
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
@@ -337,5 +338,11 @@ func RetryTask(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": result.Error.Error()})
 	}
 
-	return c.JSON(fiber.Map{"success": true, "task_id": taskID})
+	// Bulk-задача: повторно отправляются только не прошедшие части
+	retried, err := services.Queue.RetryFailedChunks(uint(taskID))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"success": true, "task_id": taskID, "retried_chunks": retried})
 }
@@ -9,6 +9,7 @@ import (
 	"eyeson-go-server/internal/database"
 	"eyeson-go-server/internal/eyesont"
 	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
 	"eyeson-go-server/internal/services"
 	"fmt"
 	"log"
@@ -44,6 +45,12 @@ func mapModelToApi(m models.SimCard) models.SimData {
 		inSession = "yes"
 	}
 
+	// Синхронизация удержала значение задачи, которое провайдер ещё не отдаёт
+	syncStatus := ""
+	if m.SyncConflict != "" {
+		syncStatus = "Sync Conflict: " + m.SyncConflict
+	}
+
 	return models.SimData{
 		MSISDN:           m.MSISDN,
 		CLI:              m.CLI,
@@ -61,6 +68,7 @@ func mapModelToApi(m models.SimCard) models.SimData {
 		AllocatedMB:      allocated,
 		LastSessionTime:  lastSession,
 		InSession:        inSession,
+		SyncStatus:       syncStatus,
 
 		// Additional fields
 		EffectiveDate:      m.EffectiveDate,
@@ -171,9 +179,13 @@ func GetSims(c *fiber.Ctx) error {
 	pendingTasks := make(map[string]string)
 	if len(msisdns) > 0 {
 		var tasks []models.SyncTask
-		// Check for tasks that are PENDING or PROCESSING
-		database.DB.Where("target_msisdn IN ? AND status IN ?", msisdns, []string{"PENDING", "PROCESSING"}).Find(&tasks)
+		// Check for tasks that are PENDING, PROCESSING or AWAITING_PROVIDER
+		database.DB.Where("target_msisdn IN ? AND status IN ?", msisdns, []string{"PENDING", "PROCESSING", "AWAITING_PROVIDER"}).Find(&tasks)
 		for _, t := range tasks {
+			if t.Status == "AWAITING_PROVIDER" {
+				pendingTasks[t.TargetMSISDN] = "Awaiting Provider Confirmation"
+				continue
+			}
 			// We can map the specific type of task if needed
 			action := "QUEUED"
 			if t.Type == "CHANGE_STATUS" || t.Type == "STATUS_CHANGE" || t.Type == "BULK_CHANGE" {
@@ -182,16 +194,35 @@ func GetSims(c *fiber.Ctx) error {
 				action = "Update Queued"
 			} else if t.Type == "LABEL_UPDATE" {
 				action = "Label Update Queued"
+			} else if t.Type == "RATE_PLAN_CHANGE" {
+				action = "Rate Plan Change Queued"
+			} else if t.Type == "SIM_SWAP" {
+				action = "SIM Swap Queued"
+			} else if t.Type == "CHANGE_SET" {
+				action = "Change Set Queued"
 			}
 			pendingTasks[t.TargetMSISDN] = action
 		}
+
+		// SIM внутри частей bulk-задач
+		for msisdn, t := range services.Queue.ActiveChunkedTasks(msisdns) {
+			if t.Status == models.TaskStatusAwaitingProvider {
+				pendingTasks[msisdn] = "Awaiting Provider Confirmation"
+			} else if t.Type == models.TaskTypeRatePlanChange {
+				pendingTasks[msisdn] = "Rate Plan Change Queued"
+			} else if t.Type == models.TaskTypeChangeSet {
+				pendingTasks[msisdn] = "Change Set Queued"
+			} else {
+				pendingTasks[msisdn] = "Status Change Queued"
+			}
+		}
 	}
 
 	// Map to API Response
 	var data []models.SimData
 	for _, s := range sims {
 		apiSim := mapModelToApi(s)
-		if status, exists := pendingTasks[s.MSISDN]; exists {
+		if status, exists := pendingTasks[s.MSISDN]; exists && apiSim.SyncStatus == "" {
 			apiSim.SyncStatus = status
 		}
 		data = append(data, apiSim)
@@ -252,6 +283,14 @@ func UpdateSim(c *fiber.Ctx) error {
 		normalizedField = "label_3"
 	}
 
+	// Проверяем поле и значение по каталогу параметров аккаунта, которому принадлежит SIM
+	if err := services.Catalog.Validate(services.Accounts.AccountForMSISDN(req.Msisdn), services.LabelCatalogField(normalizedField), req.Value); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(UpdateSimResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
 	// Get CLI from DB if not provided
 	cli := req.CLI
 	if cli == "" {
@@ -375,6 +414,22 @@ func BulkChangeStatus(c *fiber.Ctx) error {
 		})
 	}
 
+	// Статус проверяется по каталогу каждого аккаунта, чьи SIM входят в запрос
+	targets := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.MSISDN != "" {
+			targets = append(targets, item.MSISDN)
+		} else {
+			targets = append(targets, item.CLI)
+		}
+	}
+	if err := services.Catalog.ValidateFor(targets, services.CatalogFieldStatus, req.Status); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(BulkStatusResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
 	userCtx := services.Audit.GetUserContext(c)
 	batchID := uuid.New().String()
 
@@ -418,23 +473,23 @@ func BulkChangeStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	// Для нескольких SIM создаём batch задач
-	taskRequests := make([]services.CreateTaskRequest, 0, len(items))
-	for _, item := range items {
-		taskRequests = append(taskRequests, services.CreateTaskRequest{
-			Type:      models.TaskTypeStatusChange,
-			Priority:  models.PriorityHigh,
-			MSISDN:    item.MSISDN,
-			CLI:       item.CLI,
-			OldStatus: item.OldStatus,
-			NewStatus: req.Status,
-			UserID:    userCtx.UserID,
-			Username:  userCtx.Username,
-			IPAddress: c.IP(),
-		})
+	// Для нескольких SIM - bulk-задача на аккаунт, отправляется частями (EYESON_API_BULK_CHUNK_SIZE)
+	msisdns := make([]string, len(items))
+	for i, item := range items {
+		msisdns[i] = item.MSISDN
+		if msisdns[i] == "" {
+			msisdns[i] = item.CLI
+		}
 	}
 
-	_, taskIDs, queueErr := services.Queue.CreateBatch(taskRequests)
+	batchID, taskIDs, queueErr := services.Queue.CreateBulk(services.CreateTaskRequest{
+		Type:      models.TaskTypeBulkChange,
+		Priority:  models.PriorityHigh,
+		NewStatus: req.Status,
+		UserID:    userCtx.UserID,
+		Username:  userCtx.Username,
+		IPAddress: c.IP(),
+	}, msisdns)
 	if queueErr != nil {
 		return c.Status(500).JSON(BulkStatusResponse{
 			Success: false,
@@ -443,10 +498,6 @@ func BulkChangeStatus(c *fiber.Ctx) error {
 	}
 
 	// Логируем batch в аудит
-	msisdns := make([]string, len(items))
-	for i, item := range items {
-		msisdns[i] = item.MSISDN
-	}
 	services.Audit.LogBulkStatusChange(c, len(items), req.Status, msisdns)
 
 	log.Printf("[BulkChangeStatus] Created %d tasks in batch %s", len(taskIDs), batchID)
@@ -498,6 +549,13 @@ func ChangeStatus(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := services.Catalog.Validate(services.Accounts.AccountForMSISDN(req.CLI), services.CatalogFieldStatus, req.NewStatus); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(ChangeStatusResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
 	// Генерируем request_id если не передан
 	if req.RequestID == "" {
 		req.RequestID = uuid.New().String()
@@ -537,13 +595,180 @@ func ChangeStatus(c *fiber.Ctx) error {
 	})
 }
 
+// ChangeRatePlan - смена тарифного плана (одна SIM или массово)
+type RatePlanRequest struct {
+	RatePlan    string              `json:"rate_plan"`
+	OldRatePlan string              `json:"old_rate_plan,omitempty"`
+	MSISDN      string              `json:"msisdn,omitempty"`
+	CLI         string              `json:"cli,omitempty"`
+	Items       []map[string]string `json:"items,omitempty"`
+	Msisdns     []string            `json:"msisdns,omitempty"`
+	RequestID   string              `json:"request_id,omitempty"`
+}
+
+// ChangeRatePlan ставит в очередь RATE_PLAN_CHANGE для одной или нескольких SIM
+// POST /api/v1/sims/rate-plan
+func ChangeRatePlan(c *fiber.Ctx) error {
+	var req RatePlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(BulkStatusResponse{
+			Success: false,
+			Error:   "Invalid request",
+		})
+	}
+
+	if req.RatePlan == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(BulkStatusResponse{
+			Success: false,
+			Error:   "rate_plan is required",
+		})
+	}
+
+	type simItem struct {
+		MSISDN      string
+		CLI         string
+		OldRatePlan string
+	}
+	var items []simItem
+
+	if len(req.Items) > 0 {
+		for _, item := range req.Items {
+			items = append(items, simItem{
+				MSISDN:      item["msisdn"],
+				CLI:         item["cli"],
+				OldRatePlan: item["old_rate_plan"],
+			})
+		}
+	} else if len(req.Msisdns) > 0 {
+		for _, msisdn := range req.Msisdns {
+			items = append(items, simItem{MSISDN: msisdn})
+		}
+	} else if req.MSISDN != "" || req.CLI != "" {
+		items = append(items, simItem{MSISDN: req.MSISDN, CLI: req.CLI, OldRatePlan: req.OldRatePlan})
+	}
+
+	if len(items) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(BulkStatusResponse{
+			Success: false,
+			Error:   "No SIMs provided",
+		})
+	}
+
+	// Тарифы у аккаунтов разные - план проверяется по каталогу каждого аккаунта запроса
+	targets := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.MSISDN != "" {
+			targets = append(targets, item.MSISDN)
+		} else {
+			targets = append(targets, item.CLI)
+		}
+	}
+	if err := services.Catalog.ValidateFor(targets, services.CatalogFieldRatePlan, req.RatePlan); err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(BulkStatusResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	// Подставляем текущий план из локальной БД, если клиент его не передал
+	for i := range items {
+		if items[i].OldRatePlan != "" || items[i].MSISDN == "" {
+			continue
+		}
+		var sim models.SimCard
+		if err := database.DB.Select("cli, rate_plan").Where("msisdn = ?", items[i].MSISDN).First(&sim).Error; err == nil {
+			items[i].OldRatePlan = sim.RatePlan
+			if items[i].CLI == "" {
+				items[i].CLI = sim.CLI
+			}
+		}
+	}
+
+	userCtx := services.Audit.GetUserContext(c)
+	msisdns := make([]string, len(items))
+	for i, item := range items {
+		msisdns[i] = item.MSISDN
+		if msisdns[i] == "" {
+			msisdns[i] = item.CLI
+		}
+	}
+
+	log.Printf("[ChangeRatePlan] Queueing %d items for rate plan change to '%s'", len(items), req.RatePlan)
+
+	if len(items) == 1 {
+		item := items[0]
+		task, queueErr := services.Queue.CreateTask(services.CreateTaskRequest{
+			Type:        models.TaskTypeRatePlanChange,
+			Priority:    models.PriorityHigh,
+			MSISDN:      item.MSISDN,
+			CLI:         item.CLI,
+			OldRatePlan: item.OldRatePlan,
+			NewRatePlan: req.RatePlan,
+			UserID:      userCtx.UserID,
+			Username:    userCtx.Username,
+			IPAddress:   c.IP(),
+			RequestID:   req.RequestID,
+		})
+		if queueErr != nil {
+			return c.Status(500).JSON(BulkStatusResponse{
+				Success: false,
+				Error:   "Failed to queue task: " + queueErr.Error(),
+			})
+		}
+
+		services.Audit.LogRatePlanChangeQueued(c, msisdns, item.OldRatePlan, req.RatePlan)
+
+		return c.JSON(BulkStatusResponse{
+			Result:      "queued",
+			Queued:      true,
+			RequestID:   task.ID,
+			TaskIDs:     []uint{task.ID},
+			Success:     true,
+			TotalItems:  1,
+			QueuedCount: 1,
+		})
+	}
+
+	// Для нескольких SIM - bulk-задача на аккаунт, отправляется частями (EYESON_API_BULK_CHUNK_SIZE)
+	batchID, taskIDs, queueErr := services.Queue.CreateBulk(services.CreateTaskRequest{
+		Type:        models.TaskTypeRatePlanChange,
+		Priority:    models.PriorityHigh,
+		NewRatePlan: req.RatePlan,
+		UserID:      userCtx.UserID,
+		Username:    userCtx.Username,
+		IPAddress:   c.IP(),
+		RequestID:   req.RequestID,
+	}, msisdns)
+	if queueErr != nil {
+		return c.Status(500).JSON(BulkStatusResponse{
+			Success: false,
+			Error:   "Failed to queue batch: " + queueErr.Error(),
+		})
+	}
+
+	services.Audit.LogRatePlanChangeQueued(c, msisdns, "", req.RatePlan)
+
+	log.Printf("[ChangeRatePlan] Created %d tasks in batch %s", len(taskIDs), batchID)
+
+	return c.JSON(BulkStatusResponse{
+		Result:      "queued",
+		Queued:      true,
+		Success:     true,
+		BatchID:     batchID,
+		TaskIDs:     taskIDs,
+		TotalItems:  len(items),
+		QueuedCount: len(items),
+	})
+}
+
 func GetAPIStatus(c *fiber.Ctx) error {
 	status := "offline"
 	message := "Disconnected from EyesOnT API"
 
 	// Real-time check
-	if eyesont.Instance != nil {
-		if eyesont.Instance.CheckConnection() {
+	active := provider.Active()
+	if active != nil {
+		if active.Health(c.UserContext()) == nil {
 			status = "online"
 			message = "Connected to EyesOnT API"
 		}
@@ -558,14 +783,46 @@ func GetAPIStatus(c *fiber.Ctx) error {
 		}
 	}
 
+	details := map[string]string{
+		"message": message,
+	}
+	if active != nil {
+		details["provider"] = active.Name()
+	}
+
+	// Состояние сессии EyesOnT (login/relogin/expiry)
+	if client, ok := active.(*eyesont.Client); ok {
+		details["api_url"] = client.BaseURL
+		details["api_user"] = client.Username
+
+		session := client.Session()
+		details["session"] = "inactive"
+		if session.LoggedIn {
+			details["session"] = "active"
+			details["session_login_time"] = session.LoginTime.Format(time.RFC3339)
+			details["session_age"] = session.Age
+			details["session_expires_in"] = session.ExpiresIn
+		}
+		details["session_relogins"] = strconv.Itoa(session.Relogins)
+
+		rateLimit := client.RateLimit()
+		details["rate_limit_utilization"] = strconv.FormatFloat(rateLimit.Utilization, 'f', 3, 64)
+
+		breaker := client.Breaker().Snapshot()
+		details["circuit_breaker"] = string(breaker.State)
+		if breaker.RetryAt != nil {
+			details["circuit_breaker_retry_at"] = breaker.RetryAt.Format(time.RFC3339)
+		}
+		if session.LastAuthFailure != "" {
+			details["session_last_auth_failure"] = session.LastAuthFailure
+			details["session_last_auth_failure_at"] = session.LastAuthFailAt.Format(time.RFC3339)
+		}
+	}
+
 	return c.JSON(models.APIStatusResponse{
 		EyesonAPI: models.APIConnectionInfo{
-			Status: status,
-			Details: map[string]string{
-				"api_url":  eyesont.Instance.BaseURL,
-				"api_user": eyesont.Instance.Username,
-				"message":  message,
-			},
+			Status:  status,
+			Details: details,
 		},
 		GoBackend:   models.APIConnectionInfo{Status: "online"},
 		Database:    models.APIConnectionInfo{Status: dbStatus},
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -15,6 +16,7 @@ import (
 	"eyeson-go-server/internal/database"
 	"eyeson-go-server/internal/eyesont"
 	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
 	"eyeson-go-server/internal/services"
 	"eyeson-go-server/internal/syncer"
 
@@ -29,6 +31,7 @@ type manualSyncState struct {
 	LastError      string    `json:"last_error,omitempty"`
 	LastProcessed  int       `json:"last_processed"`
 	LastDurationMs int64     `json:"last_duration_ms"`
+	Cancelled      bool      `json:"cancelled"`
 	Source         string    `json:"source"`
 	BaseURL        string    `json:"base_url"`
 
@@ -41,17 +44,44 @@ type manualSyncState struct {
 	SimulatorLastError  string `json:"simulator_last_error,omitempty"`
 	SimulatorLastPushed int    `json:"simulator_last_pushed"`
 	SimulatorDurationMs int64  `json:"simulator_duration_ms"`
+
+	// Последний цикл синхронизации (плановой или ручной) и последний полный обход без ошибок
+	LastRun      *models.SyncRun `json:"last_run,omitempty"`
+	LastComplete *models.SyncRun `json:"last_complete,omitempty"`
+
+	// Расписание плановой синхронизации и ближайший запуск
+	Schedule *syncer.ScheduleStatus `json:"schedule,omitempty"`
 }
 
 var (
-	manualSyncMu    sync.Mutex
-	manualSyncStats = manualSyncState{Source: "pelephone"}
+	manualSyncMu     sync.Mutex
+	manualSyncStats  = manualSyncState{Source: "pelephone"}
+	manualSyncCancel context.CancelFunc
+
+	// manualSyncBaseCtx - родительский контекст ручной синхронизации (отменяется при shutdown)
+	manualSyncBaseCtx = context.Background()
 )
 
+// manualSyncLogoutTimeout - сколько ждать logout после (в т.ч. прерванной) синхронизации
+const manualSyncLogoutTimeout = 5 * time.Second
+
+// SetBaseContext задаёт контекст сервиса: при его отмене ручная синхронизация прерывается
+func SetBaseContext(ctx context.Context) {
+	manualSyncMu.Lock()
+	manualSyncBaseCtx = ctx
+	manualSyncMu.Unlock()
+}
+
 func GetManualSyncStatus(c *fiber.Ctx) error {
 	manualSyncMu.Lock()
 	st := manualSyncStats
 	manualSyncMu.Unlock()
+	st.LastRun = syncer.LastRun(database.DB)
+	st.LastComplete = syncer.LastComplete(database.DB)
+	if scheduledSyncer != nil {
+		schedule := scheduledSyncer.ScheduleStatus()
+		st.Schedule = &schedule
+	}
 	return c.JSON(st)
 }
 
@@ -182,6 +212,7 @@ func TriggerManualFullSync(c *fiber.Ctx) error {
 	manualSyncStats.LastError = ""
 	manualSyncStats.LastProcessed = 0
 	manualSyncStats.LastDurationMs = 0
+	manualSyncStats.Cancelled = false
 	manualSyncStats.ClearLocalDB = clearLocalDB
 	manualSyncStats.DeletedBeforeSync = 0
 	manualSyncStats.SimulatorRequested = pushSimulator
@@ -189,9 +220,12 @@ func TriggerManualFullSync(c *fiber.Ctx) error {
 	manualSyncStats.SimulatorLastPushed = 0
 	manualSyncStats.SimulatorLastPushOK = false
 	manualSyncStats.SimulatorDurationMs = 0
+	ctx, cancel := context.WithCancel(manualSyncBaseCtx)
+	manualSyncCancel = cancel
 	manualSyncMu.Unlock()
 
 	go func() {
+		defer cancel()
 		start := time.Now()
 		processed := 0
 		deletedCount := 0
@@ -221,25 +255,19 @@ func TriggerManualFullSync(c *fiber.Ctx) error {
 			if err == nil {
 				// Always use hardcoded Pelephone URL for manual sync (source of truth)
 				baseURL = services.DefaultPelephoneBaseURL
-				client := eyesont.NewClient(baseURL, cfg.ApiUsername, cfg.ApiPassword, cfg.ApiDelayMs, cfg.ApiInsecureTLS)
-				if loginErr := client.Login(); loginErr != nil {
-					err = loginErr
-				} else {
-					s := &syncer.Syncer{DB: database.DB, Client: client}
-					processed, err = s.SyncFull()
-
-					if err == nil && pushSimulator {
-						simBase = cfg.SimulatorBaseUrl
-						pStart := time.Now()
-						count, pErr := pushLocalDBToSimulator(simBase)
-						simPushDur = time.Since(pStart).Milliseconds()
-						simPushCount = count
-						if pErr != nil {
-							simPushErr = pErr.Error()
-							err = fmt.Errorf("simulator push failed: %w", pErr)
-						} else {
-							simPushOK = true
-						}
+				processed, err = syncAllAccounts(ctx)
+
+				if err == nil && pushSimulator {
+					simBase = cfg.SimulatorBaseUrl
+					pStart := time.Now()
+					count, pErr := pushLocalDBToSimulator(simBase)
+					simPushDur = time.Since(pStart).Milliseconds()
+					simPushCount = count
+					if pErr != nil {
+						simPushErr = pErr.Error()
+						err = fmt.Errorf("simulator push failed: %w", pErr)
+					} else {
+						simPushOK = true
 					}
 				}
 			}
@@ -247,6 +275,7 @@ func TriggerManualFullSync(c *fiber.Ctx) error {
 
 		dur := time.Since(start).Milliseconds()
 		manualSyncMu.Lock()
+		manualSyncCancel = nil
 		manualSyncStats.Running = false
 		manualSyncStats.FinishedAt = time.Now()
 		manualSyncStats.LastDurationMs = dur
@@ -258,7 +287,12 @@ func TriggerManualFullSync(c *fiber.Ctx) error {
 		manualSyncStats.SimulatorLastError = simPushErr
 		manualSyncStats.SimulatorLastPushOK = simPushOK
 		manualSyncStats.SimulatorDurationMs = simPushDur
-		if err != nil {
+		if err != nil && eyesont.IsCanceled(err) {
+			log.Printf("[ManualSync] Cancelled after %d records", processed)
+			manualSyncStats.LastSuccess = false
+			manualSyncStats.Cancelled = true
+			manualSyncStats.LastError = "sync cancelled"
+		} else if err != nil {
 			manualSyncStats.LastSuccess = false
 			manualSyncStats.LastError = err.Error()
 		} else {
@@ -272,3 +306,100 @@ func TriggerManualFullSync(c *fiber.Ctx) error {
 		"started": true,
 	})
 }
+
+// syncAllAccounts синхронизирует все включённые аккаунты, каждый - отдельной сессией.
+// Аккаунты Pelephone без собственного BaseURL идут в DefaultPelephoneBaseURL.
+func syncAllAccounts(ctx context.Context) (int, error) {
+	accounts, err := services.Accounts.EnabledAccounts()
+	if err != nil {
+		return 0, err
+	}
+	if len(accounts) == 0 {
+		return 0, fmt.Errorf("no enabled upstream accounts")
+	}
+
+	total := 0
+	var lastErr error
+	for _, acc := range accounts {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+
+		baseURL := ""
+		if acc.BaseURL == "" && acc.Provider == eyesont.ProviderName {
+			baseURL = services.DefaultPelephoneBaseURL
+		}
+		upstream := acc.BaseURL
+		if baseURL != "" {
+			upstream = baseURL
+		}
+		p, err := services.Accounts.NewProvider(acc, baseURL)
+		if err != nil {
+			log.Printf("[ManualSync] Account #%d %q: %v", acc.ID, acc.Name, err)
+			syncer.RecordFailedRun(database.DB, models.SyncTriggerManual, acc.ID, upstream, err)
+			lastErr = err
+			continue
+		}
+
+		session, hasSession := p.(provider.Session)
+		if hasSession {
+			if err := session.Login(ctx); err != nil {
+				if eyesont.IsCanceled(err) {
+					return total, err
+				}
+				log.Printf("[ManualSync] Account #%d %q: login failed: %v", acc.ID, acc.Name, err)
+				syncer.RecordFailedRun(database.DB, models.SyncTriggerManual, acc.ID, upstream, err)
+				lastErr = err
+				continue
+			}
+		}
+
+		s := &syncer.Syncer{DB: database.DB, Provider: p, AccountID: acc.ID}
+		processed, err := s.SyncFull(ctx)
+		total += processed
+		if err != nil {
+			lastErr = err
+		}
+
+		// Сессию закрываем и после отмены - отдельным коротким контекстом
+		if hasSession {
+			logoutCtx, cancelLogout := context.WithTimeout(context.Background(), manualSyncLogoutTimeout)
+			if logoutErr := session.Logout(logoutCtx); logoutErr != nil {
+				log.Printf("[ManualSync] Account #%d: logout failed: %v", acc.ID, logoutErr)
+			}
+			cancelLogout()
+		}
+
+		if eyesont.IsCanceled(err) {
+			return total, err
+		}
+	}
+	return total, lastErr
+}
+
+// CancelManualSync прерывает запущенную ручную синхронизацию (вместе с текущим запросом к провайдеру).
+// Уже сохранённые страницы остаются в локальной БД.
+func CancelManualSync(c *fiber.Ctx) error {
+	manualSyncMu.Lock()
+	cancel := manualSyncCancel
+	running := manualSyncStats.Running
+	manualSyncMu.Unlock()
+
+	// Тот же маршрут прерывает и фоновую сверку (POST /sync/drift)
+	driftCancelled := cancelDrift()
+	if !running || cancel == nil {
+		if driftCancelled {
+			return c.JSON(fiber.Map{"cancelling": true})
+		}
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "no sync running",
+		})
+	}
+
+	log.Println("[ManualSync] Cancellation requested")
+	cancel()
+
+	return c.JSON(fiber.Map{
+		"cancelling": true,
+	})
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/services"
+	"eyeson-go-server/internal/syncer"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ═══════════════════════════════════════════════════════════
+// ОТЧЁТ О РАСХОЖДЕНИЯХ С ПРОВАЙДЕРОМ (ADMIN)
+// ═══════════════════════════════════════════════════════════
+
+// driftState - состояние фоновой сверки (GET /api/v1/sync/drift/status)
+type driftState struct {
+	Running    bool      `json:"running"`
+	AccountID  uint      `json:"account_id,omitempty"` // 0 - все аккаунты
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Cancelled  bool      `json:"cancelled"`
+	LastError  string    `json:"last_error,omitempty"`
+	HasReport  bool      `json:"has_report"`
+}
+
+var (
+	driftMu     sync.Mutex // Одна сверка за раз: полный обход нагружает upstream
+	driftStats  driftState
+	driftCancel context.CancelFunc
+	lastDrift   *syncer.DriftReport
+)
+
+// RunSyncDrift запускает в фоне сверку локальной БД с провайдером без записи.
+// Полный обход идёт долго и под лимитом запросов, поэтому запрос сразу возвращает 202:
+// ход - GET /api/v1/sync/drift/status, отчёт - GET /api/v1/sync/drift, отмена -
+// POST /api/v1/sync/cancel. account_id - сверить один аккаунт.
+// POST /api/v1/sync/drift
+func RunSyncDrift(c *fiber.Ctx) error {
+	s := &syncer.Syncer{DB: database.DB}
+	if accountID := c.QueryInt("account_id", 0); accountID > 0 {
+		if _, err := services.Accounts.Get(uint(accountID)); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		p, err := services.Accounts.Provider(uint(accountID))
+		if err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+		}
+		s.Provider = p
+		s.AccountID = uint(accountID)
+	}
+
+	driftMu.Lock()
+	if driftStats.Running {
+		st := driftStats
+		driftMu.Unlock()
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "drift check already running", "status": st})
+	}
+	manualSyncMu.Lock()
+	ctx, cancel := context.WithCancel(manualSyncBaseCtx)
+	manualSyncMu.Unlock()
+	driftCancel = cancel
+	driftStats = driftState{Running: true, AccountID: s.AccountID, StartedAt: time.Now(), HasReport: lastDrift != nil}
+	st := driftStats
+	driftMu.Unlock()
+
+	userID := services.Audit.GetUserContext(c).UserID
+	services.Audit.NewLog(c).
+		Entity(models.EntitySystem, "sync_drift").
+		Action(models.ActionSync).
+		SetDetails(fmt.Sprintf("Drift check started (account_id=%d, 0 - all accounts)", s.AccountID)).
+		SaveAsync()
+
+	go func() {
+		defer cancel()
+		report, err := s.Drift(ctx)
+
+		driftMu.Lock()
+		driftCancel = nil
+		driftStats.Running = false
+		driftStats.FinishedAt = time.Now()
+		switch {
+		case err != nil && eyesont.IsCanceled(err):
+			log.Println("[Drift] Drift check cancelled")
+			driftStats.Cancelled = true
+			driftStats.LastError = "drift check cancelled"
+		case err != nil:
+			log.Printf("[Drift] Drift check failed: %v", err)
+			driftStats.LastError = err.Error()
+		default:
+			lastDrift = report
+			driftStats.HasReport = true
+		}
+		driftMu.Unlock()
+
+		if err == nil {
+			services.Audit.NewWorkerLog().
+				SetUserID(userID).
+				Entity(models.EntitySystem, "sync_drift").
+				Action(models.ActionSync).
+				SetDetails(fmt.Sprintf("Drift check: %d upstream SIMs, %d missing locally, %d missing upstream, %d field mismatches",
+					report.UpstreamSIMs, len(report.MissingLocally), len(report.MissingUpstream), len(report.Mismatches))).
+				Save()
+		}
+	}()
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"started": true, "status": st})
+}
+
+// GetSyncDriftStatus возвращает ход последней сверки
+// GET /api/v1/sync/drift/status
+func GetSyncDriftStatus(c *fiber.Ctx) error {
+	driftMu.Lock()
+	st := driftStats
+	driftMu.Unlock()
+	return c.JSON(st)
+}
+
+// cancelDrift прерывает идущую сверку; false - сверка не идёт
+func cancelDrift() bool {
+	driftMu.Lock()
+	cancel := driftCancel
+	driftMu.Unlock()
+	if cancel == nil {
+		return false
+	}
+	log.Println("[Drift] Cancellation requested")
+	cancel()
+	return true
+}
+
+// GetSyncDrift возвращает последний отчёт о расхождениях (CSV при ?format=csv)
+// GET /api/v1/sync/drift
+func GetSyncDrift(c *fiber.Ctx) error {
+	driftMu.Lock()
+	report := lastDrift
+	driftMu.Unlock()
+
+	if report == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "no drift report yet, run POST /api/v1/sync/drift"})
+	}
+	return sendDriftReport(c, report)
+}
+
+func sendDriftReport(c *fiber.Ctx, report *syncer.DriftReport) error {
+	if !strings.EqualFold(c.Query("format"), "csv") {
+		return c.JSON(report)
+	}
+
+	rows := len(report.MissingLocally) + len(report.MissingUpstream) + len(report.Mismatches)
+	services.Audit.LogExport(c, "sync_drift", rows)
+
+	c.Set("Content-Type", "text/csv; charset=utf-8")
+	c.Set("Content-Disposition", "attachment; filename=sync_drift_"+report.GeneratedAt.Format("2006-01-02")+".csv")
+
+	// BOM для UTF-8
+	var csv strings.Builder
+	csv.WriteString("\xEF\xBB\xBF")
+	// Header: одна строка на расхождение, тип - в колонке Kind
+	csv.WriteString("Kind,MSISDN,Account ID,Field,Policy,Local Value,Upstream Value,Note\n")
+
+	for _, sim := range report.MissingLocally {
+		fmt.Fprintf(&csv, "MISSING_LOCALLY,%s,%d,STATUS,,,%s,%s\n",
+			escapeCSV(sim.MSISDN), sim.AccountID, escapeCSV(sim.Status), escapeCSV(sim.Note))
+	}
+	for _, sim := range report.MissingUpstream {
+		fmt.Fprintf(&csv, "MISSING_UPSTREAM,%s,%d,STATUS,,%s,,%s\n",
+			escapeCSV(sim.MSISDN), sim.AccountID, escapeCSV(sim.Status), escapeCSV(sim.Note))
+	}
+	for _, m := range report.Mismatches {
+		fmt.Fprintf(&csv, "MISMATCH,%s,%d,%s,%s,%s,%s,\n",
+			escapeCSV(m.MSISDN), m.AccountID, m.Field, m.Policy, escapeCSV(m.Local), escapeCSV(m.Upstream))
+	}
+	if !report.Complete {
+		fmt.Fprintf(&csv, "INCOMPLETE,,,,,,,%s\n", escapeCSV("upstream walk did not finish: "+report.Error))
+	}
+	fmt.Fprintf(&csv, "GENERATED,,,,,,,%s\n", report.GeneratedAt.Format(time.RFC3339))
+
+	return c.SendString(csv.String())
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/syncer"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ═══════════════════════════════════════════════════════════
+// SYNC RUNS (ИСТОРИЯ ЦИКЛОВ СИНХРОНИЗАЦИИ)
+// ═══════════════════════════════════════════════════════════
+
+// SyncRunFilter - фильтры истории циклов синхронизации
+type SyncRunFilter struct {
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+	Trigger   string `query:"trigger"` // scheduled | manual | task
+	Mode      string `query:"mode"`    // full | incremental | scoped
+	Status    string `query:"status"`
+	AccountID uint   `query:"account_id"`
+	Complete  string `query:"complete"` // true | false
+	DateFrom  string `query:"date_from"`
+	DateTo    string `query:"date_to"`
+}
+
+// GetSyncRuns - история циклов синхронизации с метриками, новые первыми.
+// last_complete - последний полный обход без ошибок: когда локальная БД совпадала с upstream.
+// GET /api/v1/sync/runs
+func GetSyncRuns(c *fiber.Ctx) error {
+	filter := SyncRunFilter{
+		Page:  1,
+		Limit: 50,
+	}
+	if err := c.QueryParser(&filter); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid query parameters"})
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+
+	query := database.DB.Model(&models.SyncRun{})
+
+	// ─── ПРИМЕНЯЕМ ФИЛЬТРЫ ─────────────────────────────────
+	if filter.Trigger != "" {
+		query = query.Where("sync_trigger = ?", strings.ToLower(filter.Trigger))
+	}
+	if filter.Mode != "" {
+		query = query.Where("mode = ?", strings.ToLower(filter.Mode))
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", strings.ToUpper(filter.Status))
+	}
+	if filter.AccountID > 0 {
+		query = query.Where("account_id = ?", filter.AccountID)
+	}
+	switch strings.ToLower(filter.Complete) {
+	case "true", "1":
+		query = query.Where("complete = ?", true)
+	case "false", "0":
+		query = query.Where("complete = ?", false)
+	}
+	if filter.DateFrom != "" {
+		if t, err := time.Parse("2006-01-02", filter.DateFrom); err == nil {
+			query = query.Where("started_at >= ?", t)
+		}
+	}
+	if filter.DateTo != "" {
+		if t, err := time.Parse("2006-01-02", filter.DateTo); err == nil {
+			query = query.Where("started_at < ?", t.Add(24*time.Hour))
+		}
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var runs []models.SyncRun
+	if err := query.Order("started_at DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&runs).Error; err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"data":          runs,
+		"total":         total,
+		"page":          filter.Page,
+		"limit":         filter.Limit,
+		"total_pages":   (total + int64(filter.Limit) - 1) / int64(filter.Limit),
+		"last_complete": syncer.LastComplete(database.DB),
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/services"
+	"eyeson-go-server/internal/syncer"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ═══════════════════════════════════════════════════════════
+// РАСПИСАНИЕ ПЛАНОВОЙ СИНХРОНИЗАЦИИ (ADMIN)
+// ═══════════════════════════════════════════════════════════
+
+// scheduledSyncer - фоновый syncer сервиса (задаётся в main)
+var scheduledSyncer *syncer.Syncer
+
+// SetScheduledSyncer подключает фоновый syncer к admin API и GET /sync/status
+func SetScheduledSyncer(s *syncer.Syncer) {
+	scheduledSyncer = s
+}
+
+// PauseSync ставит плановую синхронизацию на паузу (сохраняется между перезапусками)
+// POST /api/v1/sync/pause
+func PauseSync(c *fiber.Ctx) error {
+	return setSyncPaused(c, true)
+}
+
+// ResumeSync возобновляет плановую синхронизацию
+// POST /api/v1/sync/resume
+func ResumeSync(c *fiber.Ctx) error {
+	return setSyncPaused(c, false)
+}
+
+func setSyncPaused(c *fiber.Ctx, paused bool) error {
+	if scheduledSyncer == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "syncer not running"})
+	}
+
+	wasPaused := scheduledSyncer.IsPaused()
+	var err error
+	if paused {
+		err = scheduledSyncer.Pause()
+	} else {
+		err = scheduledSyncer.Resume()
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	action := "resumed"
+	if paused {
+		action = "paused"
+	}
+	services.Audit.NewLog(c).
+		Entity(models.EntitySystem, "sync_schedule").
+		Action(models.ActionUpdate).
+		Change("sync.paused", strconv.FormatBool(wasPaused), strconv.FormatBool(paused)).
+		SetDetails("Scheduled sync " + action).
+		SaveAsync()
+
+	return c.JSON(fiber.Map{
+		"success":  true,
+		"schedule": scheduledSyncer.ScheduleStatus(),
+	})
+}
+
+// UpdateSyncSchedule задаёт cron-выражение, часовой пояс и тихие часы плановой синхронизации
+// PUT /api/v1/sync/schedule
+func UpdateSyncSchedule(c *fiber.Ctx) error {
+	if scheduledSyncer == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "syncer not running"})
+	}
+
+	var req syncer.Schedule
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	old := scheduledSyncer.ScheduleStatus()
+	status, err := scheduledSyncer.Reschedule(req)
+	if err != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	services.Audit.NewLog(c).
+		Entity(models.EntitySystem, "sync_schedule").
+		Action(models.ActionUpdate).
+		Change("sync.cron", old.Cron, status.Cron).
+		Change("sync.timezone", old.Timezone, status.Timezone).
+		SetDetails(fmt.Sprintf("Sync schedule set to %q with %d quiet window(s)", status.Cron, len(status.QuietHours))).
+		SaveAsync()
+
+	return c.JSON(fiber.Map{
+		"success":  true,
+		"schedule": status,
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/services"
+	"eyeson-go-server/internal/syncer"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ═══════════════════════════════════════════════════════════
+// ЧАСТИЧНАЯ СИНХРОНИЗАЦИЯ (ADMIN)
+// ═══════════════════════════════════════════════════════════
+
+// TriggerScopedSync синхронизирует выборку абонентов: список MSISDN, CUSTOMER_NUMBER или
+// произвольный фильтр getProvisioningData. account_id - только один аккаунт.
+// POST /api/v1/sync/scoped
+func TriggerScopedSync(c *fiber.Ctx) error {
+	var scope syncer.Scope
+	if err := c.BodyParser(&scope); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if err := scope.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	s := &syncer.Syncer{DB: database.DB, Trigger: models.SyncTriggerManual}
+	if accountID := c.QueryInt("account_id", 0); accountID > 0 {
+		if _, err := services.Accounts.Get(uint(accountID)); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		p, err := services.Accounts.Provider(uint(accountID))
+		if err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+		}
+		s.Provider = p
+		s.AccountID = uint(accountID)
+	}
+
+	manualSyncMu.Lock()
+	ctx := manualSyncBaseCtx
+	manualSyncMu.Unlock()
+
+	result, err := s.SyncScoped(ctx, scope)
+	if result == nil {
+		status := fiber.StatusBadGateway
+		switch {
+		case errors.Is(err, syncer.ErrInvalidScope):
+			status = fiber.StatusBadRequest
+		case eyesont.IsCanceled(err):
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	run := result.Run
+	if run.Created > 0 || run.Updated > 0 {
+		InvalidateStatsCache()
+	}
+
+	services.Audit.NewLog(c).
+		Entity(models.EntitySystem, "sync_scoped").
+		Action(models.ActionSync).
+		SetDetails(fmt.Sprintf("Scoped sync (%s): %d processed, %d created, %d updated, %d not found",
+			run.Reason, run.Processed, run.Created, run.Updated, len(result.NotFound))).
+		SaveAsync()
+
+	// Цикл выполнен, но с ошибками страниц - итог всё равно отдаём, статус - в run.status
+	return c.JSON(result)
+}
@@ -6,7 +6,12 @@
 package handlers
 
 import (
+	"fmt"
+
 	"eyeson-go-server/internal/config"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+	"eyeson-go-server/internal/reactive"
 	"eyeson-go-server/internal/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -39,11 +44,14 @@ func GetUpstream(c *fiber.Ctx) error {
 				"base_url": cfg.SimulatorBaseUrl,
 			},
 		},
+		"provider":         cfg.Provider,
+		"providers":        provider.Names(),
 		"restart_required": false,
 	})
 }
 
-// SetUpstream persists upstream selection. Applies after server restart.
+// SetUpstream switches the upstream at runtime: waits for the in-flight task, rebuilds
+// the account clients on the new base URL, logs in again and emits UPSTREAM_CHANGED.
 // PUT /api/v1/upstream (Admin only)
 func SetUpstream(c *fiber.Ctx) error {
 	var req upstreamSetRequest
@@ -56,11 +64,25 @@ func SetUpstream(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid selected value (expected pelephone|simulator)"})
 	}
 
-	if err := services.SetUpstreamSelected(selected); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to save upstream selection: " + err.Error()})
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Could not load config: " + err.Error()})
+	}
+
+	result, err := services.SwitchUpstream(c.UserContext(), cfg, selected)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to switch upstream: " + err.Error(), "switch": result})
 	}
 
-	cfg, _ := config.LoadConfig()
+	services.Audit.NewLog(c).
+		Entity(models.EntitySystem, "upstream").
+		Action(models.ActionUpdate).
+		Change("upstream.selected", string(result.From), string(result.To)).
+		SetDetails(fmt.Sprintf("Upstream switched to %s (%s), login ok=%t", result.To, result.BaseURL, result.LoggedIn)).
+		SaveAsync()
+
+	eventBroadcaster.Emit(reactive.EventUpstreamChanged, result, "")
+
 	return c.JSON(fiber.Map{
 		"selected": string(selected),
 		"options": fiber.Map{
@@ -71,6 +93,9 @@ func SetUpstream(c *fiber.Ctx) error {
 				"base_url": cfg.SimulatorBaseUrl,
 			},
 		},
-		"restart_required": true,
+		"provider":         cfg.Provider,
+		"providers":        provider.Names(),
+		"restart_required": false,
+		"switch":           result,
 	})
 }
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+	"eyeson-go-server/internal/services"
+)
+
+// ═══════════════════════════════════════════════════════════
+// CHANGE SET (НЕСКОЛЬКО ДЕЙСТВИЙ В ОДНОМ ЗАПРОСЕ)
+// ═══════════════════════════════════════════════════════════
+
+type ChangeSetPayload struct {
+	Msisdns []string          `json:"msisdns"`
+	Changes []provider.Change `json:"changes"`
+}
+
+// handleChangeSet отправляет набор действий одним updateProvisioningData: провайдер применяет
+// их вместе и возвращает один requestId, который подтверждает ProviderReconciler
+func (w *Worker) handleChangeSet(ctx context.Context, prov provider.Provider, task *models.SyncTaskExtended) (string, error) {
+	var p ChangeSetPayload
+	if err := json.Unmarshal([]byte(task.Payload), &p); err != nil {
+		return "", &eyesont.APIError{Kind: eyesont.ErrKindValidation, Op: string(task.Type), Message: "failed to parse payload", Err: err}
+	}
+	if len(p.Msisdns) == 0 {
+		return "No MSISDNs", nil
+	}
+
+	// Каталог мог обновиться, пока задача ждала в очереди
+	if err := services.Catalog.ValidateChangeSet(task.AccountID, p.Changes); err != nil {
+		return "", err
+	}
+
+	updater, ok := prov.(provider.ChangeSetUpdater)
+	if !ok {
+		return "", eyesont.NewValidationError(string(task.Type), fmt.Sprintf("provider %s does not support change sets", prov.Name()))
+	}
+	send := func(ctx context.Context, part []string) (*models.BulkUpdateResponse, error) {
+		return updater.UpdateProvisioningSet(ctx, part, p.Changes)
+	}
+	actions := changeSetActions(p.Changes)
+
+	if len(p.Msisdns) > 1 {
+		sent, err := w.submitChunks(ctx, prov, task, p.Msisdns, send)
+		w.applyChangeSet(task, sent, p.Changes)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Change set [%s] submitted for %d SIMs in chunks", actions, len(sent)), nil
+	}
+
+	resp, err := send(ctx, p.Msisdns)
+	if err != nil {
+		return "", err
+	}
+	if resp != nil {
+		task.ProviderRequestID = resp.RequestId
+	}
+
+	w.applyChangeSet(task, p.Msisdns, p.Changes)
+	return fmt.Sprintf("Change set [%s] requested for %d SIMs (requestId=%d)", actions, len(p.Msisdns), task.ProviderRequestID), nil
+}
+
+// applyChangeSet обновляет локальную БД по действиям набора для абонентов, чей запрос принят
+// провайдером. Историю по каждому действию пишет recordConfirmed; отложенная синхронизация - одна на весь набор.
+func (w *Worker) applyChangeSet(task *models.SyncTaskExtended, msisdns []string, changes []provider.Change) {
+	if len(msisdns) == 0 {
+		return
+	}
+
+	fields := make([]string, 0, len(changes))
+	for _, ch := range changes {
+		if field := provider.ChangeField(ch.ActionType); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	w.rememberOldValues(task, msisdns, fields...)
+
+	for _, ch := range changes {
+		field := provider.ChangeField(ch.ActionType)
+		// Future план - локальный rate_plan не трогаем, его подтянет отложенная синхронизация
+		if field == "" || ch.ActionType == provider.ActionRatePlanChange {
+			continue
+		}
+		column := strings.ReplaceAll(field, "_", "") // label_1 -> label1
+		w.DB.Model(&models.SimCard{}).Where("msisdn IN ?", msisdns).Update(column, ch.TargetValue)
+	}
+
+	w.syncLater(task, msisdns)
+}
+
+// changeSetAction - действие истории для поля SIM, которое меняет действие набора
+func changeSetAction(field string) string {
+	switch field {
+	case "status":
+		return "STATUS_CHANGE"
+	case "rate_plan":
+		return "RATE_PLAN_CHANGE"
+	}
+	return "UPDATE_FIELD"
+}
+
+// changeSetActions - список действий набора для сообщений и логов
+func changeSetActions(changes []provider.Change) string {
+	names := make([]string, len(changes))
+	for i, ch := range changes {
+		names[i] = ch.ActionType
+	}
+	return strings.Join(names, ", ")
+}
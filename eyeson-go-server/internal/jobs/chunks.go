@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+	"eyeson-go-server/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// ═══════════════════════════════════════════════════════════
+// CHUNKED BULK SUBMISSION
+// ═══════════════════════════════════════════════════════════
+
+// chunkSender отправляет одну часть абонентов провайдеру
+type chunkSender func(ctx context.Context, part []string) (*models.BulkUpdateResponse, error)
+
+// sendAction - chunkSender для одного действия updateProvisioningData
+func sendAction(prov provider.Provider, actionType, targetValue string) chunkSender {
+	return func(ctx context.Context, part []string) (*models.BulkUpdateResponse, error) {
+		return prov.UpdateProvisioning(ctx, part, actionType, targetValue)
+	}
+}
+
+// submitChunks отправляет части bulk-задачи, которые провайдер ещё не принял, и возвращает
+// абонентов, отправленных в этом проходе. Принятые части (SUBMITTED/COMPLETED) не отправляются
+// повторно. Если retryable-ошибка оставила части в PENDING, возвращается ошибка - задача уйдёт
+// на повтор; на последней попытке такие части закрываются как FAILED, а уже принятые
+// подтверждает ProviderReconciler. send отправляет одну часть (одно действие или набор действий).
+func (w *Worker) submitChunks(ctx context.Context, prov provider.Provider, task *models.SyncTaskExtended, msisdns []string, send chunkSender) ([]string, error) {
+	chunks, err := services.Queue.EnsureChunks(task, msisdns, provider.MaxSubscribers(prov))
+	if err != nil {
+		return nil, fmt.Errorf("load chunks: %w", err)
+	}
+
+	var sent []string
+	var retryErr, fatalErr error
+	for i := range chunks {
+		ch := &chunks[i]
+		if ch.Status != models.ChunkStatusPending {
+			continue
+		}
+
+		part := ch.MSISDNList()
+		resp, err := send(ctx, part)
+		if err != nil {
+			// Отмена и открытый breaker - прерываем проход, оставшиеся части ждут
+			if eyesont.IsCanceled(err) || eyesont.IsCircuitOpen(err) {
+				return sent, err
+			}
+			updates := map[string]interface{}{
+				"attempt":    ch.Attempt + 1,
+				"last_error": err.Error(),
+			}
+			if eyesont.IsRetryable(err) {
+				retryErr = err
+			} else {
+				// Провайдер отклонил часть - повтор не поможет
+				now := time.Now()
+				ch.Status = models.ChunkStatusFailed
+				updates["status"] = ch.Status
+				updates["failed"] = ch.Size
+				updates["completed_at"] = &now
+				fatalErr = err
+			}
+			log.Printf("[JobWorker] Task ID=%d chunk %d/%d (%d SIMs) FAILED: %v", task.ID, ch.ChunkIndex+1, len(chunks), ch.Size, err)
+			w.DB.Model(ch).Updates(updates)
+			continue
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"attempt":      ch.Attempt + 1,
+			"last_error":   "",
+			"submitted_at": &now,
+		}
+		if resp != nil && resp.RequestId > 0 {
+			ch.Status = models.ChunkStatusSubmitted
+			ch.ProviderRequestID = resp.RequestId
+			updates["provider_request_id"] = resp.RequestId
+		} else {
+			// Без requestId подтверждать нечего (legacy endpoint симулятора)
+			ch.Status = models.ChunkStatusCompleted
+			updates["succeeded"] = ch.Size
+			updates["completed_at"] = &now
+		}
+		updates["status"] = ch.Status
+		w.DB.Model(ch).Updates(updates)
+		sent = append(sent, part...)
+		log.Printf("[JobWorker] Task ID=%d chunk %d/%d (%d SIMs) accepted (requestId=%d)", task.ID, ch.ChunkIndex+1, len(chunks), ch.Size, ch.ProviderRequestID)
+	}
+
+	// Итог по всем частям, включая принятые в прошлых попытках
+	pending, accepted := 0, 0
+	for _, ch := range chunks {
+		switch ch.Status {
+		case models.ChunkStatusPending:
+			pending++
+		case models.ChunkStatusSubmitted:
+			accepted++
+			if task.ProviderRequestID == 0 {
+				task.ProviderRequestID = ch.ProviderRequestID
+			}
+		case models.ChunkStatusCompleted:
+			accepted++
+		}
+	}
+
+	if pending > 0 {
+		lastAttempt := task.Attempt+1 >= task.MaxAttempts
+		if !lastAttempt || accepted == 0 {
+			return sent, retryErr
+		}
+		now := time.Now()
+		w.DB.Model(&models.TaskChunk{}).
+			Where("task_id = ? AND status = ?", task.ID, models.ChunkStatusPending).
+			Updates(map[string]interface{}{
+				"status":       models.ChunkStatusFailed,
+				"failed":       gorm.Expr("size"),
+				"last_error":   "attempts exhausted: " + retryErr.Error(),
+				"completed_at": &now,
+			})
+		log.Printf("[JobWorker] Task ID=%d: %d chunks failed after %d attempts, %d accepted", task.ID, pending, task.MaxAttempts, accepted)
+	}
+	if accepted == 0 {
+		if fatalErr == nil {
+			fatalErr = eyesont.NewValidationError(string(task.Type), "all chunks were rejected by the provider")
+		}
+		return sent, fatalErr
+	}
+	return sent, nil
+}
@@ -0,0 +1,620 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/handlers"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+	"eyeson-go-server/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// ═══════════════════════════════════════════════════════════
+// PROVIDER JOB RECONCILER
+// ═══════════════════════════════════════════════════════════
+
+// ProviderReconciler подтверждает задачи AWAITING_PROVIDER по getProvisioningJobList.
+// updateProvisioningData возвращает SUCCESS сразу после приёма запроса, а реальный
+// результат по каждому абоненту появляется в actions соответствующего job.
+type ProviderReconciler struct {
+	DB     *gorm.DB
+	Worker *Worker
+
+	Interval time.Duration // Период опроса GetJobs
+	Timeout  time.Duration // Сколько ждать подтверждения, прежде чем закрыть задачу как UNCONFIRMED
+	Limit    int           // Сколько задач проверять за один проход
+}
+
+func NewProviderReconciler(w *Worker) *ProviderReconciler {
+	return &ProviderReconciler{
+		DB:       w.DB,
+		Worker:   w,
+		Interval: 20 * time.Second,
+		Timeout:  30 * time.Minute,
+		Limit:    50,
+	}
+}
+
+func (r *ProviderReconciler) Start(ctx context.Context) {
+	log.Println("[Reconciler] Starting provider job reconciler...")
+
+	go func() {
+		ticker := time.NewTicker(r.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Println("[Reconciler] Stopped")
+				return
+			case <-ticker.C:
+			}
+			if r.Worker.IsPaused() {
+				continue
+			}
+			// Переключение upstream прерывает проход; задачи проверятся на следующем тике
+			passCtx, release := services.UpstreamGate.Enter(ctx, false)
+			r.ReconcileOnce(passCtx)
+			release()
+		}
+	}()
+}
+
+// ReconcileOnce проверяет все задачи, ожидающие подтверждения провайдера
+func (r *ProviderReconciler) ReconcileOnce(ctx context.Context) {
+	ctx = eyesont.WithBulk(ctx)
+
+	var tasks []models.SyncTaskExtended
+	if err := r.DB.Where("status = ? AND provider_request_id > 0", models.TaskStatusAwaitingProvider).
+		Order("provider_checked_at ASC, id ASC").
+		Limit(r.Limit).
+		Find(&tasks).Error; err != nil {
+		log.Printf("[Reconciler] Error fetching tasks: %v", err)
+		return
+	}
+
+	for _, task := range tasks {
+		if ctx.Err() != nil {
+			return
+		}
+		r.reconcileTask(ctx, task)
+	}
+}
+
+// providerOutcome - итог по одному абоненту внутри job
+type providerOutcome struct {
+	NeID   string
+	Status string
+	Error  string
+}
+
+func (r *ProviderReconciler) reconcileTask(ctx context.Context, task models.SyncTaskExtended) {
+	now := time.Now()
+
+	// requestId уникален только в пределах аккаунта - спрашиваем тот же аккаунт
+	prov, err := r.Worker.providerFor(&task)
+	if err != nil {
+		log.Printf("[Reconciler] Task ID=%d: %v", task.ID, err)
+		r.checkTimeout(task, now, "upstream account unavailable")
+		return
+	}
+	if !provider.IsAvailable(prov) {
+		return // breaker открыт - проверим, когда upstream оживёт
+	}
+
+	// Bulk-задача: у каждой части свой job
+	if chunks, err := services.Queue.TaskChunks(task.ID); err == nil && len(chunks) > 0 {
+		r.reconcileChunks(ctx, prov, task, chunks, now)
+		return
+	}
+
+	resp, err := prov.ListJobs(ctx, 0, 1, task.ProviderRequestID, "")
+	if eyesont.IsCanceled(err) {
+		return
+	}
+	if err != nil {
+		log.Printf("[Reconciler] Task ID=%d: GetJobs(jobId=%d) failed: %v", task.ID, task.ProviderRequestID, err)
+		r.DB.Model(&task).Update("provider_checked_at", &now)
+		return
+	}
+
+	var job *models.Job
+	for i := range resp.Jobs {
+		if resp.Jobs[i].JobId == task.ProviderRequestID {
+			job = &resp.Jobs[i]
+			break
+		}
+	}
+
+	if job == nil {
+		r.checkTimeout(task, now, "job not found in getProvisioningJobList")
+		return
+	}
+
+	jobStatus := job.JobStatus
+	if jobStatus == "" {
+		jobStatus = job.Status
+	}
+
+	outcomes, done := evaluateJob(*job, jobStatus)
+	r.DB.Model(&task).Updates(map[string]interface{}{
+		"provider_status":     jobStatus,
+		"provider_checked_at": &now,
+	})
+
+	if !done {
+		r.checkTimeout(task, now, fmt.Sprintf("job status %s", jobStatus))
+		return
+	}
+
+	var failed []providerOutcome
+	for _, o := range outcomes {
+		if classifyProviderStatus(o.Status) == providerFailed {
+			failed = append(failed, o)
+		}
+	}
+
+	if len(failed) == 0 {
+		result := fmt.Sprintf("Provider confirmed job #%d: %d/%d succeeded", job.JobId, len(outcomes), len(outcomes))
+		r.complete(task, result)
+		return
+	}
+
+	r.fail(ctx, prov, task, job.JobId, failed, len(outcomes))
+}
+
+// checkTimeout закрывает задачу как UNCONFIRMED, если провайдер так и не подтвердил job.
+// Провайдер уже ответил SUCCESS на сам запрос, поэтому это не ошибка (так работало до reconciler'а).
+func (r *ProviderReconciler) checkTimeout(task models.SyncTaskExtended, now time.Time, reason string) {
+	since := task.UpdatedAt
+	if task.SubmittedAt != nil {
+		since = *task.SubmittedAt
+	}
+	if now.Sub(since) < r.Timeout {
+		r.DB.Model(&task).Update("provider_checked_at", &now)
+		return
+	}
+
+	log.Printf("[Reconciler] Task ID=%d: no provider confirmation after %s (%s)", task.ID, r.Timeout, reason)
+	r.complete(task, fmt.Sprintf("UNCONFIRMED: provider job #%d not confirmed within %s (%s)", task.ProviderRequestID, r.Timeout, reason))
+}
+
+func (r *ProviderReconciler) complete(task models.SyncTaskExtended, result string) {
+	if !r.Worker.finishTask(task, "COMPLETED", result, 0) {
+		return
+	}
+	log.Printf("[Reconciler] Task ID=%d COMPLETED: %s", task.ID, result)
+
+	if task.Type == models.TaskTypeSimSwap {
+		if err := services.Inventory.CompleteSwap(task.TargetMSISDN, task.OldICCID, task.NewICCID, task.ID); err != nil {
+			log.Printf("[Reconciler] SIM_SWAP %s: inventory update failed: %v", task.TargetMSISDN, err)
+		}
+	}
+
+	r.Worker.recordConfirmed(task)
+	services.Audit.LogQueueCompleted(task.ID, task.TargetMSISDN, result, 0)
+	handlers.InvalidateStatsCache()
+}
+
+func (r *ProviderReconciler) fail(ctx context.Context, prov provider.Provider, task models.SyncTaskExtended, jobID int, failed []providerOutcome, total int) {
+	msisdns, parts := r.recordRejections(task, failed)
+
+	result := fmt.Sprintf("Provider job #%d: %d/%d failed: %s", jobID, len(failed), total, strings.Join(parts, "; "))
+	if !r.Worker.finishTask(task, "FAILED", result, 0) {
+		return
+	}
+	log.Printf("[Reconciler] Task ID=%d FAILED: %s", task.ID, result)
+
+	r.Worker.recordConfirmed(task) // Абоненты без отказа изменение получили
+	services.Audit.LogQueueFailed(task.ID, task.TargetMSISDN, result, 0)
+	r.Worker.releaseTaskResources(task)
+
+	// Локальная БД обновлена оптимистично - возвращаем фактические значения провайдера.
+	// Синхронно: ctx прохода отменяется release() сразу после ReconcileOnce.
+	r.Worker.syncSimsFromAPI(ctx, prov, task.AccountID, msisdns)
+}
+
+// recordRejections пишет в историю SIM отказы провайдера и возвращает их MSISDN и описания
+func (r *ProviderReconciler) recordRejections(task models.SyncTaskExtended, failed []providerOutcome) ([]string, []string) {
+	parts := make([]string, 0, len(failed))
+	msisdns := make([]string, 0, len(failed))
+	for _, o := range failed {
+		desc := o.Error
+		if desc == "" {
+			desc = o.Status
+		}
+		msisdn := r.resolveMSISDN(task, o.NeID)
+		msisdns = append(msisdns, msisdn)
+		parts = append(parts, fmt.Sprintf("%s: %s", msisdn, desc))
+
+		// Отказ по конкретному абоненту виден в истории SIM, даже если остальные прошли
+		models.NewSimHistory(0, msisdn, "PROVIDER_JOB").ByTask(task.ID).
+			Event("PROVIDER_REJECTED", string(task.Type), "", desc).Save(r.DB)
+	}
+	return msisdns, parts
+}
+
+// ─── ИСТОРИЯ ПОДТВЕРЖДЁННЫХ ИЗМЕНЕНИЙ ──────────────────────
+
+// recordConfirmed пишет историю изменений задачи, когда она получила итог: до подтверждения
+// провайдера это лишь запрос. Абоненты с отказом (PROVIDER_REJECTED) и части bulk-задачи,
+// которые провайдер не принял, в историю не попадают.
+func (w *Worker) recordConfirmed(task models.SyncTaskExtended) {
+	changes := taskChanges(task)
+	if len(changes) == 0 {
+		return
+	}
+	actions := make([]string, 0, len(changes))
+	for _, ch := range changes {
+		actions = append(actions, ch.Action)
+	}
+
+	// После повтора части задача завершается снова - уже записанные изменения пропускаем
+	var recorded []models.SimHistory
+	w.DB.Select("msisdn", "field").Where("task_id = ? AND action IN ?", task.ID, actions).Find(&recorded)
+	done := make(map[string]bool, len(recorded))
+	for _, h := range recorded {
+		done[h.MSISDN+"|"+h.Field] = true
+	}
+
+	msisdns := w.confirmedMSISDNs(task)
+	if len(msisdns) == 0 {
+		return
+	}
+
+	var sims []models.SimCard
+	w.DB.Where("msisdn IN ? OR cli IN ?", msisdns, msisdns).Find(&sims)
+	simMap := make(map[string]models.SimCard, len(sims))
+	for _, sim := range sims {
+		simMap[sim.MSISDN] = sim
+		if sim.CLI != "" {
+			simMap[sim.CLI] = sim
+		}
+	}
+	olds := task.OldFieldValues()
+
+	for _, msisdn := range msisdns {
+		sim := simMap[msisdn]
+		h := models.NewSimHistory(sim.ID, msisdn, "WORKER").ByTask(task.ID)
+		for _, ch := range changes {
+			if done[msisdn+"|"+historyField(ch.Field)] {
+				continue
+			}
+			// Значение до отправки; поля, которые worker не трогает локально (future тариф), ещё прежние
+			old, ok := olds[msisdn][ch.Field]
+			if !ok {
+				old = ch.Old
+			}
+			if !ok && old == "" {
+				old = sim.FieldValue(ch.Field)
+			}
+			if old == "" && ch.Action != "UPDATE_FIELD" {
+				old = "Unknown"
+			}
+			h.Change(ch.Action, ch.Field, old, ch.Value)
+		}
+		h.Save(w.DB)
+	}
+}
+
+// confirmedChange - изменение поля, которое задача запросила у провайдера
+type confirmedChange struct {
+	Action string // RATE_PLAN_CHANGE, SIM_SWAP, STATUS_CHANGE, UPDATE_FIELD
+	Field  string
+	Old    string // Прежнее значение из самой задачи, если оно там есть
+	Value  string
+}
+
+// taskChanges - изменения, которые попадут в историю после подтверждения провайдером
+func taskChanges(task models.SyncTaskExtended) []confirmedChange {
+	switch task.Type {
+	case models.TaskTypeRatePlanChange:
+		var p RatePlanPayload
+		_ = json.Unmarshal([]byte(task.Payload), &p)
+		if p.RatePlan == "" {
+			p.RatePlan = task.NewRatePlan
+		}
+		ch := confirmedChange{Action: string(task.Type), Field: "rate_plan", Value: p.RatePlan}
+		if len(taskMsisdns(task)) == 1 {
+			ch.Old = task.OldRatePlan
+		}
+		return []confirmedChange{ch}
+	case models.TaskTypeSimSwap:
+		return []confirmedChange{{Action: string(task.Type), Field: "iccid", Old: task.OldICCID, Value: task.NewICCID}}
+	case "CHANGE_STATUS", "STATUS_CHANGE", "BULK_CHANGE":
+		var p BulkStatusPayload
+		_ = json.Unmarshal([]byte(task.Payload), &p)
+		return []confirmedChange{{Action: "STATUS_CHANGE", Field: "status", Value: p.Status}}
+	case "UPDATE_SIM", "LABEL_UPDATE":
+		_, field, value, err := updateSimTarget(task)
+		if err != nil {
+			return nil
+		}
+		return []confirmedChange{{Action: "UPDATE_FIELD", Field: field, Value: value}}
+	case models.TaskTypeChangeSet:
+		var p ChangeSetPayload
+		_ = json.Unmarshal([]byte(task.Payload), &p)
+		changes := make([]confirmedChange, 0, len(p.Changes))
+		for _, ch := range p.Changes {
+			if field := provider.ChangeField(ch.ActionType); field != "" {
+				changes = append(changes, confirmedChange{Action: changeSetAction(field), Field: field, Value: ch.TargetValue})
+			}
+		}
+		return changes
+	}
+	return nil
+}
+
+// historyField - имя поля так, как его сохраняет SimHistoryBuilder.Change
+func historyField(field string) string {
+	if f, ok := models.LookupSimField(field); ok {
+		return f.Key
+	}
+	return field
+}
+
+// confirmedMSISDNs - абоненты задачи, которых провайдер принял: у bulk-задачи только
+// завершённые части, без абонентов с отказом после последней отправки их части
+func (w *Worker) confirmedMSISDNs(task models.SyncTaskExtended) []string {
+	rejections := services.Queue.Rejections(task.ID)
+
+	var confirmed []string
+	if chunks, err := services.Queue.TaskChunks(task.ID); err == nil && len(chunks) > 0 {
+		for _, ch := range chunks {
+			if ch.Status != models.ChunkStatusCompleted {
+				continue
+			}
+			for _, msisdn := range ch.MSISDNList() {
+				if !services.RejectedSince(rejections, msisdn, ch.SubmittedAt) {
+					confirmed = append(confirmed, msisdn)
+				}
+			}
+		}
+		return confirmed
+	}
+
+	for _, msisdn := range taskMsisdns(task) {
+		if msisdn != "" && !services.RejectedSince(rejections, msisdn, task.SubmittedAt) {
+			confirmed = append(confirmed, msisdn)
+		}
+	}
+	return confirmed
+}
+
+// ─── ЧАСТИ BULK-ЗАДАЧ ──────────────────────────────────────
+
+// reconcileChunks подтверждает job каждой отправленной части. Задача завершается, когда
+// у всех частей есть итог: COMPLETED, если отказов нет, иначе FAILED со сводкой по частям.
+func (r *ProviderReconciler) reconcileChunks(ctx context.Context, prov provider.Provider, task models.SyncTaskExtended, chunks []models.TaskChunk, now time.Time) {
+	var rejected []string
+	open := 0
+	for i := range chunks {
+		ch := &chunks[i]
+		if ch.Status != models.ChunkStatusSubmitted {
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if !r.reconcileChunk(ctx, prov, task, ch, now, &rejected) {
+			open++
+		}
+	}
+
+	r.DB.Model(&task).Update("provider_checked_at", &now)
+	if len(rejected) > 0 {
+		// Локальная БД обновлена оптимистично - возвращаем фактические значения провайдера
+		// (синхронно, пока ctx прохода не отменён)
+		r.Worker.syncSimsFromAPI(ctx, prov, task.AccountID, rejected)
+	}
+	if open > 0 {
+		return
+	}
+
+	total, failed, failedChunks := 0, 0, 0
+	jobIDs := make([]string, 0, len(chunks))
+	for _, ch := range chunks {
+		total += ch.Size
+		failed += ch.Failed
+		if ch.Failed > 0 {
+			failedChunks++
+		}
+		if ch.ProviderRequestID > 0 {
+			jobIDs = append(jobIDs, fmt.Sprintf("#%d", ch.ProviderRequestID))
+		}
+	}
+
+	if failed == 0 {
+		r.complete(task, fmt.Sprintf("Provider confirmed %d chunks (jobs %s): %d/%d succeeded",
+			len(chunks), strings.Join(jobIDs, ","), total, total))
+		return
+	}
+
+	result := fmt.Sprintf("Provider jobs %s: %d/%d SIMs failed in %d/%d chunks",
+		strings.Join(jobIDs, ","), failed, total, failedChunks, len(chunks))
+	if !r.Worker.finishTask(task, "FAILED", result, 0) {
+		return
+	}
+	log.Printf("[Reconciler] Task ID=%d FAILED: %s", task.ID, result)
+	r.Worker.recordConfirmed(task)
+	services.Audit.LogQueueFailed(task.ID, task.TargetMSISDN, result, 0)
+	r.Worker.releaseTaskResources(task)
+}
+
+// reconcileChunk проверяет job одной части; true - часть получила итог
+func (r *ProviderReconciler) reconcileChunk(ctx context.Context, prov provider.Provider, task models.SyncTaskExtended, ch *models.TaskChunk, now time.Time, rejected *[]string) bool {
+	resp, err := prov.ListJobs(ctx, 0, 1, ch.ProviderRequestID, "")
+	if err != nil {
+		if !eyesont.IsCanceled(err) {
+			log.Printf("[Reconciler] Task ID=%d chunk %d: GetJobs(jobId=%d) failed: %v", task.ID, ch.ChunkIndex, ch.ProviderRequestID, err)
+		}
+		return false
+	}
+
+	var job *models.Job
+	for i := range resp.Jobs {
+		if resp.Jobs[i].JobId == ch.ProviderRequestID {
+			job = &resp.Jobs[i]
+			break
+		}
+	}
+
+	var outcomes []providerOutcome
+	done := false
+	jobStatus := ""
+	if job != nil {
+		jobStatus = job.JobStatus
+		if jobStatus == "" {
+			jobStatus = job.Status
+		}
+		outcomes, done = evaluateJob(*job, jobStatus)
+	}
+
+	updates := map[string]interface{}{"provider_status": jobStatus}
+	if !done {
+		// Как и для обычных задач: без подтверждения за Timeout часть считается принятой
+		if ch.SubmittedAt == nil || now.Sub(*ch.SubmittedAt) < r.Timeout {
+			r.DB.Model(ch).Updates(updates)
+			return false
+		}
+		log.Printf("[Reconciler] Task ID=%d chunk %d: job #%d not confirmed within %s", task.ID, ch.ChunkIndex, ch.ProviderRequestID, r.Timeout)
+		updates["provider_status"] = "UNCONFIRMED"
+		outcomes = nil
+	}
+
+	var failed []providerOutcome
+	for _, o := range outcomes {
+		if classifyProviderStatus(o.Status) == providerFailed {
+			failed = append(failed, o)
+		}
+	}
+
+	// Отказ всего job без разбивки по абонентам - не прошла вся часть
+	if len(failed) > 0 && len(job.Actions) == 0 {
+		desc := failed[0].Error
+		failed = failed[:0]
+		for _, msisdn := range ch.MSISDNList() {
+			failed = append(failed, providerOutcome{NeID: msisdn, Status: jobStatus, Error: desc})
+		}
+	}
+
+	ch.Status = models.ChunkStatusCompleted
+	ch.Failed = failedSubscribers(failed)
+	if len(failed) > 0 {
+		msisdns, _ := r.recordRejections(task, failed)
+		*rejected = append(*rejected, msisdns...)
+		if ch.Failed >= ch.Size {
+			ch.Status = models.ChunkStatusFailed
+			ch.Failed = ch.Size
+		}
+	}
+	ch.Succeeded = ch.Size - ch.Failed
+	updates["status"] = ch.Status
+	updates["failed"] = ch.Failed
+	updates["succeeded"] = ch.Succeeded
+	updates["completed_at"] = &now
+	r.DB.Model(ch).Updates(updates)
+	return true
+}
+
+// failedSubscribers - сколько абонентов получили отказ. В CHANGE_SET у абонента по action на
+// каждое действие набора, поэтому считаем разные neId, а не actions.
+func failedSubscribers(failed []providerOutcome) int {
+	seen := make(map[string]bool, len(failed))
+	for _, o := range failed {
+		seen[eyesont.NormalizeMSISDN(o.NeID)] = true
+	}
+	return len(seen)
+}
+
+// resolveMSISDN сопоставляет neId из job с MSISDN задачи
+func (r *ProviderReconciler) resolveMSISDN(task models.SyncTaskExtended, neID string) string {
+	normalized := eyesont.NormalizeMSISDN(neID)
+	for _, msisdn := range taskMsisdns(task) {
+		if eyesont.NormalizeMSISDN(msisdn) == normalized {
+			return msisdn
+		}
+	}
+	if neID == "" {
+		return task.TargetMSISDN
+	}
+	return neID
+}
+
+// taskMsisdns возвращает всех абонентов задачи (bulk payload или одиночный target)
+func taskMsisdns(task models.SyncTaskExtended) []string {
+	var p struct {
+		Msisdns []string `json:"msisdns"`
+		Msisdn  string   `json:"msisdn"`
+	}
+	_ = json.Unmarshal([]byte(task.Payload), &p)
+
+	if len(p.Msisdns) > 0 {
+		return p.Msisdns
+	}
+	if p.Msisdn != "" {
+		return []string{p.Msisdn}
+	}
+	if task.TargetMSISDN != "" {
+		return []string{task.TargetMSISDN}
+	}
+	return []string{task.TargetCLI}
+}
+
+const (
+	providerSucceeded = "SUCCEEDED"
+	providerFailed    = "FAILED"
+	providerPending   = "PENDING"
+)
+
+// classifyProviderStatus сводит статусы job/action провайдера к трём исходам
+func classifyProviderStatus(status string) string {
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case "SUCCESS", "SUCCEEDED", "COMPLETED", "DONE":
+		return providerSucceeded
+	case "FAILED", "REJECTED", "INVALID_REQ", "MISSING_ENTITY", "ERROR", "CANCELLED", "COMPLETED_WITH_ERROR":
+		return providerFailed
+	default:
+		return providerPending
+	}
+}
+
+// evaluateJob возвращает исходы по абонентам и признак того, что job завершён
+func evaluateJob(job models.Job, jobStatus string) ([]providerOutcome, bool) {
+	if len(job.Actions) == 0 {
+		// Без actions судим по статусу всего job
+		switch classifyProviderStatus(jobStatus) {
+		case providerSucceeded:
+			return []providerOutcome{{Status: jobStatus}}, true
+		case providerFailed:
+			return []providerOutcome{{Status: jobStatus, Error: "job " + jobStatus}}, true
+		default:
+			return nil, false
+		}
+	}
+
+	outcomes := make([]providerOutcome, 0, len(job.Actions))
+	for _, a := range job.Actions {
+		if classifyProviderStatus(a.Status) == providerPending {
+			return nil, false
+		}
+		desc := a.ErrorDesc
+		if desc == "" {
+			desc = a.ErrorMsg
+		}
+		outcomes = append(outcomes, providerOutcome{NeID: a.NeId, Status: a.Status, Error: desc})
+	}
+	return outcomes, true
+}
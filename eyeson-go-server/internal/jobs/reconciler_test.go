@@ -0,0 +1,157 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package jobs
+
+import (
+	"reflect"
+	"testing"
+
+	"eyeson-go-server/internal/models"
+)
+
+func TestClassifyProviderStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{"SUCCESS", providerSucceeded},
+		{"succeeded", providerSucceeded},
+		{" Completed ", providerSucceeded},
+		{"DONE", providerSucceeded},
+		{"FAILED", providerFailed},
+		{"REJECTED", providerFailed},
+		{"INVALID_REQ", providerFailed},
+		{"MISSING_ENTITY", providerFailed},
+		{"COMPLETED_WITH_ERROR", providerFailed},
+		{"cancelled", providerFailed},
+		{"PENDING", providerPending},
+		{"IN_PROGRESS", providerPending},
+		{"", providerPending},
+	}
+	for _, tt := range tests {
+		if got := classifyProviderStatus(tt.status); got != tt.want {
+			t.Errorf("classifyProviderStatus(%q) = %s, want %s", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateJob(t *testing.T) {
+	tests := []struct {
+		name      string
+		job       models.Job
+		status    string
+		want      []providerOutcome
+		wantFinal bool
+	}{
+		{
+			name:      "no actions, succeeded",
+			status:    "SUCCESS",
+			want:      []providerOutcome{{Status: "SUCCESS"}},
+			wantFinal: true,
+		},
+		{
+			name:      "no actions, failed",
+			status:    "REJECTED",
+			want:      []providerOutcome{{Status: "REJECTED", Error: "job REJECTED"}},
+			wantFinal: true,
+		},
+		{
+			name:   "no actions, pending",
+			status: "IN_PROGRESS",
+		},
+		{
+			name: "pending action keeps job open",
+			job: models.Job{Actions: []models.JobAction{
+				{NeId: "100", Status: "SUCCESS"},
+				{NeId: "101", Status: "PENDING"},
+			}},
+			status: "COMPLETED",
+		},
+		{
+			name: "outcome per action",
+			job: models.Job{Actions: []models.JobAction{
+				{NeId: "100", Status: "SUCCESS"},
+				{NeId: "101", Status: "FAILED", ErrorMsg: "msg", ErrorDesc: "desc"},
+				{NeId: "102", Status: "REJECTED", ErrorMsg: "not allowed"},
+			}},
+			status: "IN_PROGRESS", // Статус job не важен, если actions завершены
+			want: []providerOutcome{
+				{NeID: "100", Status: "SUCCESS"},
+				{NeID: "101", Status: "FAILED", Error: "desc"},
+				{NeID: "102", Status: "REJECTED", Error: "not allowed"},
+			},
+			wantFinal: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, final := evaluateJob(tt.job, tt.status)
+			if final != tt.wantFinal {
+				t.Fatalf("final = %v, want %v", final, tt.wantFinal)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("outcomes = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		task models.SyncTaskExtended
+		want []confirmedChange
+	}{
+		{
+			name: "single rate plan keeps task old value",
+			task: models.SyncTaskExtended{Type: models.TaskTypeRatePlanChange, TargetMSISDN: "100", OldRatePlan: "A", NewRatePlan: "B"},
+			want: []confirmedChange{{Action: "RATE_PLAN_CHANGE", Field: "rate_plan", Old: "A", Value: "B"}},
+		},
+		{
+			name: "bulk rate plan has no task old value",
+			task: models.SyncTaskExtended{Type: models.TaskTypeRatePlanChange, OldRatePlan: "A", Payload: `{"msisdns":["100","101"],"rate_plan":"B"}`},
+			want: []confirmedChange{{Action: "RATE_PLAN_CHANGE", Field: "rate_plan", Value: "B"}},
+		},
+		{
+			name: "sim swap",
+			task: models.SyncTaskExtended{Type: models.TaskTypeSimSwap, TargetMSISDN: "100", OldICCID: "1", NewICCID: "2"},
+			want: []confirmedChange{{Action: "SIM_SWAP", Field: "iccid", Old: "1", Value: "2"}},
+		},
+		{
+			name: "status change",
+			task: models.SyncTaskExtended{Type: "CHANGE_STATUS", Payload: `{"msisdns":["100"],"status":"Suspended"}`},
+			want: []confirmedChange{{Action: "STATUS_CHANGE", Field: "status", Value: "Suspended"}},
+		},
+		{
+			name: "label update normalizes the field",
+			task: models.SyncTaskExtended{Type: models.TaskTypeLabelUpdate, TargetMSISDN: "100", LabelField: "CUSTOMER_LABEL_2", LabelValue: "x"},
+			want: []confirmedChange{{Action: "UPDATE_FIELD", Field: "label_2", Value: "x"}},
+		},
+		{
+			name: "change set maps each action",
+			task: models.SyncTaskExtended{Type: models.TaskTypeChangeSet, Payload: `{"msisdns":["100"],"changes":[` +
+				`{"action_type":"SIM_STATE_CHANGE","target_value":"Active"},` +
+				`{"action_type":"RATE_PLAN_CHANGE","target_value":"B"},` +
+				`{"action_type":"CUSTOMER_LABEL_3","target_value":"x"}]}`},
+			want: []confirmedChange{
+				{Action: "STATUS_CHANGE", Field: "status", Value: "Active"},
+				{Action: "RATE_PLAN_CHANGE", Field: "rate_plan", Value: "B"},
+				{Action: "UPDATE_FIELD", Field: "label_3", Value: "x"},
+			},
+		},
+		{
+			name: "broken payload",
+			task: models.SyncTaskExtended{Type: "UPDATE_SIM", Payload: "{"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := taskChanges(tt.task); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("taskChanges = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
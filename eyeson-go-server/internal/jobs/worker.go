@@ -6,12 +6,16 @@
 package jobs
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"eyeson-go-server/internal/eyesont"
 	"eyeson-go-server/internal/handlers"
 	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
 	"eyeson-go-server/internal/reactive"
 	"eyeson-go-server/internal/services"
+	"eyeson-go-server/internal/syncer"
 	"fmt"
 	"log"
 	"strings"
@@ -23,34 +27,67 @@ import (
 
 type Worker struct {
 	DB     *gorm.DB
-	Client *eyesont.Client
 	paused int32
+
+	// ctx - контекст сервиса (отменяется при shutdown), для отложенных синхронизаций
+	ctx context.Context
 }
 
 func New(db *gorm.DB) *Worker {
 	return &Worker{
-		DB:     db,
-		Client: eyesont.Instance,
+		DB:  db,
+		ctx: context.Background(),
+	}
+}
+
+// providerFor возвращает клиента аккаунта, которому принадлежит SIM задачи.
+// Задачи, созданные до появления аккаунтов (account_id = 0), привязываются здесь.
+func (w *Worker) providerFor(task *models.SyncTaskExtended) (provider.Provider, error) {
+	if task.AccountID == 0 {
+		target := task.TargetMSISDN
+		if target == "" {
+			target = task.TargetCLI
+		}
+		if accountID := services.Accounts.AccountForMSISDN(target); accountID != 0 {
+			task.AccountID = accountID
+			w.DB.Model(task).Update("account_id", accountID)
+		}
 	}
+
+	p, err := services.Accounts.Provider(task.AccountID)
+	if err != nil {
+		return nil, &eyesont.APIError{Kind: eyesont.ErrKindValidation, Op: string(task.Type),
+			Message: fmt.Sprintf("upstream account #%d unavailable", task.AccountID), Err: err}
+	}
+	return p, nil
 }
 
 func (w *Worker) IsPaused() bool {
 	return atomic.LoadInt32(&w.paused) == 1
 }
 
-func (w *Worker) Start() {
+// Start запускает обработку очереди; отмена ctx останавливает цикл и прерывает текущий запрос
+func (w *Worker) Start(ctx context.Context) {
 	log.Println("[JobWorker] Starting background job worker...")
+	w.ctx = ctx
 
 	// Clean up stale tasks on startup
 	w.cleanupStaleTasks()
 
 	go func() {
 		ticker := time.NewTicker(1 * time.Second) // Check every 1 second for responsiveness
-		for range ticker.C {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				log.Println("[JobWorker] Stopped")
+				return
+			case <-ticker.C:
+			}
 			if w.IsPaused() {
 				continue
 			}
-			w.ProcessPendingTasks()
+			w.ProcessPendingTasks(ctx)
 		}
 	}()
 }
@@ -87,7 +124,7 @@ func (w *Worker) cleanupStaleTasks() {
 		Update("max_attempts", 3)
 }
 
-func (w *Worker) ProcessPendingTasks() {
+func (w *Worker) ProcessPendingTasks(ctx context.Context) {
 	var tasks []models.SyncTaskExtended
 
 	// Fetch pending tasks
@@ -97,11 +134,17 @@ func (w *Worker) ProcessPendingTasks() {
 	}
 
 	for _, task := range tasks {
-		w.processTask(task)
+		if ctx.Err() != nil {
+			return
+		}
+		// Переключение upstream дожидается текущей задачи (services.SwitchUpstream)
+		taskCtx, release := services.UpstreamGate.Enter(ctx, true)
+		w.processTask(taskCtx, task)
+		release()
 	}
 }
 
-func (w *Worker) processTask(task models.SyncTaskExtended) {
+func (w *Worker) processTask(ctx context.Context, task models.SyncTaskExtended) {
 	log.Printf("[JobWorker] Processing task ID=%d Type=%s Target=%s Attempt=%d/%d", task.ID, task.Type, task.TargetMSISDN, task.Attempt, task.MaxAttempts)
 
 	// Ensure MaxAttempts is set (default 3 if not configured)
@@ -119,27 +162,66 @@ func (w *Worker) processTask(task models.SyncTaskExtended) {
 			"updated_at": time.Now(),
 		})
 		services.Audit.LogQueueFailed(task.ID, task.TargetMSISDN, "Max attempts exceeded", 0)
+		w.releaseTaskResources(task)
+		return
+	}
+
+	// Задача выполняется от имени аккаунта, которому принадлежит SIM
+	prov, provErr := w.providerFor(&task)
+
+	// Upstream недоступен (circuit breaker открыт) - задача ждёт в PENDING, попытка не тратится
+	if provErr == nil && !provider.IsAvailable(prov) {
 		return
 	}
 
 	startTime := time.Now()
 
-	// Update status to PROCESSING
-	w.DB.Model(&task).Updates(map[string]interface{}{
+	// Update status to PROCESSING (задачу могли отменить, пока она ждала своей очереди)
+	claim := w.DB.Model(&task).Where("status = ?", "PENDING").Updates(map[string]interface{}{
 		"status":     "PROCESSING",
 		"updated_at": time.Now(),
 	})
+	if claim.Error == nil && claim.RowsAffected == 0 {
+		log.Printf("[JobWorker] Task ID=%d is no longer PENDING - skipping", task.ID)
+		return
+	}
+	task.Status = models.TaskStatusProcessing
+
+	// Отмена задачи через Queue.CancelTask прерывает её HTTP-запрос к провайдеру
+	taskCtx, cancel := context.WithCancel(ctx)
+	services.Queue.TrackInFlight(task.ID, cancel)
+	defer func() {
+		services.Queue.UntrackInFlight(task.ID)
+		cancel()
+	}()
 
-	var err error
 	var result string
 
-	switch task.Type {
-	case "UPDATE_SIM", "LABEL_UPDATE":
-		result, err = w.handleUpdateSim(task)
-	case "CHANGE_STATUS", "STATUS_CHANGE", "BULK_CHANGE":
-		result, err = w.handleChangeStatus(task)
-	default:
-		err = fmt.Errorf("unknown task type: %s", task.Type)
+	err := provErr
+	if err == nil {
+		switch task.Type {
+		case "UPDATE_SIM", "LABEL_UPDATE":
+			result, err = w.handleUpdateSim(taskCtx, prov, &task)
+		case "CHANGE_STATUS", "STATUS_CHANGE", "BULK_CHANGE":
+			result, err = w.handleChangeStatus(taskCtx, prov, &task)
+		case "RATE_PLAN_CHANGE":
+			result, err = w.handleChangeRatePlan(taskCtx, prov, &task)
+		case "SIM_SWAP":
+			result, err = w.handleSimSwap(taskCtx, prov, &task)
+		case "CHANGE_SET":
+			result, err = w.handleChangeSet(taskCtx, prov, &task)
+		default:
+			err = eyesont.NewValidationError(string(task.Type), "unknown task type")
+		}
+	}
+
+	if err != nil && eyesont.IsCanceled(err) {
+		w.abandonTask(ctx, task)
+		return
+	}
+	if err != nil && eyesont.IsCircuitOpen(err) {
+		w.holdTask(task, err)
+		return
 	}
 
 	durationMs := time.Since(startTime).Milliseconds()
@@ -149,52 +231,28 @@ func (w *Worker) processTask(task models.SyncTaskExtended) {
 		result = errMsg
 		log.Printf("[JobWorker] Task ID=%d FAILED: %v", task.ID, err)
 
-		// Determine Failure Type
-		isNetworkError := strings.Contains(errMsg, "connect refused") ||
-			strings.Contains(errMsg, "connectex") ||
-			strings.Contains(errMsg, "no such host") ||
-			strings.Contains(errMsg, "timeout") ||
-			strings.Contains(errMsg, "dial tcp")
-		isRefused := strings.Contains(errMsg, "500") || strings.Contains(errMsg, "403") || strings.Contains(errMsg, "Server Internal Error")
-
-		// Fatal errors that should NOT be retried - mark as COMPLETED with error
-		isFatalError := strings.Contains(errMsg, "not allowed to request_type_id") || // Permission/state issue
-			strings.Contains(errMsg, "Permission Denied") ||
-			strings.Contains(errMsg, "initial value #null") || // Already in target state
-			strings.Contains(errMsg, "subscriber not found") ||
-			strings.Contains(errMsg, "invalid subscriber") ||
-			strings.Contains(errMsg, "not allowed") ||
-			strings.Contains(errMsg, "actionType is mandatory") || // Invalid label update request
-			strings.Contains(errMsg, "targetId is mandatory") ||
-			strings.Contains(errMsg, "Invalid actionType") ||
-			strings.Contains(errMsg, "Invalid targetId") ||
-			strings.Contains(errMsg, "System Error") || // Pelephone system error - won't resolve with retries
-			(strings.Contains(errMsg, "FAILED") && strings.Contains(errMsg, "target_value")) ||
-			(strings.Contains(errMsg, "FAILED") && strings.Contains(errMsg, "mandatory"))
+		// Классифицируем по типу ошибки (eyesont.APIError), а не по тексту сообщения
+		apiErr, typed := eyesont.AsAPIError(err)
+		isFatalError := !eyesont.IsRetryable(err) || errors.Is(err, services.ErrCatalogValidation)
+		isNetworkError := typed && apiErr.Kind == eyesont.ErrKindTransport
+		isRefused := typed && (apiErr.Kind == eyesont.ErrKindHTTPStatus || apiErr.Kind == eyesont.ErrKindNonJSON)
 
 		// Logic based on error type
 		if isFatalError {
 			log.Printf("[JobWorker] FATAL API Error detected - will not retry. Marking as COMPLETED with error.")
 			status = "COMPLETED" // Mark as completed so it doesn't retry
 
-			// Produce a user-friendly result message
-			friendlyMsg := errMsg
-			if strings.Contains(errMsg, "initial value #null") {
-				friendlyMsg = fmt.Sprintf("SIM %s не имеет текущего статуса в системе провайдера (initial value = null). Синхронизируйте данные и попробуйте снова.", task.TargetMSISDN)
-			} else if strings.Contains(errMsg, "not allowed to request_type_id") {
-				friendlyMsg = fmt.Sprintf("Нет прав на изменение статуса SIM %s. Обратитесь к администратору.", task.TargetMSISDN)
-			} else if strings.Contains(errMsg, "subscriber not found") || strings.Contains(errMsg, "invalid subscriber") {
-				friendlyMsg = fmt.Sprintf("SIM %s не найдена в системе провайдера.", task.TargetMSISDN)
-			}
-			result = "SKIPPED: " + friendlyMsg
-			// Log to audit
-			services.Audit.LogQueueCompleted(task.ID, task.TargetMSISDN, result, durationMs)
-
-			w.DB.Model(&task).Updates(map[string]interface{}{
+			result = "SKIPPED: " + friendlyTaskError(task, err)
+			if !w.leaveProcessing(task, map[string]interface{}{
 				"status":     status,
 				"result":     result,
 				"updated_at": time.Now(),
-			})
+			}) {
+				return
+			}
+			// Log to audit
+			services.Audit.LogQueueCompleted(task.ID, task.TargetMSISDN, result, durationMs)
+			w.releaseTaskResources(task)
 			return
 		} else if isNetworkError {
 			log.Printf("[JobWorker] Network Error detected. Server might be DOWN.")
@@ -210,37 +268,114 @@ func (w *Worker) processTask(task models.SyncTaskExtended) {
 			}
 
 			nextRetry := time.Now().Add(time.Minute * time.Duration(backoffMinutes))
-			w.DB.Model(&task).Updates(map[string]interface{}{
+			if !w.leaveProcessing(task, map[string]interface{}{
 				"status":      "PENDING", // Back to pending
 				"attempt":     task.Attempt + 1,
 				"next_run_at": nextRetry,
 				"result":      "RETRYING: " + result,
-			})
+			}) {
+				return
+			}
 
 			// Log retry to audit
 			services.Audit.LogQueueRetry(task.ID, task.TargetMSISDN, task.Attempt+1, task.MaxAttempts, errMsg)
 			return
-		} else {
-			status = "FAILED"
-			// Log failure to audit
-			services.Audit.LogQueueFailed(task.ID, task.TargetMSISDN, errMsg, durationMs)
 		}
-	} else {
+		status = "FAILED"
+	} else if task.ProviderRequestID > 0 {
+		// Провайдер принял запрос - итог по каждому абоненту подтвердит ProviderReconciler
+		log.Printf("[JobWorker] Task ID=%d accepted by provider (requestId=%d) - AWAITING_PROVIDER", task.ID, task.ProviderRequestID)
+		status = string(models.TaskStatusAwaitingProvider)
+	}
+
+	if !w.finishTask(task, status, result, durationMs) {
+		return
+	}
+
+	switch status {
+	case "FAILED":
+		// Log failure to audit
+		services.Audit.LogQueueFailed(task.ID, task.TargetMSISDN, result, durationMs)
+		w.releaseTaskResources(task)
+	case "COMPLETED":
 		log.Printf("[JobWorker] Task ID=%d COMPLETED", task.ID)
+		// Провайдер не вернул requestId - подтверждать нечего, историю пишем сразу
+		w.recordConfirmed(task)
 		// Log completion to audit
 		services.Audit.LogQueueCompleted(task.ID, task.TargetMSISDN, result, durationMs)
 
 		// Invalidate stats cache on successful status/label change
-		if task.Type == "CHANGE_STATUS" || task.Type == "STATUS_CHANGE" || task.Type == "BULK_CHANGE" {
+		if task.Type == "CHANGE_STATUS" || task.Type == "STATUS_CHANGE" || task.Type == "BULK_CHANGE" || task.Type == "RATE_PLAN_CHANGE" || task.Type == "CHANGE_SET" {
 			handlers.InvalidateStatsCache()
 		}
 	}
+}
+
+// leaveProcessing переводит задачу из PROCESSING; false - задачу уже увели из PROCESSING
+// (например, отменили), и её статус перезаписывать нельзя
+func (w *Worker) leaveProcessing(task models.SyncTaskExtended, updates map[string]interface{}) bool {
+	res := w.DB.Model(&task).Where("status = ?", "PROCESSING").Updates(updates)
+	if res.Error == nil && res.RowsAffected == 0 {
+		log.Printf("[JobWorker] Task ID=%d is no longer PROCESSING - result discarded", task.ID)
+		return false
+	}
+	return true
+}
+
+// abandonTask обрабатывает задачу, чей запрос прерван отменой контекста.
+// При shutdown задача возвращается в очередь без расхода попытки; при отмене
+// пользователем статус CANCELLED уже выставлен Queue.CancelTask.
+func (w *Worker) abandonTask(ctx context.Context, task models.SyncTaskExtended) {
+	if ctx.Err() != nil {
+		reason := "shutdown"
+		if services.UpstreamGate.Switching() {
+			reason = "upstream switch"
+		}
+		log.Printf("[JobWorker] Task ID=%d interrupted by %s - returning to PENDING", task.ID, reason)
+		w.DB.Model(&task).Where("status = ?", "PROCESSING").Updates(map[string]interface{}{
+			"status":     "PENDING",
+			"updated_at": time.Now(),
+		})
+		return
+	}
+	log.Printf("[JobWorker] Task ID=%d cancelled - provider request aborted", task.ID)
+}
+
+// holdTask возвращает в очередь задачу, запрос которой не отправлен из-за открытого
+// circuit breaker'а. Попытка не расходуется: worker возьмёт задачу, когда upstream оживёт.
+func (w *Worker) holdTask(task models.SyncTaskExtended, err error) {
+	log.Printf("[JobWorker] Task ID=%d held - %v", task.ID, err)
+	w.DB.Model(&task).Where("status = ?", "PROCESSING").Updates(map[string]interface{}{
+		"status":     "PENDING",
+		"result":     "WAITING: upstream unavailable (circuit breaker open)",
+		"updated_at": time.Now(),
+	})
+}
 
-	w.DB.Model(&task).Updates(map[string]interface{}{
+// finishTask сохраняет итоговый статус задачи, уведомляет UI и пишет историю.
+// Используется worker'ом и ProviderReconciler'ом. Переход условный - из task.Status
+// (PROCESSING или AWAITING_PROVIDER); false - задачу успели перевести (например,
+// отменить), ни статус, ни события и история не записываются.
+func (w *Worker) finishTask(task models.SyncTaskExtended, status, result string, durationMs int64) bool {
+	updates := map[string]interface{}{
 		"status":     status,
 		"result":     result,
 		"updated_at": time.Now(),
-	})
+	}
+	if task.ProviderRequestID > 0 {
+		updates["provider_request_id"] = task.ProviderRequestID
+	}
+	now := time.Now()
+	if status == "COMPLETED" || status == "FAILED" {
+		updates["completed_at"] = &now
+	} else if status == string(models.TaskStatusAwaitingProvider) {
+		updates["submitted_at"] = &now
+	}
+	res := w.DB.Model(&task).Where("status = ?", task.Status).Updates(updates)
+	if res.Error == nil && res.RowsAffected == 0 {
+		log.Printf("[JobWorker] Task ID=%d is no longer %s - result discarded", task.ID, task.Status)
+		return false
+	}
 
 	// Broadcast SSE event to notify UI of task completion/failure
 	broadcaster := handlers.GetEventBroadcaster()
@@ -262,20 +397,54 @@ func (w *Worker) processTask(task models.SyncTaskExtended) {
 		} else if status == "FAILED" {
 			broadcaster.Emit(reactive.EventTaskFailed, eventData, "")
 			log.Printf("[JobWorker] Broadcast TASK_FAILED event for task %d", task.ID)
+		} else if status == string(models.TaskStatusAwaitingProvider) {
+			eventData["provider_request_id"] = task.ProviderRequestID
+			broadcaster.Emit(reactive.EventTaskAwaitingProvider, eventData, "")
 		}
 	}
 
 	// Create History Log for final status
 	if status == "FAILED" || status == "COMPLETED" {
-		w.DB.Create(&models.SimHistory{
-			MSISDN:   task.TargetMSISDN,
-			Action:   "TASK_" + status,
-			Field:    "status", // generic
-			NewValue: status,
-			Source:   "WORKER",
-			TaskID:   &task.ID,
-			OldValue: result, // Store error or result msg
-		})
+		// OldValue - текст ошибки или результата задачи
+		models.NewSimHistory(0, task.TargetMSISDN, "WORKER").ByTask(task.ID).
+			Event("TASK_"+status, "status", result, status).Save(w.DB)
+	}
+	return true
+}
+
+// friendlyTaskError формирует понятное пользователю сообщение для фатальной ошибки задачи
+func friendlyTaskError(task models.SyncTaskExtended, err error) string {
+	apiErr, ok := eyesont.AsAPIError(err)
+	if !ok || apiErr.Kind != eyesont.ErrKindResult {
+		return err.Error()
+	}
+
+	// У аккаунта нет прав на этот тип запроса - result бывает разным, узнаём по тексту
+	if strings.Contains(apiErr.Message, "not allowed to request_type_id") {
+		return fmt.Sprintf("Нет прав на изменение статуса SIM %s. Обратитесь к администратору.", task.TargetMSISDN)
+	}
+
+	switch apiErr.Result {
+	case eyesont.ResultMissingEntity:
+		return fmt.Sprintf("SIM %s не найдена в системе провайдера.", task.TargetMSISDN)
+	case eyesont.ResultRejected:
+		if strings.Contains(apiErr.Message, "initial value #null") {
+			return fmt.Sprintf("SIM %s не имеет текущего статуса в системе провайдера (initial value = null). Синхронизируйте данные и попробуйте снова.", task.TargetMSISDN)
+		}
+		return fmt.Sprintf("Провайдер отклонил изменение SIM %s: %s", task.TargetMSISDN, apiErr.Message)
+	case eyesont.ResultInvalidReq:
+		return fmt.Sprintf("Некорректный запрос для SIM %s: %s", task.TargetMSISDN, apiErr.Message)
+	}
+	return err.Error()
+}
+
+// releaseTaskResources освобождает ресурсы, удерживаемые задачей, которая больше не будет выполняться
+func (w *Worker) releaseTaskResources(task models.SyncTaskExtended) {
+	services.Queue.CloseChunks(task.ID, models.ChunkStatusFailed, "task failed")
+	if task.Type == models.TaskTypeSimSwap {
+		if err := services.Inventory.ReleaseForTask(task.ID); err != nil {
+			log.Printf("[JobWorker] Task ID=%d: failed to release reserved ICCID: %v", task.ID, err)
+		}
 	}
 }
 
@@ -286,9 +455,8 @@ type UpdateSimPayload struct {
 	Value  string `json:"value"`
 }
 
-func (w *Worker) handleUpdateSim(task models.SyncTaskExtended) (string, error) {
-	var msisdn, field, value string
-
+// updateSimTarget возвращает абонента, поле и значение задачи UPDATE_SIM / LABEL_UPDATE
+func updateSimTarget(task models.SyncTaskExtended) (msisdn, field, value string, err error) {
 	// Для LABEL_UPDATE используем поля LabelField и LabelValue из задачи
 	if task.Type == models.TaskTypeLabelUpdate && task.LabelField != "" {
 		msisdn = task.TargetMSISDN
@@ -307,40 +475,48 @@ func (w *Worker) handleUpdateSim(task models.SyncTaskExtended) (string, error) {
 		case "CUSTOMER_LABEL_3":
 			field = "label_3"
 		}
+		return msisdn, field, value, nil
+	}
+
+	// Fallback для UPDATE_SIM - парсим из Payload
+	var p UpdateSimPayload
+	if err := json.Unmarshal([]byte(task.Payload), &p); err != nil {
+		return "", "", "", &eyesont.APIError{Kind: eyesont.ErrKindValidation, Op: string(task.Type), Message: "failed to parse payload", Err: err}
+	}
+	return p.Msisdn, p.Field, p.Value, nil
+}
 
+func (w *Worker) handleUpdateSim(ctx context.Context, prov provider.Provider, task *models.SyncTaskExtended) (string, error) {
+	msisdn, field, value, err := updateSimTarget(*task)
+	if err != nil {
+		return "", err
+	}
+	if task.Type == models.TaskTypeLabelUpdate {
 		log.Printf("[Worker] LABEL_UPDATE: msisdn=%s, field=%s, value=%s", msisdn, field, value)
-	} else {
-		// Fallback для UPDATE_SIM - парсим из Payload
-		var p UpdateSimPayload
-		if err := json.Unmarshal([]byte(task.Payload), &p); err != nil {
-			return "", fmt.Errorf("failed to parse payload: %w", err)
-		}
-		msisdn = p.Msisdn
-		field = p.Field
-		value = p.Value
 	}
 
 	if msisdn == "" {
-		return "", fmt.Errorf("msisdn is required")
+		return "", eyesont.NewValidationError(string(task.Type), "msisdn is required")
 	}
 
-	// Для label updates используем UpdateSIMLabel
-	var resp *models.BulkUpdateResponse
-	var err error
+	// Для label updates используем действие CUSTOMER_LABEL_N
+	actionType := field
 	if field == "label_1" || field == "label_2" || field == "label_3" {
-		resp, err = w.Client.UpdateSIMLabel(msisdn, field, value)
-	} else {
-		resp, err = w.Client.BulkUpdate([]string{msisdn}, field, value)
+		actionType = provider.LabelAction(field)
 	}
+	resp, err := prov.UpdateProvisioning(ctx, []string{msisdn}, actionType, value)
 
 	if err != nil {
 		return "", err
 	}
 
-	if resp != nil && resp.Result != "succeeded" && resp.Result != "SUCCESS" {
-		return "", fmt.Errorf("API Error: %s - %s", resp.Result, resp.Message)
+	if resp != nil {
+		task.ProviderRequestID = resp.RequestId
 	}
 
+	// Историю UPDATE_FIELD пишет recordConfirmed, когда провайдер подтвердит изменение
+	w.rememberOldValues(task, []string{msisdn}, field)
+
 	// Update local DB to reflect change immediately
 	if field == "label_1" || field == "label_2" || field == "label_3" {
 		dbField := "label1"
@@ -352,23 +528,9 @@ func (w *Worker) handleUpdateSim(task models.SyncTaskExtended) (string, error) {
 		w.DB.Model(&models.SimCard{}).Where("msisdn = ? OR cli = ?", msisdn, msisdn).Update(dbField, value)
 	}
 
-	// Create History
-	w.DB.Create(&models.SimHistory{
-		SimID:    0, // We need to lookup ID, skipped for now
-		MSISDN:   msisdn,
-		Action:   "UPDATE_FIELD",
-		Field:    field,
-		NewValue: value,
-		Source:   "SYNC_WORKER",
-		TaskID:   &task.ID,
-	})
-
 	// НЕ синхронизируем с API сразу - Pelephone имеет eventual consistency
 	// Запланируем отложенную синхронизацию через 15 секунд (увеличено с 5 до 15 для избежания race condition)
-	go func(m string) {
-		time.Sleep(15 * time.Second)
-		w.syncSimsFromAPI([]string{m})
-	}(msisdn)
+	w.syncLater(task, []string{msisdn})
 
 	return "Update successful", nil
 }
@@ -378,7 +540,7 @@ type BulkStatusPayload struct {
 	Status  string   `json:"status"`
 }
 
-func (w *Worker) handleChangeStatus(task models.SyncTaskExtended) (string, error) {
+func (w *Worker) handleChangeStatus(ctx context.Context, prov provider.Provider, task *models.SyncTaskExtended) (string, error) {
 	var p BulkStatusPayload
 	if err := json.Unmarshal([]byte(task.Payload), &p); err != nil {
 		return "", err
@@ -392,141 +554,281 @@ func (w *Worker) handleChangeStatus(task models.SyncTaskExtended) (string, error
 	// The Pelephone API determines "initialValue" server-side; if it resolves
 	// to null the request is rejected. By checking beforehand we can give a
 	// clear error instead of a cryptic permission-denied message.
+	// Для bulk-задач проверка стоила бы запроса на каждую SIM - отказы придут в job провайдера.
+	lookup, canLookup := prov.(provider.StatusLookup)
+	canLookup = canLookup && len(p.Msisdns) == 1
 	for _, msisdn := range p.Msisdns {
-		upstreamStatus, err := w.Client.GetSimStatus(msisdn)
+		if !canLookup {
+			break
+		}
+		upstreamStatus, err := lookup.GetSimStatus(ctx, msisdn)
+		if eyesont.IsCanceled(err) {
+			return "", err
+		}
 		if err != nil {
 			log.Printf("[JobWorker] Pre-validation WARNING for %s: %v", msisdn, err)
 			// Network errors should not block – let the actual API call handle it
 			continue
 		}
 		if upstreamStatus == "" {
-			return "", fmt.Errorf(
+			return "", eyesont.NewValidationError(string(task.Type), fmt.Sprintf(
 				"SIM %s has no status in upstream API (initial value would be null). "+
 					"Cannot change status to %s. Please sync data first or verify the SIM in the provider portal",
-				eyesont.NormalizeMSISDN(msisdn), p.Status)
+				eyesont.NormalizeMSISDN(msisdn), p.Status))
 		}
 		log.Printf("[JobWorker] Pre-validated SIM %s: current upstream status = %s", msisdn, upstreamStatus)
 	}
 
+	// Bulk-задача отправляется частями; локально обновляем только принятых абонентов
+	if len(p.Msisdns) > 1 {
+		sent, err := w.submitChunks(ctx, prov, task, p.Msisdns, sendAction(prov, provider.ActionSimStateChange, p.Status))
+		w.applyStatusChange(task, sent, p.Status)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Submitted %d SIMs in chunks", len(sent)), nil
+	}
+
 	// Call API
-	resp, err := w.Client.BulkUpdate(p.Msisdns, "SIM_STATE_CHANGE", p.Status)
+	resp, err := prov.UpdateProvisioning(ctx, p.Msisdns, provider.ActionSimStateChange, p.Status)
 	if err != nil {
 		return "", err
 	}
 
-	if resp != nil && resp.Result != "succeeded" && resp.Result != "SUCCESS" {
-		return "", fmt.Errorf("API Error: %s - %s", resp.Result, resp.Message)
+	if resp != nil {
+		task.ProviderRequestID = resp.RequestId
 	}
 
-	// Fetch old statuses before update
-	var oldSims []models.SimCard
-	w.DB.Where("msisdn IN ?", p.Msisdns).Find(&oldSims)
-	oldStatusMap := make(map[string]string)
-	for _, sim := range oldSims {
-		oldStatusMap[sim.MSISDN] = sim.Status
+	w.applyStatusChange(task, p.Msisdns, p.Status)
+	return fmt.Sprintf("Updated %d SIMs", len(p.Msisdns)), nil
+}
+
+// applyStatusChange обновляет статус в локальной БД и планирует отложенную синхронизацию
+// для абонентов, чей запрос принят провайдером. Историю STATUS_CHANGE пишет recordConfirmed.
+func (w *Worker) applyStatusChange(task *models.SyncTaskExtended, msisdns []string, status string) {
+	if len(msisdns) == 0 {
+		return
 	}
 
+	// Прежние статусы нужны истории, которая появится только после подтверждения
+	w.rememberOldValues(task, msisdns, "status")
+
 	// Update local DB for immediate UI feedback
-	w.DB.Model(&models.SimCard{}).Where("msisdn IN ?", p.Msisdns).Update("status", p.Status)
+	w.DB.Model(&models.SimCard{}).Where("msisdn IN ?", msisdns).Update("status", status)
 
 	// НЕ синхронизируем с API сразу - Pelephone имеет eventual consistency
 	// API вернёт старый статус в течение 2-5 секунд после обновления
 	// Синхронизация произойдёт при следующем полном sync цикле
-	// w.syncSimsFromAPI(p.Msisdns)
+	// w.syncSimsFromAPI(ctx, msisdns)
 
 	// Запланируем отложенную синхронизацию через 15 секунд (увеличено с 5 до 15 для избежания race condition)
-	go func(msisdns []string) {
-		time.Sleep(15 * time.Second)
-		w.syncSimsFromAPI(msisdns)
-		handlers.InvalidateStatsCache()
-	}(p.Msisdns)
+	w.syncLater(task, msisdns)
+}
 
-	// Create history records for each SIM
-	for _, msisdn := range p.Msisdns {
-		oldStatus := oldStatusMap[msisdn]
-		if oldStatus == "" {
-			oldStatus = "Unknown"
-		}
-
-		w.DB.Create(&models.SimHistory{
-			MSISDN:    msisdn,
-			Action:    "STATUS_CHANGE",
-			Field:     "status",
-			OldValue:  oldStatus,
-			NewValue:  p.Status,
-			Source:    "WORKER",
-			ChangedBy: "system",
-			TaskID:    &task.ID,
-		})
+// rememberOldValues сохраняет в задаче значения полей до оптимистичного обновления локальной БД.
+// Значения первой отправки не перезаписываются: при повторе части в БД уже новое значение.
+func (w *Worker) rememberOldValues(task *models.SyncTaskExtended, msisdns []string, fields ...string) {
+	var sims []models.SimCard
+	w.DB.Where("msisdn IN ? OR cli IN ?", msisdns, msisdns).Find(&sims)
+	simMap := make(map[string]models.SimCard, len(sims))
+	for _, sim := range sims {
+		simMap[sim.MSISDN] = sim
+		if sim.CLI != "" {
+			simMap[sim.CLI] = sim
+		}
 	}
 
-	return fmt.Sprintf("Updated %d SIMs", len(p.Msisdns)), nil
-}
+	olds := task.OldFieldValues()
+	for _, msisdn := range msisdns {
+		if olds[msisdn] == nil {
+			olds[msisdn] = make(map[string]string, len(fields))
+		}
+		for _, field := range fields {
+			if _, ok := olds[msisdn][field]; !ok {
+				olds[msisdn][field] = simMap[msisdn].FieldValue(field)
+			}
+		}
+	}
 
-// syncSimsFromAPI fetches and updates SIM data from API after task completion
-func (w *Worker) syncSimsFromAPI(msisdns []string) {
-	if w.Client == nil || len(msisdns) == 0 {
+	data, err := json.Marshal(olds)
+	if err != nil {
 		return
 	}
+	task.OldValues = string(data)
+	w.DB.Model(task).Update("old_values", task.OldValues)
+}
 
-	log.Printf("[JobWorker] Syncing %d SIMs from API after task completion", len(msisdns))
+type RatePlanPayload struct {
+	Msisdns  []string `json:"msisdns"`
+	RatePlan string   `json:"rate_plan"`
+}
 
-	// Fetch updated data from API for each MSISDN
-	for _, msisdn := range msisdns {
-		// Build search criteria for this specific SIM
-		searchCriteria := []models.SearchParam{
-			{
-				FieldName:  "MSISDN",
-				FieldValue: msisdn,
-			},
-		}
+func (w *Worker) handleChangeRatePlan(ctx context.Context, prov provider.Provider, task *models.SyncTaskExtended) (string, error) {
+	var p RatePlanPayload
+	if err := json.Unmarshal([]byte(task.Payload), &p); err != nil {
+		return "", err
+	}
+	if p.RatePlan == "" {
+		p.RatePlan = task.NewRatePlan
+	}
+
+	if len(p.Msisdns) == 0 {
+		return "No MSISDNs", nil
+	}
+	if strings.TrimSpace(p.RatePlan) == "" {
+		return "", eyesont.NewValidationError(string(task.Type), "rate plan is required")
+	}
+
+	// Pre-validate against the cached provisioning catalog of the task's account
+	// (getProvisioningParameterList, refreshed by the syncer).
+	if err := services.Catalog.Validate(task.AccountID, services.CatalogFieldRatePlan, p.RatePlan); err != nil {
+		return "", err
+	}
 
-		// Fetch from API
-		resp, err := w.Client.GetSims(0, 1, searchCriteria, "", "")
+	// Bulk-задача отправляется частями; синхронизируем только принятых абонентов
+	if len(p.Msisdns) > 1 {
+		sent, err := w.submitChunks(ctx, prov, task, p.Msisdns, sendAction(prov, provider.ActionRatePlanChange, p.RatePlan))
+		w.applyRatePlanChange(task, sent)
 		if err != nil {
-			log.Printf("[JobWorker] Failed to sync SIM %s: %v", msisdn, err)
-			continue
+			return "", err
 		}
+		return fmt.Sprintf("Rate plan change to %s submitted for %d SIMs in chunks", p.RatePlan, len(sent)), nil
+	}
 
-		if len(resp.Data) == 0 {
-			log.Printf("[JobWorker] SIM %s not found in API response", msisdn)
-			continue
+	resp, err := prov.UpdateProvisioning(ctx, p.Msisdns, provider.ActionRatePlanChange, p.RatePlan)
+	if err != nil {
+		return "", err
+	}
+
+	if resp != nil {
+		task.ProviderRequestID = resp.RequestId
+	}
+
+	w.applyRatePlanChange(task, p.Msisdns)
+	return fmt.Sprintf("Rate plan change to %s requested for %d SIMs (requestId=%d)", p.RatePlan, len(p.Msisdns), task.ProviderRequestID), nil
+}
+
+// applyRatePlanChange планирует отложенную синхронизацию после смены тарифа.
+// Историю RATE_PLAN_CHANGE пишет recordConfirmed, когда провайдер подтвердит job.
+func (w *Worker) applyRatePlanChange(task *models.SyncTaskExtended, msisdns []string) {
+	if len(msisdns) == 0 {
+		return
+	}
+
+	// RATE_PLAN_CHANGE назначает future план - локальный rate_plan не трогаем,
+	// актуальное значение подтянет отложенная синхронизация
+	w.syncLater(task, msisdns)
+}
+
+type SimSwapPayload struct {
+	Msisdn   string `json:"msisdn"`
+	OldICCID string `json:"old_iccid"`
+	NewICCID string `json:"new_iccid"`
+}
+
+func (w *Worker) handleSimSwap(ctx context.Context, prov provider.Provider, task *models.SyncTaskExtended) (string, error) {
+	var p SimSwapPayload
+	if err := json.Unmarshal([]byte(task.Payload), &p); err != nil {
+		return "", &eyesont.APIError{Kind: eyesont.ErrKindValidation, Op: string(task.Type), Message: "failed to parse payload", Err: err}
+	}
+	if p.Msisdn == "" {
+		p.Msisdn = task.TargetMSISDN
+	}
+	if p.NewICCID == "" {
+		p.NewICCID = task.NewICCID
+	}
+	if p.OldICCID == "" {
+		p.OldICCID = task.OldICCID
+	}
+
+	if p.Msisdn == "" || p.NewICCID == "" {
+		return "", eyesont.NewValidationError(string(task.Type), "msisdn and new ICCID are mandatory")
+	}
+
+	resp, err := prov.UpdateProvisioning(ctx, []string{p.Msisdn}, provider.ActionSimSwap, strings.TrimSpace(p.NewICCID))
+	if err != nil {
+		return "", err
+	}
+
+	if resp != nil {
+		task.ProviderRequestID = resp.RequestId
+	}
+
+	// Без requestId подтвердить замену через getProvisioningJobList нельзя -
+	// фиксируем склад сразу, иначе это сделает ProviderReconciler
+	if task.ProviderRequestID == 0 {
+		if err := services.Inventory.CompleteSwap(p.Msisdn, p.OldICCID, p.NewICCID, task.ID); err != nil {
+			log.Printf("[JobWorker] SIM_SWAP %s: inventory update failed: %v", p.Msisdn, err)
 		}
+	}
 
-		simData := resp.Data[0]
+	// Историю SIM_SWAP пишет recordConfirmed, когда провайдер подтвердит замену.
+	// SimCard.ICCID локально не меняем и syncSimsFromAPI не вызываем: следующий
+	// полный sync увидит новый SIM_SWAP и запишет CHANGE_ICCID, подтверждая замену.
 
-		// Update database with fresh data from API
-		var sim models.SimCard
-		result := w.DB.Where("msisdn = ?", msisdn).First(&sim)
+	return fmt.Sprintf("SIM swap %s -> %s requested (requestId=%d)", p.OldICCID, p.NewICCID, task.ProviderRequestID), nil
+}
 
-		if result.Error != nil {
-			// SIM doesn't exist, create it
-			sim = models.SimCard{
-				MSISDN: msisdn,
-			}
+// syncLater через 15 секунд подтягивает изменённые SIM из API (eventual consistency Pelephone).
+// Клиент берётся заново и синхронизация идёт через UpstreamGate: за это время upstream
+// могли переключить, а клиент, выполнявший задачу, - закрыть.
+func (w *Worker) syncLater(task *models.SyncTaskExtended, msisdns []string) {
+	accountID := task.AccountID
+	go func() {
+		if eyesont.SleepContext(w.ctx, 15*time.Second) != nil {
+			return
 		}
+		ctx, release := services.UpstreamGate.Enter(w.ctx, false)
+		defer release()
 
-		// Update all fields from API
-		sim.CLI = simData.CLI
-		sim.IMSI = simData.IMSI
-		sim.ICCID = simData.SimSwap
-		sim.IMEI = simData.IMEI
-		sim.Status = simData.SimStatusChange
-		sim.RatePlan = simData.RatePlanFullName
-		sim.Label1 = simData.CustomerLabel1
-		sim.Label2 = simData.CustomerLabel2
-		sim.Label3 = simData.CustomerLabel3
-		sim.APN = simData.ApnName
-		sim.IP = simData.Ip1
-		sim.InSession = simData.InSession == "true"
+		prov, err := services.Accounts.Provider(accountID)
+		if err != nil {
+			log.Printf("[JobWorker] Delayed sync of %d SIMs skipped: %v", len(msisdns), err)
+			return
+		}
+		w.syncSimsFromAPI(ctx, prov, accountID, msisdns)
+		handlers.InvalidateStatsCache()
+	}()
+}
 
-		// Save to DB
-		if result.Error != nil {
-			w.DB.Create(&sim)
-		} else {
-			w.DB.Save(&sim)
+// syncSimsFromAPI fetches and updates SIM data from API after task completion.
+// Это scoped-синхронизация по MSISDN: тот же mapApiToModel, diff и защита полей задач,
+// что и у полного обхода, поэтому обновляются все поля (usage, даты, доп. поля Pelephone).
+func (w *Worker) syncSimsFromAPI(ctx context.Context, prov provider.Provider, accountID uint, msisdns []string) {
+	if prov == nil || len(msisdns) == 0 {
+		return
+	}
+	if accountID == 0 {
+		// Старые задачи без аккаунта - аккаунт по умолчанию (его же клиент выбирает Provider(0))
+		if account, err := services.Accounts.Default(); err == nil {
+			accountID = account.ID
 		}
+	}
+
+	log.Printf("[JobWorker] Syncing %d SIMs from API after task completion", len(msisdns))
+	s := &syncer.Syncer{DB: w.DB, Provider: prov, AccountID: accountID, Trigger: models.SyncTriggerTask}
 
-		log.Printf("[JobWorker] ✅ Synced SIM %s from API", msisdn)
+	// Scope принимает не больше MaxScopeMSISDNs номеров - большие bulk-задачи синхронизируем частями
+	processed, updated := 0, 0
+	for _, part := range provider.SplitSubscribers(msisdns, syncer.MaxScopeMSISDNs) {
+		result, err := s.SyncScoped(ctx, syncer.Scope{MSISDNs: part})
+		if err != nil {
+			log.Printf("[JobWorker] Failed to sync SIMs from API: %v", err)
+		}
+		if result == nil {
+			if eyesont.IsCanceled(err) {
+				break
+			}
+			continue
+		}
+		for _, msisdn := range result.NotFound {
+			log.Printf("[JobWorker] SIM %s not found in API response", msisdn)
+		}
+		processed += result.Run.Processed
+		updated += result.Run.Updated
+		if ctx.Err() != nil {
+			break
+		}
 	}
+	log.Printf("[JobWorker] ✅ Synced %d SIMs from API (%d updated)", processed, updated)
 }
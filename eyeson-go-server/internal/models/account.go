@@ -0,0 +1,37 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package models
+
+import "time"
+
+// ═══════════════════════════════════════════════════════════
+// UPSTREAM ACCOUNTS
+// ═══════════════════════════════════════════════════════════
+
+// UpstreamAccount - учётная запись клиента у провайдера (например, отдельный
+// customer account в Pelephone). У каждого аккаунта свой клиент и своя сессия.
+type UpstreamAccount struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name     string `gorm:"uniqueIndex;not null;size:100" json:"name"`
+	Provider string `gorm:"size:30;default:pelephone" json:"provider"`
+	// BaseURL пустой - используется выбранный upstream (EYESON_API_BASE_URL / симулятор)
+	BaseURL  string `gorm:"size:300" json:"base_url,omitempty"`
+	Username string `gorm:"size:100;not null" json:"username"`
+
+	// PasswordEnc - пароль, зашифрованный AES-GCM (services.Accounts); наружу не отдаётся
+	PasswordEnc string `gorm:"size:500" json:"-"`
+
+	Enabled   bool `json:"enabled"`
+	IsDefault bool `json:"is_default"`
+}
+
+// TableName - имя таблицы
+func (UpstreamAccount) TableName() string {
+	return "upstream_accounts"
+}
@@ -14,6 +14,11 @@ type ResponseBase struct {
 	Message string `json:"message,omitempty"`
 }
 
+// GetBase возвращает общие поля ответа (используется для разбора result в eyesont)
+func (r ResponseBase) GetBase() ResponseBase {
+	return r
+}
+
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -60,7 +65,7 @@ type SimData struct {
 	AllocatedMB      string `json:"ALLOCATED_MB"`
 	LastSessionTime  string `json:"LAST_SESSION_TIME"`
 	InSession        string `json:"IN_SESSION"`
-	SyncStatus       string `json:"SYNC_STATUS,omitempty"` // PENDING, PROCESSING, or empty
+	SyncStatus       string `json:"SYNC_STATUS,omitempty"` // Queued task, "Sync Conflict: ...", or empty
 
 	// Additional fields from Pelephone API
 	EffectiveDate        string  `json:"EFFECTIVE_DATE"`
@@ -120,6 +125,37 @@ type UpdateProvisioningDataResponse struct {
 // BulkUpdateResponse is an alias for UpdateProvisioningDataResponse
 type BulkUpdateResponse = UpdateProvisioningDataResponse
 
+// Provisioning parameter models (getProvisioningParameterList)
+type ProvisioningParameterValue struct {
+	Value int    `json:"value"`
+	Name  string `json:"name"`
+	Desc  string `json:"desc,omitempty"`
+}
+
+type ProvisioningParameter struct {
+	FieldName       string                       `json:"fieldName"`
+	PermissionLevel string                       `json:"permissionLevel"` // READ-ONLY, READ-WRITE, READ-WRITE_FROM_LIST
+	Alias           string                       `json:"alias,omitempty"`
+	AvailableValues []ProvisioningParameterValue `json:"availableValues,omitempty"`
+}
+
+type GetProvisioningParameterListResponse struct {
+	ResponseBase
+	Parameters []ProvisioningParameter `json:"parameters"`
+}
+
+// FindParameter returns the first parameter matching one of the given field names
+func (r *GetProvisioningParameterListResponse) FindParameter(fieldNames ...string) *ProvisioningParameter {
+	for _, name := range fieldNames {
+		for i := range r.Parameters {
+			if r.Parameters[i].FieldName == name {
+				return &r.Parameters[i]
+			}
+		}
+	}
+	return nil
+}
+
 // Job models
 type GetJobsRequest struct {
 	Username  string `json:"username"`
@@ -136,12 +172,14 @@ type JobAction struct {
 	InitialValue string `json:"initialValue,omitempty"` // Value before change
 	TargetValue  string `json:"targetValue"`
 	Status       string `json:"status"`
+	ErrorMsg     string `json:"errorMsg,omitempty"`
 	ErrorDesc    string `json:"errorDesc,omitempty"`
 }
 
 type Job struct {
 	JobId          int         `json:"jobId"`
 	JobStatus      string      `json:"jobStatus"`
+	Status         string      `json:"status,omitempty"` // Так поле называется в PDF v1.5.2
 	RequestTime    interface{} `json:"requestTime"`
 	LastActionTime interface{} `json:"lastActionTime,omitempty"`
 	Actions        []JobAction `json:"actions,omitempty"`
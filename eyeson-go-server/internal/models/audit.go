@@ -35,7 +35,10 @@ const (
 	ActionUpdate        AuditAction = "UPDATE"
 	ActionDelete        AuditAction = "DELETE"
 	ActionStatusChange  AuditAction = "STATUS_CHANGE"
+	ActionRatePlan      AuditAction = "RATE_PLAN_CHANGE"
+	ActionSimSwap       AuditAction = "SIM_SWAP"
 	ActionBulkChange    AuditAction = "BULK_CHANGE"
+	ActionChangeSet     AuditAction = "CHANGE_SET"
 	ActionGoogleLink    AuditAction = "GOOGLE_LINK"
 	ActionLogin         AuditAction = "LOGIN"
 	ActionLogout        AuditAction = "LOGOUT"
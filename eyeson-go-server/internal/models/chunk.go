@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════
+// TASK CHUNKS (BULK UPDATES)
+// ═══════════════════════════════════════════════════════════
+
+// ChunkStatus - статус части bulk-задачи
+type ChunkStatus string
+
+const (
+	ChunkStatusPending   ChunkStatus = "PENDING"   // Не отправлена (или отправка не удалась и будет повторена)
+	ChunkStatusSubmitted ChunkStatus = "SUBMITTED" // Провайдер принял запрос, есть requestId
+	ChunkStatusCompleted ChunkStatus = "COMPLETED" // Провайдер подтвердил job (Failed - отказы по отдельным SIM)
+	ChunkStatusFailed    ChunkStatus = "FAILED"    // Запрос не принят или job отклонён целиком
+	ChunkStatusCancelled ChunkStatus = "CANCELLED" // Задача отменена до отправки части
+)
+
+// TaskChunk - часть большого списка абонентов одной задачи. Каждая часть - отдельный
+// запрос updateProvisioningData со своим requestId: при повторе задачи отправляются
+// только части, которые провайдер ещё не принял.
+type TaskChunk struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	TaskID     uint   `gorm:"index" json:"task_id"`
+	BatchID    string `gorm:"index;size:36" json:"batch_id,omitempty"` // Копия BatchID задачи для прогресса
+	ChunkIndex int    `json:"chunk_index"`
+	Msisdns    string `gorm:"type:text" json:"-"` // JSON-массив абонентов части
+	Size       int    `json:"size"`
+
+	Status    ChunkStatus `gorm:"index;size:20;default:'PENDING'" json:"status"`
+	Attempt   int         `gorm:"default:0" json:"attempt"`
+	LastError string      `gorm:"type:text" json:"last_error,omitempty"`
+
+	// ─── ПРОВАЙДЕР ─────────────────────────────────────────
+	ProviderRequestID int        `gorm:"index" json:"provider_request_id,omitempty"`
+	ProviderStatus    string     `gorm:"size:30" json:"provider_status,omitempty"`
+	Succeeded         int        `json:"succeeded"`
+	Failed            int        `json:"failed"`
+	SubmittedAt       *time.Time `json:"submitted_at,omitempty"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+}
+
+func (TaskChunk) TableName() string {
+	return "task_chunks"
+}
+
+// MSISDNList возвращает абонентов части
+func (c *TaskChunk) MSISDNList() []string {
+	var list []string
+	_ = json.Unmarshal([]byte(c.Msisdns), &list)
+	return list
+}
+
+// SetMSISDNs сохраняет абонентов части
+func (c *TaskChunk) SetMSISDNs(msisdns []string) {
+	data, _ := json.Marshal(msisdns)
+	c.Msisdns = string(data)
+	c.Size = len(msisdns)
+}
+
+// IsOpen - часть ещё не получила окончательный результат
+func (c *TaskChunk) IsOpen() bool {
+	return c.Status == ChunkStatusPending || c.Status == ChunkStatusSubmitted
+}
@@ -48,24 +48,31 @@ type ActivityLog struct {
 
 // >>> NEW SYNC ARCHITECTURE MODELS <<<
 
+// SimCard - локальная копия абонента; тег diff задаёт, как синхронизация относится
+// к изменению поля (см. simdiff.go)
 type SimCard struct {
 	gorm.Model
-	MSISDN      string    `gorm:"uniqueIndex;not null;size:20" json:"msisdn"`
+	MSISDN      string    `gorm:"uniqueIndex;not null;size:20" json:"msisdn" diff:"ignore"`
 	CLI         string    `gorm:"index;size:20" json:"cli"`
 	IMSI        string    `gorm:"index;size:30" json:"imsi"`
-	ICCID       string    `gorm:"size:30" json:"iccid"` // SimSwap field
+	ICCID       string    `gorm:"size:30" json:"iccid" diff:"history,key=ICCID"` // SimSwap field
 	IMEI        string    `gorm:"size:30" json:"imei"`
 	Status      string    `gorm:"index;size:50" json:"status"`
 	RatePlan    string    `gorm:"index;size:100" json:"rate_plan"`
-	Label1      string    `json:"label1"`
-	Label2      string    `json:"label2"`
-	Label3      string    `json:"label3"`
+	Label1      string    `json:"label1" diff:"history,key=LABEL_1"`
+	Label2      string    `json:"label2" diff:"history,key=LABEL_2"`
+	Label3      string    `json:"label3" diff:"history,key=LABEL_3"`
 	APN         string    `json:"apn"`
 	IP          string    `json:"ip"`
-	UsageMB     float64   `json:"usage_mb"`
-	AllocatedMB float64   `json:"allocated_mb"`
-	LastSession time.Time `json:"last_session"`
-	InSession   bool      `json:"in_session"`
+	UsageMB     float64   `json:"usage_mb" diff:"update,threshold=1"`
+	AllocatedMB float64   `json:"allocated_mb" diff:"update"`
+	LastSession time.Time `json:"last_session" diff:"update"`
+	InSession   bool      `json:"in_session" diff:"update"`
+
+	// Провайдер (MNO), из которого получена SIM - имя в реестре provider
+	Provider string `gorm:"index;size:30;default:pelephone" json:"provider" diff:"update"`
+	// Аккаунт провайдера, которому принадлежит SIM (UpstreamAccount.ID)
+	AccountID uint `gorm:"index" json:"account_id" diff:"update"`
 
 	// Additional Pelephone fields
 	EffectiveDate      string  `json:"effective_date"`
@@ -75,9 +82,9 @@ type SimCard struct {
 	CustomerName       string  `gorm:"size:200" json:"customer_name"`
 	SubCustomerName    string  `gorm:"size:200" json:"sub_customer_name"`
 	OrderNumber        string  `gorm:"size:30" json:"order_number"`
-	MonthlyUsageSMS    string  `json:"monthly_usage_sms"`
-	BundleUtilization  string  `json:"bundle_utilization"`
-	PrepaidDataBalance string  `json:"prepaid_data_balance"`
+	MonthlyUsageSMS    string  `json:"monthly_usage_sms" diff:"update"`
+	BundleUtilization  string  `json:"bundle_utilization" diff:"update"`
+	PrepaidDataBalance string  `json:"prepaid_data_balance" diff:"update"`
 	DataThrottle       string  `gorm:"size:10" json:"data_throttle"`
 	IsPooled           string  `gorm:"size:10" json:"is_pooled"`
 	RatePlanChange     string  `gorm:"size:100" json:"rate_plan_change"`
@@ -89,12 +96,16 @@ type SimCard struct {
 	FutureExpirationDate string `json:"future_expiration_date"`
 	ApnHname           string  `json:"apn_hname"`
 	ApnHlsfi           string  `json:"apn_hlsfi"`
-	SimRefresh         string  `gorm:"size:20" json:"sim_refresh"`
-	RefreshSubUsages   string  `gorm:"size:20" json:"refresh_sub_usages"`
+	SimRefresh         string  `gorm:"size:20" json:"sim_refresh" diff:"update"`
+	RefreshSubUsages   string  `gorm:"size:20" json:"refresh_sub_usages" diff:"update"`
 
 	// Sync Metadata
-	LastSyncAt time.Time `gorm:"index" json:"last_sync_at"`
-	IsSyncing  bool      `gorm:"default:false" json:"is_syncing"`
+	LastSyncAt time.Time `gorm:"index" json:"last_sync_at" diff:"ignore"`
+	IsSyncing  bool      `gorm:"default:false" json:"is_syncing" diff:"ignore"`
+	// Полный обход upstream не нашёл SIM с этого момента; после grace-периода - soft delete
+	MissingSince *time.Time `gorm:"index" json:"missing_since,omitempty" diff:"ignore"`
+	// Поля, которые синхронизация не перезаписала: задача ещё ждёт значения от провайдера
+	SyncConflict string `gorm:"size:500" json:"sync_conflict,omitempty" diff:"ignore"`
 }
 
 type SyncTask struct {
@@ -0,0 +1,46 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package models
+
+import "time"
+
+// ═══════════════════════════════════════════════════════════
+// SPARE SIM INVENTORY
+// ═══════════════════════════════════════════════════════════
+
+// SpareSimStatus - состояние ICCID в локальном складе
+type SpareSimStatus string
+
+const (
+	SpareSimAvailable SpareSimStatus = "AVAILABLE" // Свободна, можно выдать под SIM_SWAP
+	SpareSimReserved  SpareSimStatus = "RESERVED"  // Зарезервирована задачей SIM_SWAP
+	SpareSimInUse     SpareSimStatus = "IN_USE"    // Установлена абоненту после успешного SIM_SWAP
+	SpareSimRetired   SpareSimStatus = "RETIRED"   // Старая ICCID, снятая с абонента
+)
+
+// SpareSim - физическая SIM (ICCID) в локальном складе запасных карт
+type SpareSim struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ICCID  string         `gorm:"uniqueIndex;not null;size:30" json:"iccid"`
+	Status SpareSimStatus `gorm:"index;size:20;default:'AVAILABLE'" json:"status"`
+	Notes  string         `gorm:"size:200" json:"notes,omitempty"`
+
+	// Привязка к абоненту и задаче SIM_SWAP
+	MSISDN string `gorm:"index;size:20" json:"msisdn,omitempty"`
+	TaskID *uint  `gorm:"index" json:"task_id,omitempty"`
+
+	ReservedAt *time.Time `json:"reserved_at,omitempty"`
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+	RetiredAt  *time.Time `json:"retired_at,omitempty"`
+}
+
+// TableName - имя таблицы
+func (SpareSim) TableName() string {
+	return "spare_sims"
+}
@@ -6,6 +6,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +25,10 @@ const (
 	TaskTypeLabelUpdate  TaskType = "LABEL_UPDATE"
 	TaskTypeBulkChange   TaskType = "BULK_CHANGE"
 	TaskTypeSync         TaskType = "SYNC"
+
+	TaskTypeRatePlanChange TaskType = "RATE_PLAN_CHANGE"
+	TaskTypeSimSwap        TaskType = "SIM_SWAP"
+	TaskTypeChangeSet      TaskType = "CHANGE_SET" // Несколько действий в одном updateProvisioningData
 )
 
 // TaskStatus - статус задачи
@@ -35,6 +40,9 @@ const (
 	TaskStatusCompleted  TaskStatus = "COMPLETED"
 	TaskStatusFailed     TaskStatus = "FAILED"
 	TaskStatusCancelled  TaskStatus = "CANCELLED"
+
+	// Запрос принят провайдером (есть requestId), ждём подтверждения в getProvisioningJobList
+	TaskStatusAwaitingProvider TaskStatus = "AWAITING_PROVIDER"
 )
 
 // TaskPriority - приоритет задачи (1 = высший, 10 = низший)
@@ -68,6 +76,8 @@ type SyncTaskExtended struct {
 	TargetMSISDN string `gorm:"index;size:20" json:"target_msisdn"` // Основной MSISDN
 	TargetCLI    string `gorm:"index;size:20" json:"target_cli"`    // CLI карты
 	Payload      string `gorm:"type:text" json:"payload"`           // JSON с деталями
+	AccountID    uint   `gorm:"index" json:"account_id,omitempty"`  // Аккаунт провайдера, которому принадлежит SIM
+	OldValues    string `gorm:"type:text" json:"-"`                 // JSON {msisdn: {field: value}} - значения до отправки
 
 	// Для STATUS_CHANGE
 	OldStatus string `gorm:"size:50" json:"old_status,omitempty"`
@@ -77,6 +87,14 @@ type SyncTaskExtended struct {
 	LabelField string `gorm:"size:20" json:"label_field,omitempty"`  // label_1, label_2, label_3
 	LabelValue string `gorm:"size:200" json:"label_value,omitempty"` // Новое значение метки
 
+	// Для RATE_PLAN_CHANGE
+	OldRatePlan string `gorm:"size:100" json:"old_rate_plan,omitempty"`
+	NewRatePlan string `gorm:"size:100" json:"new_rate_plan,omitempty"`
+
+	// Для SIM_SWAP
+	OldICCID string `gorm:"size:30" json:"old_iccid,omitempty"`
+	NewICCID string `gorm:"size:30" json:"new_iccid,omitempty"`
+
 	// ─── BATCH (ГРУППОВЫЕ ОПЕРАЦИИ) ────────────────────────
 	BatchID    string `gorm:"index;size:36" json:"batch_id,omitempty"` // UUID группы
 	BatchTotal int    `json:"batch_total,omitempty"`                   // Всего в группе
@@ -100,8 +118,13 @@ type SyncTaskExtended struct {
 	DurationMs  int64      `json:"duration_ms,omitempty"` // Время выполнения
 
 	// ─── РЕЗУЛЬТАТ ─────────────────────────────────────────
-	Result            string `gorm:"type:text" json:"result,omitempty"` // Результат или ошибка
-	ProviderRequestID int    `json:"provider_request_id,omitempty"`     // ID от Pelephone
+	Result            string `gorm:"type:text" json:"result,omitempty"`          // Результат или ошибка
+	ProviderRequestID int    `gorm:"index" json:"provider_request_id,omitempty"` // ID от Pelephone (jobId)
+
+	// ─── ПОДТВЕРЖДЕНИЕ ПРОВАЙДЕРОМ ──────────────────────────
+	ProviderStatus    string     `gorm:"size:30" json:"provider_status,omitempty"` // Статус job в getProvisioningJobList
+	SubmittedAt       *time.Time `json:"submitted_at,omitempty"`                   // Когда провайдер принял запрос
+	ProviderCheckedAt *time.Time `json:"provider_checked_at,omitempty"`            // Последняя проверка reconciler'ом
 }
 
 // TableName - используем ту же таблицу sync_tasks
@@ -160,9 +183,23 @@ func (t *SyncTaskExtended) IsFailed() bool {
 	return t.Status == TaskStatusFailed
 }
 
-// IsActive - проверяет активна ли задача (pending или processing)
+// IsAwaitingProvider - проверяет ждёт ли задача подтверждения провайдера
+func (t *SyncTaskExtended) IsAwaitingProvider() bool {
+	return t.Status == TaskStatusAwaitingProvider
+}
+
+// OldFieldValues - прежние значения полей по абонентам, сохранённые worker'ом при отправке
+func (t *SyncTaskExtended) OldFieldValues() map[string]map[string]string {
+	olds := make(map[string]map[string]string)
+	if t.OldValues != "" {
+		_ = json.Unmarshal([]byte(t.OldValues), &olds)
+	}
+	return olds
+}
+
+// IsActive - проверяет активна ли задача (pending, processing или ждёт провайдера)
 func (t *SyncTaskExtended) IsActive() bool {
-	return t.Status == TaskStatusPending || t.Status == TaskStatusProcessing
+	return t.Status == TaskStatusPending || t.Status == TaskStatusProcessing || t.Status == TaskStatusAwaitingProvider
 }
 
 // ═══════════════════════════════════════════════════════════
@@ -177,6 +214,8 @@ type QueueStats struct {
 	Failed     int64 `json:"failed"`
 	Cancelled  int64 `json:"cancelled"`
 	TodayTotal int64 `json:"today_total"`
+
+	AwaitingProvider int64 `json:"awaiting_provider"`
 }
 
 // BatchProgress - прогресс выполнения batch операции
@@ -187,4 +226,9 @@ type BatchProgress struct {
 	Failed    int     `json:"failed"`
 	Pending   int     `json:"pending"`
 	Progress  float64 `json:"progress"` // Процент выполнения
+
+	AwaitingProvider int `json:"awaiting_provider"` // Отправлено, ждёт подтверждения провайдера
+
+	// Bulk-задачи считаются по SIM в их частях; Chunks - состояние каждой части
+	Chunks []TaskChunk `json:"chunks,omitempty"`
 }
@@ -0,0 +1,308 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package models
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ═══════════════════════════════════════════════════════════
+// DIFF ПОЛЕЙ SIMCARD И ИСТОРИЯ SIM
+// ═══════════════════════════════════════════════════════════
+//
+// Что делать с изменением каждого поля SimCard, задаёт тег diff:
+//
+//	diff:"history"                 - обновить и записать в SimHistory (CHANGE_<KEY>)
+//	diff:"update"                  - обновить без истории
+//	diff:"ignore"                  - не считать изменением (служебные поля)
+//	diff:"update,threshold=1"      - числовое поле: изменение меньше порога не в счёт
+//	diff:"history,key=LABEL_1"     - имя поля в SimHistory.Field (по умолчанию - колонка в верхнем регистре)
+//
+// Поле без тега пишется в историю: новое поле провайдера не перезаписывается молча.
+// Все записи SimHistory в syncer и worker создаются через SimHistoryBuilder.
+
+// DiffPolicy - что делать с изменением поля SimCard
+type DiffPolicy string
+
+const (
+	DiffHistory DiffPolicy = "history"
+	DiffUpdate  DiffPolicy = "update"
+	DiffIgnore  DiffPolicy = "ignore"
+)
+
+// SimField - поле SimCard в реестре diff
+type SimField struct {
+	Name      string // Имя поля в структуре
+	Column    string // Колонка БД
+	Key       string // SimHistory.Field: STATUS, RATE_PLAN, LABEL_1...
+	Policy    DiffPolicy
+	Threshold float64 // Минимальное изменение числового поля (0 - любое)
+
+	index int
+}
+
+var simFields, simFieldIndex = buildSimFields()
+
+func buildSimFields() ([]SimField, map[string]int) {
+	naming := schema.NamingStrategy{}
+	t := reflect.TypeOf(SimCard{})
+
+	var fields []SimField
+	index := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous || !f.IsExported() {
+			continue // gorm.Model: ID и даты ведёт GORM
+		}
+
+		field := SimField{Name: f.Name, Column: naming.ColumnName("", f.Name), Policy: DiffHistory, index: i}
+		field.Key = strings.ToUpper(field.Column)
+		if tag, ok := f.Tag.Lookup("diff"); ok {
+			parts := strings.Split(tag, ",")
+			field.Policy = DiffPolicy(strings.TrimSpace(parts[0]))
+			switch field.Policy {
+			case DiffHistory, DiffUpdate, DiffIgnore:
+			default:
+				panic(fmt.Sprintf("models: SimCard.%s: unknown diff policy %q", f.Name, parts[0]))
+			}
+			for _, opt := range parts[1:] {
+				name, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+				switch name {
+				case "key":
+					field.Key = value
+				case "threshold":
+					threshold, err := strconv.ParseFloat(value, 64)
+					if err != nil {
+						panic(fmt.Sprintf("models: SimCard.%s: invalid diff threshold %q", f.Name, value))
+					}
+					field.Threshold = threshold
+				default:
+					panic(fmt.Sprintf("models: SimCard.%s: unknown diff option %q", f.Name, opt))
+				}
+			}
+		}
+
+		index[field.Key] = len(fields)
+		index[field.Column] = len(fields)
+		index[strings.ToLower(field.Key)] = len(fields)
+		fields = append(fields, field)
+	}
+	return fields, index
+}
+
+// SimFields - реестр полей SimCard с политиками diff
+func SimFields() []SimField {
+	return append([]SimField(nil), simFields...)
+}
+
+// LookupSimField ищет поле по ключу истории ("LABEL_1"), колонке ("label1") или ключу в нижнем регистре ("label_1")
+func LookupSimField(name string) (SimField, bool) {
+	i, ok := simFieldIndex[name]
+	if !ok {
+		i, ok = simFieldIndex[strings.ToUpper(name)]
+	}
+	if !ok {
+		return SimField{}, false
+	}
+	return simFields[i], true
+}
+
+// FieldValue - значение поля SimCard в виде для истории (пусто - поля нет в реестре)
+func (sim SimCard) FieldValue(name string) string {
+	f, ok := LookupSimField(name)
+	if !ok {
+		return ""
+	}
+	return formatSimValue(reflect.ValueOf(sim).Field(f.index))
+}
+
+// HistoryValues - значения полей с политикой DiffHistory в порядке реестра
+func (sim SimCard) HistoryValues() []string {
+	v := reflect.ValueOf(sim)
+	values := make([]string, 0, len(simFields))
+	for _, f := range simFields {
+		if f.Policy == DiffHistory {
+			values = append(values, formatSimValue(v.Field(f.index)))
+		}
+	}
+	return values
+}
+
+// CopyField копирует поле реестра из from; false - поля нет в реестре
+func (sim *SimCard) CopyField(name string, from SimCard) bool {
+	f, ok := LookupSimField(name)
+	if !ok {
+		return false
+	}
+	reflect.ValueOf(sim).Elem().Field(f.index).Set(reflect.ValueOf(from).Field(f.index))
+	return true
+}
+
+// changed сравнивает значения поля с учётом порога
+func (f SimField) changed(old, new reflect.Value) bool {
+	switch old.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return f.exceeds(math.Abs(new.Float() - old.Float()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.exceeds(math.Abs(float64(new.Int() - old.Int())))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return f.exceeds(math.Abs(float64(new.Uint()) - float64(old.Uint())))
+	}
+	if o, ok := old.Interface().(time.Time); ok {
+		return !o.Equal(new.Interface().(time.Time))
+	}
+	return formatSimValue(old) != formatSimValue(new)
+}
+
+func (f SimField) exceeds(delta float64) bool {
+	if f.Threshold > 0 {
+		return delta >= f.Threshold
+	}
+	return delta != 0
+}
+
+func formatSimValue(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case string:
+		return x
+	case bool:
+		return strconv.FormatBool(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format(time.RFC3339)
+	case *time.Time:
+		if x == nil {
+			return ""
+		}
+		return x.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// ─── DIFF ───────────────────────────────────────────────────
+
+// FieldChange - изменившееся поле SimCard
+type FieldChange struct {
+	SimField
+	Old string
+	New string
+}
+
+// SimDiff - изменения SimCard по реестру (поля DiffIgnore не входят)
+type SimDiff []FieldChange
+
+// DiffSimCards сравнивает локальную SIM с новыми данными
+func DiffSimCards(old, new SimCard) SimDiff {
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(new)
+	var diff SimDiff
+	for _, f := range simFields {
+		if f.Policy == DiffIgnore {
+			continue
+		}
+		o, n := ov.Field(f.index), nv.Field(f.index)
+		if f.changed(o, n) {
+			diff = append(diff, FieldChange{SimField: f, Old: formatSimValue(o), New: formatSimValue(n)})
+		}
+	}
+	return diff
+}
+
+// Changed - есть изменения, которые надо сохранить
+func (d SimDiff) Changed() bool {
+	return len(d) > 0
+}
+
+// Has - изменилось ли поле (ключ истории или колонка)
+func (d SimDiff) Has(name string) bool {
+	f, ok := LookupSimField(name)
+	if !ok {
+		return false
+	}
+	for _, ch := range d {
+		if ch.Key == f.Key {
+			return true
+		}
+	}
+	return false
+}
+
+// ─── ИСТОРИЯ ────────────────────────────────────────────────
+
+// SimHistoryBuilder собирает записи SimHistory одной SIM
+type SimHistoryBuilder struct {
+	base    SimHistory
+	entries []SimHistory
+}
+
+// NewSimHistory начинает записи истории SIM; simID может быть 0, если SIM нет в локальной БД
+func NewSimHistory(simID uint, msisdn, source string) *SimHistoryBuilder {
+	return &SimHistoryBuilder{base: SimHistory{SimID: simID, MSISDN: msisdn, Source: source}}
+}
+
+// ByTask связывает записи с задачей очереди
+func (b *SimHistoryBuilder) ByTask(taskID uint) *SimHistoryBuilder {
+	id := taskID
+	b.base.TaskID = &id
+	b.base.ChangedBy = "system"
+	return b
+}
+
+// Event - событие SIM (CREATED, DELETED, TASK_COMPLETED...); field - произвольный текст
+func (b *SimHistoryBuilder) Event(action, field, oldValue, newValue string) *SimHistoryBuilder {
+	entry := b.base
+	entry.Action = action
+	entry.Field = field
+	entry.OldValue = oldValue
+	entry.NewValue = newValue
+	b.entries = append(b.entries, entry)
+	return b
+}
+
+// Change - изменение поля SimCard; имя поля приводится к ключу реестра, пустой action - CHANGE_<KEY>
+func (b *SimHistoryBuilder) Change(action, field, oldValue, newValue string) *SimHistoryBuilder {
+	if f, ok := LookupSimField(field); ok {
+		field = f.Key
+	}
+	if action == "" {
+		action = "CHANGE_" + field
+	}
+	return b.Event(action, field, oldValue, newValue)
+}
+
+// Diff добавляет изменения полей с политикой DiffHistory
+func (b *SimHistoryBuilder) Diff(diff SimDiff) *SimHistoryBuilder {
+	for _, ch := range diff {
+		if ch.Policy == DiffHistory {
+			b.Change("", ch.Key, ch.Old, ch.New)
+		}
+	}
+	return b
+}
+
+// Entries - собранные записи (для пакетной записи в транзакции)
+func (b *SimHistoryBuilder) Entries() []SimHistory {
+	return b.entries
+}
+
+// Save пишет собранные записи; без записей ничего не делает
+func (b *SimHistoryBuilder) Save(db *gorm.DB) error {
+	if len(b.entries) == 0 {
+		return nil
+	}
+	return db.Create(&b.entries).Error
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffSimCards(t *testing.T) {
+	base := SimCard{
+		MSISDN:      "972500000001",
+		Status:      "Activated",
+		RatePlan:    "Basic",
+		ICCID:       "8997200000000000001",
+		UsageMB:     10.2,
+		LastSession: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name   string
+		modify func(sim *SimCard)
+		want   []FieldChange // Только Key, Policy, Old, New
+	}{
+		{
+			name:   "no changes",
+			modify: func(sim *SimCard) {},
+		},
+		{
+			name:   "status goes to history",
+			modify: func(sim *SimCard) { sim.Status = "Suspended" },
+			want:   []FieldChange{{SimField: SimField{Key: "STATUS", Policy: DiffHistory}, Old: "Activated", New: "Suspended"}},
+		},
+		{
+			name:   "label and iccid keep registry keys",
+			modify: func(sim *SimCard) { sim.ICCID = "8997200000000000002"; sim.Label1 = "site A" },
+			want: []FieldChange{
+				{SimField: SimField{Key: "ICCID", Policy: DiffHistory}, Old: "8997200000000000001", New: "8997200000000000002"},
+				{SimField: SimField{Key: "LABEL_1", Policy: DiffHistory}, Old: "", New: "site A"},
+			},
+		},
+		{
+			name:   "usage below threshold is ignored",
+			modify: func(sim *SimCard) { sim.UsageMB = 10.9 },
+		},
+		{
+			name:   "usage at threshold is an update",
+			modify: func(sim *SimCard) { sim.UsageMB = 11.2 },
+			want:   []FieldChange{{SimField: SimField{Key: "USAGE_MB", Policy: DiffUpdate}, Old: "10.2", New: "11.2"}},
+		},
+		{
+			name:   "same instant in another zone",
+			modify: func(sim *SimCard) { sim.LastSession = sim.LastSession.In(time.FixedZone("IST", 3*60*60)) },
+		},
+		{
+			name: "ignored fields",
+			modify: func(sim *SimCard) {
+				sim.MSISDN = "972500000002"
+				sim.LastSyncAt = time.Now()
+				sim.SyncConflict = "STATUS"
+				sim.ID = 42
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := base
+			tt.modify(&sim)
+			diff := DiffSimCards(base, sim)
+			if diff.Changed() != (len(tt.want) > 0) {
+				t.Fatalf("Changed() = %v, diff %+v", diff.Changed(), diff)
+			}
+			if len(diff) != len(tt.want) {
+				t.Fatalf("got %d changes %+v, want %d", len(diff), diff, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := diff[i]
+				if got.Key != want.Key || got.Policy != want.Policy || got.Old != want.Old || got.New != want.New {
+					t.Errorf("change %d = %s/%s %q -> %q, want %s/%s %q -> %q",
+						i, got.Key, got.Policy, got.Old, got.New, want.Key, want.Policy, want.Old, want.New)
+				}
+			}
+		})
+	}
+}
+
+func TestSimDiffHas(t *testing.T) {
+	diff := DiffSimCards(SimCard{Label2: "a"}, SimCard{Label2: "b"})
+	for _, name := range []string{"LABEL_2", "label2", "label_2"} {
+		if !diff.Has(name) {
+			t.Errorf("Has(%q) = false, want true", name)
+		}
+	}
+	if diff.Has("STATUS") || diff.Has("no_such_field") {
+		t.Error("Has reports fields that did not change")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package models
+
+import "time"
+
+// ═══════════════════════════════════════════════════════════
+// SYNC STATE
+// ═══════════════════════════════════════════════════════════
+
+// SyncPage - состояние одной страницы getProvisioningData аккаунта после последней проверки.
+// Хэш содержимого позволяет инкрементальной синхронизации пропускать неизменные диапазоны,
+// а первая/последняя SIM и Total - заметить, что границы страниц сдвинулись.
+type SyncPage struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	AccountID uint `gorm:"uniqueIndex:idx_sync_page" json:"account_id"`
+	PageStart int  `gorm:"uniqueIndex:idx_sync_page" json:"page_start"`
+	PageSize  int  `json:"page_size"`
+	Total     int  `json:"total"` // Сколько абонентов сообщил провайдер при проверке
+
+	Hash        string `gorm:"size:64" json:"hash"`
+	FirstMSISDN string `gorm:"size:32" json:"first_msisdn"`
+	LastMSISDN  string `gorm:"size:32" json:"last_msisdn"`
+
+	// Проверок подряд без изменений; стабильные страницы инкрементальный цикл не запрашивает
+	Unchanged int       `json:"unchanged"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// ═══════════════════════════════════════════════════════════
+// SYNC RUNS
+// ═══════════════════════════════════════════════════════════
+
+// Режим цикла синхронизации
+const (
+	SyncModeFull        = "full"
+	SyncModeIncremental = "incremental"
+	SyncModeScoped      = "scoped" // Частичная: список MSISDN, CUSTOMER_NUMBER или фильтр getProvisioningData
+)
+
+// Кто запустил цикл
+const (
+	SyncTriggerScheduled = "scheduled" // Плановый цикл syncer.Start
+	SyncTriggerManual    = "manual"    // POST /api/v1/sync/full, /sync/scoped
+	SyncTriggerTask      = "task"      // Отложенная синхронизация SIM после задачи очереди
+)
+
+// SyncRunStatus - статус цикла синхронизации
+type SyncRunStatus string
+
+const (
+	SyncRunRunning     SyncRunStatus = "RUNNING"
+	SyncRunSuccess     SyncRunStatus = "SUCCESS"
+	SyncRunFailed      SyncRunStatus = "FAILED"      // Хотя бы один аккаунт или страница завершились ошибкой
+	SyncRunCancelled   SyncRunStatus = "CANCELLED"   // Отмена, переключение upstream или shutdown
+	SyncRunInterrupted SyncRunStatus = "INTERRUPTED" // Сервер остановился, не дописав итог
+)
+
+// SyncRun - итог одного цикла синхронизации (плановой или ручной) с метриками
+type SyncRun struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Trigger   string        `gorm:"column:sync_trigger;size:16;index" json:"trigger"`
+	Mode      string        `gorm:"size:16;index" json:"mode"`
+	Reason    string        `gorm:"size:128" json:"reason"` // Почему выбран режим
+	Status    SyncRunStatus `gorm:"size:16;index" json:"status"`
+	AccountID uint          `gorm:"index" json:"account_id"` // 0 - все включённые аккаунты
+	Accounts  int           `json:"accounts"`
+	Upstream  string        `gorm:"size:255" json:"upstream"` // BaseURL аккаунтов цикла
+
+	StartedAt  time.Time  `gorm:"index" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	DurationMs int64      `json:"duration_ms"`
+
+	PagesFetched   int `json:"pages_fetched"`   // Запрошено у провайдера
+	PagesUnchanged int `json:"pages_unchanged"` // Запрошены, хэш не изменился
+	PagesSkipped   int `json:"pages_skipped"`   // Стабильные, не запрашивались (инкрементальный цикл)
+	FullFallbacks  int `json:"full_fallbacks"`  // Аккаунты, ушедшие в полный обход из инкрементального
+
+	Processed   int `json:"processed"` // Записей получено и сверено
+	Created     int `json:"created"`
+	Updated     int `json:"updated"`
+	Unchanged   int `json:"unchanged"`
+	HistoryRows int `json:"history_rows"`
+
+	// Удалённые у провайдера: впервые не найдены, удалены после grace-периода, вернулись
+	Missing  int `json:"missing"`
+	Deleted  int `json:"deleted"`
+	Restored int `json:"restored"`
+
+	// SIM, у которых поля задач удержаны до подтверждения провайдером (services/conflicts.go)
+	Conflicts int `json:"conflicts"`
+
+	Errors int    `json:"errors"`
+	Error  string `gorm:"type:text" json:"error,omitempty"` // Последняя ошибка
+
+	// Задержка getProvisioningData: число запросов, суммарная, средняя и максимальная
+	UpstreamCalls     int   `json:"upstream_calls"`
+	UpstreamLatencyMs int64 `json:"upstream_latency_ms"`
+	AvgLatencyMs      int64 `json:"avg_latency_ms"`
+	MaxLatencyMs      int64 `json:"max_latency_ms"`
+
+	// Полный обход дошёл до конца без ошибок - локальная БД совпала с upstream на момент цикла
+	Complete bool `gorm:"index" json:"complete"`
+}
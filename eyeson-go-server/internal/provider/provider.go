@@ -0,0 +1,270 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"eyeson-go-server/internal/models"
+)
+
+// ═══════════════════════════════════════════════════════════
+// UPSTREAM PROVIDER (MNO) ABSTRACTION
+// ═══════════════════════════════════════════════════════════
+
+// Provider - оператор связи, из которого синхронизируются SIM и через который
+// выполняются изменения. Syncer, Worker и Reconciler работают только через этот интерфейс.
+//
+// Модели запросов/ответов - models.* (формат EyesOnT v1.5.2); адаптер другого
+// оператора конвертирует свои ответы в них.
+type Provider interface {
+	// Name - имя, под которым провайдер зарегистрирован (пишется в SimCard.Provider)
+	Name() string
+
+	// ListSubscribers - постраничный список абонентов (getProvisioningData)
+	ListSubscribers(ctx context.Context, start, limit int, search []models.SearchParam) (*models.GetProvisioningDataResponse, error)
+
+	// UpdateProvisioning - изменение одного параметра у группы абонентов (updateProvisioningData).
+	// actionType - одно из Action*; RequestId в ответе подтверждается через ListJobs.
+	UpdateProvisioning(ctx context.Context, msisdns []string, actionType, targetValue string) (*models.BulkUpdateResponse, error)
+
+	// ListJobs - задачи провизионирования и результат по каждому абоненту (getProvisioningJobList)
+	ListJobs(ctx context.Context, start, limit, jobID int, jobStatus string) (*models.GetJobsResponse, error)
+
+	// GetParameters - каталог параметров и допустимых значений (getProvisioningParameterList)
+	GetParameters(ctx context.Context) (*models.GetProvisioningParameterListResponse, error)
+
+	// Health - nil, если провайдер доступен
+	Health(ctx context.Context) error
+}
+
+// StatusLookup - необязательное расширение: быстрый запрос текущего статуса одной SIM.
+// Worker использует его для предварительной проверки смены статуса.
+type StatusLookup interface {
+	GetSimStatus(ctx context.Context, msisdn string) (string, error)
+}
+
+// Session - необязательное расширение для провайдеров с сессионной авторизацией
+type Session interface {
+	Login(ctx context.Context) error
+	Logout(ctx context.Context) error
+}
+
+// Availability - необязательное расширение: провайдер сообщает, что сейчас запросы
+// к нему бессмысленны (например, открыт circuit breaker). Worker и syncer ждут.
+type Availability interface {
+	Available() bool
+}
+
+// IsAvailable - false только если провайдер реализует Availability и сообщает о недоступности
+func IsAvailable(p Provider) bool {
+	if a, ok := p.(Availability); ok {
+		return a.Available()
+	}
+	return true
+}
+
+// Chunker - необязательное расширение: сколько абонентов провайдер принимает в одном action.
+// Worker делит большие списки на части этого размера и отслеживает каждую отдельно.
+type Chunker interface {
+	MaxSubscribersPerAction() int
+}
+
+// Change - одно действие набора изменений: actionType (Action*) и новое значение
+type Change struct {
+	ActionType  string `json:"action_type"`
+	TargetValue string `json:"target_value"`
+}
+
+// ChangeSetUpdater - необязательное расширение: несколько действий над одними абонентами
+// в одном запросе (массив actions updateProvisioningData), один requestId на весь набор.
+type ChangeSetUpdater interface {
+	UpdateProvisioningSet(ctx context.Context, msisdns []string, changes []Change) (*models.BulkUpdateResponse, error)
+}
+
+// DefaultMaxSubscribersPerAction - размер части, если провайдер не сообщает свой
+const DefaultMaxSubscribersPerAction = 100
+
+// MaxSubscribers возвращает размер части для провайдера
+func MaxSubscribers(p Provider) int {
+	if c, ok := p.(Chunker); ok {
+		if n := c.MaxSubscribersPerAction(); n > 0 {
+			return n
+		}
+	}
+	return DefaultMaxSubscribersPerAction
+}
+
+// SplitSubscribers делит список абонентов на части не больше size, сохраняя порядок
+func SplitSubscribers(msisdns []string, size int) [][]string {
+	if size <= 0 {
+		size = DefaultMaxSubscribersPerAction
+	}
+	chunks := make([][]string, 0, (len(msisdns)+size-1)/size)
+	for start := 0; start < len(msisdns); start += size {
+		end := start + size
+		if end > len(msisdns) {
+			end = len(msisdns)
+		}
+		chunks = append(chunks, msisdns[start:end])
+	}
+	return chunks
+}
+
+// Типы действий UpdateProvisioning (имена по PDF v1.5.2, раздел 4.4)
+const (
+	ActionSimStateChange = "SIM_STATE_CHANGE"
+	ActionRatePlanChange = "RATE_PLAN_CHANGE"
+	ActionSimSwap        = "SIM_SWAP"
+	ActionCustomerLabel1 = "CUSTOMER_LABEL_1"
+	ActionCustomerLabel2 = "CUSTOMER_LABEL_2"
+	ActionCustomerLabel3 = "CUSTOMER_LABEL_3"
+)
+
+const labelActionPrefix = "CUSTOMER_LABEL_"
+
+// LabelAction возвращает действие для поля метки: label_1/label_2/label_3 (по умолчанию label_1)
+func LabelAction(field string) string {
+	switch field {
+	case "label_2":
+		return ActionCustomerLabel2
+	case "label_3":
+		return ActionCustomerLabel3
+	default:
+		return ActionCustomerLabel1
+	}
+}
+
+// IsLabelAction - действие меняет одну из клиентских меток
+func IsLabelAction(actionType string) bool {
+	return strings.HasPrefix(actionType, labelActionPrefix)
+}
+
+// LabelField - поле метки (label_1/label_2/label_3) для действия CUSTOMER_LABEL_N
+func LabelField(actionType string) string {
+	return "label_" + strings.TrimPrefix(actionType, labelActionPrefix)
+}
+
+// ChangeField - поле SIM (status, rate_plan, label_N), которое меняет действие;
+// "" - действие не меняет поле SIM
+func ChangeField(actionType string) string {
+	switch {
+	case actionType == ActionSimStateChange:
+		return "status"
+	case actionType == ActionRatePlanChange:
+		return "rate_plan"
+	case IsLabelAction(actionType):
+		return LabelField(actionType)
+	}
+	return ""
+}
+
+// ─── РЕЕСТР ────────────────────────────────────────────────
+
+// Config - параметры подключения, общие для всех провайдеров
+type Config struct {
+	BaseURL     string
+	Username    string
+	Password    string
+	ApiDelayMs  int
+	InsecureTLS bool
+
+	// Исходящий прокси, корневые сертификаты и клиентский сертификат (mTLS)
+	ProxyURL       string
+	NoProxy        string
+	CAFile         string
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// Лимитер клиента: RatePerSec = 0 - выводится из ApiDelayMs
+	RatePerSec float64
+	RateBurst  int
+
+	// Максимум абонентов в одном action (0 - DefaultMaxSubscribersPerAction)
+	BulkChunkSize int
+
+	// 0 - значения провайдера по умолчанию
+	SessionMaxAge  time.Duration
+	RequestTimeout time.Duration
+}
+
+// Factory создаёт экземпляр провайдера
+type Factory func(cfg Config) (Provider, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// active - провайдер, с которым работает сервер (main по EYESON_PROVIDER, затем переключение upstream)
+var (
+	activeMu sync.RWMutex
+	active   Provider
+)
+
+// Active возвращает текущего провайдера; nil - ещё не выбран
+func Active() Provider {
+	activeMu.RLock()
+	defer activeMu.RUnlock()
+	return active
+}
+
+// SetActive делает p текущим провайдером. Запросы, уже получившие прежнего, доработают с ним.
+func SetActive(p Provider) {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	active = p
+}
+
+// Register регистрирует фабрику провайдера под именем. Вызывается из init() адаптера.
+func Register(name string, factory Factory) {
+	name = normalizeName(name)
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory == nil {
+		panic("provider: Register factory is nil for " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic("provider: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+// New создаёт провайдера по зарегистрированному имени
+func New(name string, cfg Config) (Provider, error) {
+	name = normalizeName(name)
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (registered: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg)
+}
+
+// Names возвращает имена зарегистрированных провайдеров
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -17,16 +17,19 @@ import (
 type EventType string
 
 const (
-	EventSimCreated     EventType = "SIM_CREATED"
-	EventSimUpdated     EventType = "SIM_UPDATED"
-	EventSimDeleted     EventType = "SIM_DELETED"
-	EventSyncStarted    EventType = "SYNC_STARTED"
-	EventSyncCompleted  EventType = "SYNC_COMPLETED"
-	EventSyncFailed     EventType = "SYNC_FAILED"
-	EventTaskQueued     EventType = "TASK_QUEUED"
-	EventTaskProcessing EventType = "TASK_PROCESSING"
-	EventTaskCompleted  EventType = "TASK_COMPLETED"
-	EventTaskFailed     EventType = "TASK_FAILED"
+	EventSimCreated           EventType = "SIM_CREATED"
+	EventSimUpdated           EventType = "SIM_UPDATED"
+	EventSimDeleted           EventType = "SIM_DELETED"
+	EventSyncStarted          EventType = "SYNC_STARTED"
+	EventSyncCompleted        EventType = "SYNC_COMPLETED"
+	EventSyncFailed           EventType = "SYNC_FAILED"
+	EventTaskQueued           EventType = "TASK_QUEUED"
+	EventTaskProcessing       EventType = "TASK_PROCESSING"
+	EventTaskAwaitingProvider EventType = "TASK_AWAITING_PROVIDER"
+	EventTaskCompleted        EventType = "TASK_COMPLETED"
+	EventTaskFailed           EventType = "TASK_FAILED"
+	EventUpstreamCircuit      EventType = "UPSTREAM_CIRCUIT_CHANGED"
+	EventUpstreamChanged      EventType = "UPSTREAM_CHANGED"
 )
 
 // Event represents a system event
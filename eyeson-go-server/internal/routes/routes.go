@@ -90,6 +90,21 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	simsWrite.Post("/update", handlers.UpdateSim)
 	simsWrite.Post("/status", handlers.ChangeStatus) // Single SIM status change with queue fallback
 	simsWrite.Post("/bulk-status", handlers.BulkChangeStatus)
+	simsWrite.Post("/rate-plan", handlers.ChangeRatePlan) // Single or bulk RATE_PLAN_CHANGE
+	simsWrite.Post("/swap", handlers.SwapSim)             // SIM_SWAP with ICCID from spare inventory
+	simsWrite.Post("/change-set", handlers.ChangeSet)     // Several actions in one updateProvisioningData
+
+	// Spare SIM inventory (ICCIDs for SIM_SWAP) - Admin+Moderator can read, Admin can add
+	inventory := api.Group("/inventory")
+	inventory.Use(handlers.JWTMiddleware)
+	inventory.Get("/sims", handlers.RequireAnyRole("Administrator", "Moderator"), handlers.GetInventory)
+	inventory.Post("/sims", handlers.RequireRole("Administrator"), handlers.AddInventory)
+
+	// Provisioning parameter catalog - all roles read, Admin can force refresh
+	catalog := api.Group("/catalog")
+	catalog.Use(handlers.JWTMiddleware)
+	catalog.Get("", handlers.GetCatalog)
+	catalog.Post("/refresh", handlers.RequireRole("Administrator"), handlers.RefreshCatalog)
 
 	// Stats routes (protected - All roles)
 	stats := api.Group("/stats")
@@ -113,14 +128,32 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	upstream.Get("", handlers.GetUpstream)
 	upstream.Put("", handlers.SetUpstream)
 
+	// Upstream accounts (Admin only) - credentials are write-only
+	accounts := api.Group("/accounts")
+	accounts.Use(handlers.JWTMiddleware)
+	accounts.Use(handlers.RequireRole("Administrator"))
+	accounts.Get("", handlers.GetAccounts)
+	accounts.Post("", handlers.CreateAccount)
+	accounts.Put("/:id", handlers.UpdateAccount)
+	accounts.Delete("/:id", handlers.DeleteAccount)
+
 	// Sync status - available to all authenticated users
 	api.Get("/sync/status", handlers.JWTMiddleware, handlers.GetManualSyncStatus)
+	api.Get("/sync/runs", handlers.JWTMiddleware, handlers.GetSyncRuns)
 
 	// Manual sync trigger (Admin only) - pulls latest data from Pelephone into local DB
 	syncAdmin := api.Group("/sync")
 	syncAdmin.Use(handlers.JWTMiddleware)
 	syncAdmin.Use(handlers.RequireRole("Administrator"))
 	syncAdmin.Post("/full", handlers.TriggerManualFullSync)
+	syncAdmin.Post("/cancel", handlers.CancelManualSync)
+	syncAdmin.Post("/pause", handlers.PauseSync)
+	syncAdmin.Post("/resume", handlers.ResumeSync)
+	syncAdmin.Put("/schedule", handlers.UpdateSyncSchedule)
+	syncAdmin.Post("/drift", handlers.RunSyncDrift)
+	syncAdmin.Get("/drift", handlers.GetSyncDrift)
+	syncAdmin.Get("/drift/status", handlers.GetSyncDriftStatus)
+	syncAdmin.Post("/scoped", handlers.TriggerScopedSync)
 
 	// Jobs routes (protected - All roles)
 	jobs := api.Group("/jobs")
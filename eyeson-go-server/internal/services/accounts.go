@@ -0,0 +1,572 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"eyeson-go-server/internal/config"
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+
+	"gorm.io/gorm"
+)
+
+// ═══════════════════════════════════════════════════════════
+// UPSTREAM ACCOUNT SERVICE
+// ═══════════════════════════════════════════════════════════
+
+// AccountService хранит аккаунты провайдера (пароли зашифрованы) и держит
+// по одному клиенту с собственной сессией на каждый аккаунт
+type AccountService struct {
+	mu        sync.Mutex
+	key       [32]byte
+	defaults  provider.Config
+	providers map[uint]provider.Provider
+}
+
+// Accounts - глобальный экземпляр сервиса аккаунтов
+var Accounts = &AccountService{}
+
+var (
+	ErrAccountNotFound = errors.New("upstream account not found")
+	ErrAccountDisabled = errors.New("upstream account is disabled")
+)
+
+// sealedPrefix - версия формата зашифрованного пароля
+const sealedPrefix = "v1:"
+
+// DefaultAccountName - аккаунт, создаваемый из EYESON_API_USERNAME / EYESON_API_PASSWORD
+const DefaultAccountName = "default"
+
+// Configure задаёт ключ шифрования и общие параметры клиентов, при первом запуске
+// создаёт аккаунт по умолчанию из конфигурации. Вызывается в main до запуска syncer/worker.
+func (s *AccountService) Configure(cfg *config.Config, baseURL string) error {
+	secret := cfg.CredentialsKey
+	if secret == "" {
+		// В prod config.Validate не пропустит пустой ключ; в dev - громкое предупреждение
+		log.Println("[Accounts] ════════════════════════════════════════════════════════════")
+		log.Println("[Accounts] WARNING: EYESON_CREDENTIALS_KEY is not set!")
+		log.Println("[Accounts] Upstream passwords are encrypted with a key derived from JWT_SECRET:")
+		log.Println("[Accounts] rotating JWT_SECRET will make stored passwords unreadable.")
+		log.Println("[Accounts] Set EYESON_CREDENTIALS_KEY to the current JWT_SECRET value to keep them.")
+		log.Println("[Accounts] ════════════════════════════════════════════════════════════")
+		secret = cfg.JwtSecret
+	}
+
+	s.mu.Lock()
+	s.key = sha256.Sum256([]byte(secret))
+	s.defaults = provider.Config{
+		BaseURL:        baseURL,
+		ApiDelayMs:     cfg.ApiDelayMs,
+		RatePerSec:     float64(cfg.ApiRatePerSec),
+		RateBurst:      cfg.ApiRateBurst,
+		BulkChunkSize:  cfg.ApiBulkChunkSize,
+		InsecureTLS:    cfg.ApiInsecureTLS,
+		ProxyURL:       cfg.ApiProxyURL,
+		NoProxy:        cfg.ApiNoProxy,
+		CAFile:         cfg.ApiCAFile,
+		ClientCertFile: cfg.ApiClientCertFile,
+		ClientKeyFile:  cfg.ApiClientKeyFile,
+		SessionMaxAge:  time.Duration(cfg.ApiSessionMaxAgeMin) * time.Minute,
+		RequestTimeout: time.Duration(cfg.ApiRequestTimeoutSec) * time.Second,
+	}
+	s.providers = make(map[uint]provider.Provider)
+	s.mu.Unlock()
+
+	return s.seedDefault(cfg)
+}
+
+// seedDefault создаёт аккаунт по умолчанию и привязывает к нему SIM и задачи без аккаунта.
+// Если аккаунты уже есть, EYESON_API_* не применяются - только сверяются с аккаунтом по умолчанию.
+func (s *AccountService) seedDefault(cfg *config.Config) error {
+	var count int64
+	if err := database.DB.Model(&models.UpstreamAccount{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		s.checkDefault(cfg)
+		return nil
+	}
+
+	sealed, err := s.seal(cfg.ApiPassword)
+	if err != nil {
+		return err
+	}
+	account := models.UpstreamAccount{
+		Name:        DefaultAccountName,
+		Provider:    cfg.Provider,
+		Username:    cfg.ApiUsername,
+		PasswordEnc: sealed,
+		Enabled:     true,
+		IsDefault:   true,
+	}
+	if err := database.DB.Create(&account).Error; err != nil {
+		return fmt.Errorf("failed to create default upstream account: %w", err)
+	}
+
+	database.DB.Model(&models.SimCard{}).Where("account_id = 0 OR account_id IS NULL").Update("account_id", account.ID)
+	database.DB.Model(&models.SyncTaskExtended{}).Where("account_id = 0 OR account_id IS NULL").Update("account_id", account.ID)
+
+	log.Printf("[Accounts] Created default upstream account #%d (%s, user=%s)", account.ID, account.Provider, account.Username)
+	return nil
+}
+
+// checkDefault предупреждает, что EYESON_API_* расходятся с сохранённым аккаунтом по умолчанию:
+// аккаунт меняется через /api/v1/accounts, переменные окружения действуют только при первом запуске
+func (s *AccountService) checkDefault(cfg *config.Config) {
+	var account models.UpstreamAccount
+	if err := database.DB.Where("is_default = ?", true).First(&account).Error; err != nil {
+		return
+	}
+
+	var differs []string
+	if account.Provider != cfg.Provider {
+		differs = append(differs, "provider")
+	}
+	if account.Username != cfg.ApiUsername {
+		differs = append(differs, "EYESON_API_USERNAME")
+	}
+	if password, err := s.open(account.PasswordEnc); err != nil {
+		log.Printf("[Accounts] WARNING: default upstream account #%d: %v", account.ID, err)
+	} else if password != cfg.ApiPassword {
+		differs = append(differs, "EYESON_API_PASSWORD")
+	}
+	if len(differs) > 0 {
+		log.Printf("[Accounts] WARNING: default upstream account #%d (%s) differs from %s; the stored account is used - update it via /api/v1/accounts",
+			account.ID, account.Name, strings.Join(differs, ", "))
+	}
+}
+
+// ─── CRUD ──────────────────────────────────────────────────
+
+// AccountInput - поля для создания/изменения аккаунта (nil - не менять)
+type AccountInput struct {
+	Name      *string `json:"name"`
+	Provider  *string `json:"provider"`
+	BaseURL   *string `json:"base_url"`
+	Username  *string `json:"username"`
+	Password  *string `json:"password"`
+	Enabled   *bool   `json:"enabled"`
+	IsDefault *bool   `json:"is_default"`
+}
+
+// List возвращает все аккаунты
+func (s *AccountService) List() ([]models.UpstreamAccount, error) {
+	var accounts []models.UpstreamAccount
+	err := database.DB.Order("id ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// EnabledAccounts возвращает аккаунты, участвующие в синхронизации
+func (s *AccountService) EnabledAccounts() ([]models.UpstreamAccount, error) {
+	var accounts []models.UpstreamAccount
+	err := database.DB.Where("enabled = ?", true).Order("id ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// Get возвращает аккаунт по ID
+func (s *AccountService) Get(id uint) (*models.UpstreamAccount, error) {
+	var account models.UpstreamAccount
+	if err := database.DB.First(&account, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Default возвращает аккаунт по умолчанию (или первый включённый)
+func (s *AccountService) Default() (*models.UpstreamAccount, error) {
+	var account models.UpstreamAccount
+	err := database.DB.Where("is_default = ? AND enabled = ?", true, true).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = database.DB.Where("enabled = ?", true).Order("id ASC").First(&account).Error
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Create создаёт аккаунт; name, username и password обязательны
+func (s *AccountService) Create(in AccountInput) (*models.UpstreamAccount, error) {
+	account := models.UpstreamAccount{Provider: s.defaultProviderName(), Enabled: true}
+	if in.Password == nil || *in.Password == "" {
+		return nil, fmt.Errorf("password is required")
+	}
+	if err := s.apply(&account, in); err != nil {
+		return nil, err
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if account.IsDefault {
+			if err := tx.Model(&models.UpstreamAccount{}).Where("is_default = ?", true).Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&account).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+
+	log.Printf("[Accounts] Created upstream account #%d %q (%s)", account.ID, account.Name, account.Provider)
+	return &account, nil
+}
+
+// Update изменяет аккаунт; клиент аккаунта пересоздаётся при следующем запросе
+func (s *AccountService) Update(id uint, in AccountInput) (*models.UpstreamAccount, error) {
+	account, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(account, in); err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if account.IsDefault {
+			if err := tx.Model(&models.UpstreamAccount{}).Where("is_default = ? AND id <> ?", true, account.ID).Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(account).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update account: %w", err)
+	}
+
+	s.invalidate(id)
+	return account, nil
+}
+
+// Delete удаляет аккаунт без SIM. Аккаунт с SIM можно только выключить.
+func (s *AccountService) Delete(id uint) error {
+	account, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if account.IsDefault {
+		return fmt.Errorf("default account cannot be deleted")
+	}
+
+	var simCount int64
+	database.DB.Model(&models.SimCard{}).Where("account_id = ?", id).Count(&simCount)
+	if simCount > 0 {
+		return fmt.Errorf("account owns %d SIMs; disable it instead", simCount)
+	}
+
+	if err := database.DB.Delete(&models.UpstreamAccount{}, id).Error; err != nil {
+		return err
+	}
+	s.invalidate(id)
+	return nil
+}
+
+func (s *AccountService) apply(account *models.UpstreamAccount, in AccountInput) error {
+	if in.Name != nil {
+		account.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.Provider != nil {
+		account.Provider = strings.ToLower(strings.TrimSpace(*in.Provider))
+	}
+	if in.BaseURL != nil {
+		account.BaseURL = strings.TrimRight(strings.TrimSpace(*in.BaseURL), "/")
+	}
+	if in.Username != nil {
+		account.Username = strings.TrimSpace(*in.Username)
+	}
+	if in.Password != nil && *in.Password != "" {
+		sealed, err := s.seal(*in.Password)
+		if err != nil {
+			return err
+		}
+		account.PasswordEnc = sealed
+	}
+	if in.Enabled != nil {
+		account.Enabled = *in.Enabled
+	}
+	if in.IsDefault != nil {
+		account.IsDefault = *in.IsDefault
+	}
+
+	if account.Name == "" || account.Username == "" {
+		return fmt.Errorf("name and username are required")
+	}
+	if !isRegisteredProvider(account.Provider) {
+		return fmt.Errorf("unknown provider %q (registered: %s)", account.Provider, strings.Join(provider.Names(), ", "))
+	}
+	if account.IsDefault && !account.Enabled {
+		return fmt.Errorf("default account cannot be disabled")
+	}
+	return nil
+}
+
+func isRegisteredProvider(name string) bool {
+	for _, n := range provider.Names() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AccountService) defaultProviderName() string {
+	if account, err := s.Default(); err == nil {
+		return account.Provider
+	}
+	return ""
+}
+
+// ─── КЛИЕНТЫ ПО АККАУНТАМ ──────────────────────────────────
+
+// Provider возвращает клиента аккаунта (создаётся один раз, со своей сессией).
+// accountID = 0 - аккаунт по умолчанию.
+func (s *AccountService) Provider(accountID uint) (provider.Provider, error) {
+	if accountID == 0 {
+		account, err := s.Default()
+		if err != nil {
+			return nil, err
+		}
+		accountID = account.ID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p, ok := s.providers[accountID]; ok {
+		return p, nil
+	}
+
+	account, err := s.Get(accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !account.Enabled {
+		return nil, ErrAccountDisabled
+	}
+
+	p, err := s.newProviderLocked(*account, "")
+	if err != nil {
+		return nil, err
+	}
+	if s.providers == nil {
+		s.providers = make(map[uint]provider.Provider)
+	}
+	s.providers[accountID] = p
+	log.Printf("[Accounts] Client for account #%d %q (%s) created", account.ID, account.Name, account.Provider)
+	return p, nil
+}
+
+// NewProvider создаёт отдельного (не кэшируемого) клиента аккаунта.
+// baseURL, если задан, заменяет адрес аккаунта - ручная синхронизация всегда идёт в Pelephone.
+func (s *AccountService) NewProvider(account models.UpstreamAccount, baseURL string) (provider.Provider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.newProviderLocked(account, baseURL)
+}
+
+func (s *AccountService) newProviderLocked(account models.UpstreamAccount, baseURL string) (provider.Provider, error) {
+	password, err := s.open(account.PasswordEnc)
+	if err != nil {
+		return nil, fmt.Errorf("account #%d: %w", account.ID, err)
+	}
+
+	cfg := s.defaults
+	cfg.Username = account.Username
+	cfg.Password = password
+	if account.BaseURL != "" {
+		cfg.BaseURL = account.BaseURL
+	}
+	if baseURL != "" {
+		cfg.BaseURL = baseURL
+	}
+	return provider.New(account.Provider, cfg)
+}
+
+// Active возвращает уже созданных клиентов аккаунтов (для диагностики)
+func (s *AccountService) Active() map[uint]provider.Provider {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	active := make(map[uint]provider.Provider, len(s.providers))
+	for id, p := range s.providers {
+		active[id] = p
+	}
+	return active
+}
+
+// AccountForMSISDN возвращает аккаунт, которому принадлежит SIM (0 - неизвестна)
+func (s *AccountService) AccountForMSISDN(msisdn string) uint {
+	if msisdn == "" {
+		return 0
+	}
+	var sim models.SimCard
+	if err := database.DB.Select("account_id").Where("msisdn = ? OR cli = ?", msisdn, msisdn).First(&sim).Error; err != nil {
+		return 0
+	}
+	return sim.AccountID
+}
+
+// AccountsForMSISDNs возвращает аккаунты, которым принадлежат SIM (без повторов).
+// Неизвестные SIM относятся к аккаунту 0 - по умолчанию, как и их задачи.
+func (s *AccountService) AccountsForMSISDNs(msisdns []string) []uint {
+	if len(msisdns) == 0 {
+		return nil
+	}
+
+	var sims []models.SimCard
+	if err := database.DB.Select("msisdn", "cli", "account_id").
+		Where("msisdn IN ? OR cli IN ?", msisdns, msisdns).Find(&sims).Error; err != nil {
+		return []uint{0}
+	}
+	owner := make(map[string]uint, len(sims)*2)
+	for _, sim := range sims {
+		owner[sim.MSISDN] = sim.AccountID
+		if sim.CLI != "" {
+			owner[sim.CLI] = sim.AccountID
+		}
+	}
+
+	seen := make(map[uint]bool)
+	var ids []uint
+	for _, msisdn := range msisdns {
+		id := owner[msisdn]
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// invalidate закрывает сессию изменённого/удалённого аккаунта и убирает клиента из кэша
+func (s *AccountService) invalidate(accountID uint) {
+	s.mu.Lock()
+	p, ok := s.providers[accountID]
+	delete(s.providers, accountID)
+	s.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	if session, ok := p.(provider.Session); ok {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := session.Logout(ctx); err != nil {
+				log.Printf("[Accounts] Account #%d: logout failed: %v", accountID, err)
+			}
+		}()
+	}
+}
+
+// SetBaseURL меняет адрес upstream по умолчанию (переключение pelephone/simulator на лету).
+// Клиенты всех аккаунтов пересоздаются при следующем запросе, старые сессии закрываются в фоне.
+func (s *AccountService) SetBaseURL(baseURL string) {
+	s.mu.Lock()
+	s.defaults.BaseURL = baseURL
+	providers := s.providers
+	s.providers = make(map[uint]provider.Provider)
+	s.mu.Unlock()
+
+	for id, p := range providers {
+		session, ok := p.(provider.Session)
+		if !ok {
+			continue
+		}
+		go func(id uint, session provider.Session) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := session.Logout(ctx); err != nil {
+				log.Printf("[Accounts] Account #%d: logout from previous upstream failed: %v", id, err)
+			}
+		}(id, session)
+	}
+	log.Printf("[Accounts] Upstream base URL set to %s, %d clients will be recreated", baseURL, len(providers))
+}
+
+// Close закрывает сессии всех аккаунтов (shutdown)
+func (s *AccountService) Close(ctx context.Context) {
+	s.mu.Lock()
+	providers := s.providers
+	s.providers = make(map[uint]provider.Provider)
+	s.mu.Unlock()
+
+	for id, p := range providers {
+		if session, ok := p.(provider.Session); ok {
+			if err := session.Logout(ctx); err != nil {
+				log.Printf("[Accounts] Account #%d: logout on shutdown failed: %v", id, err)
+			}
+		}
+	}
+}
+
+// ─── ШИФРОВАНИЕ ПАРОЛЕЙ ────────────────────────────────────
+
+// seal шифрует секрет AES-256-GCM: "v1:" + base64(nonce || ciphertext)
+func (s *AccountService) seal(plaintext string) (string, error) {
+	gcm, err := s.gcm()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open расшифровывает секрет, сохранённый seal
+func (s *AccountService) open(sealed string) (string, error) {
+	if !strings.HasPrefix(sealed, sealedPrefix) {
+		return "", fmt.Errorf("unsupported credentials format")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("corrupted credentials: %w", err)
+	}
+	gcm, err := s.gcm()
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return "", fmt.Errorf("corrupted credentials")
+	}
+	nonce, ciphertext := raw[:gcm.NonceSize()], raw[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt credentials (EYESON_CREDENTIALS_KEY changed?)")
+	}
+	return string(plaintext), nil
+}
+
+func (s *AccountService) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
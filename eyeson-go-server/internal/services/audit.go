@@ -10,10 +10,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"eyeson-go-server/internal/database"
 	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
@@ -349,3 +351,64 @@ func (s *AuditService) LogBulkStatusChange(c *fiber.Ctx, count int, newStatus st
 		SetDetails(details)
 	auditLog.SaveAsync()
 }
+
+// LogRatePlanChangeQueued - логирование постановки в очередь смены тарифного плана
+func (s *AuditService) LogRatePlanChangeQueued(c *fiber.Ctx, msisdns []string, oldRatePlan, newRatePlan string) {
+	entityID := ""
+	if len(msisdns) == 1 {
+		entityID = msisdns[0]
+	}
+	details := fmt.Sprintf("Rate plan change to '%s' for %d SIMs queued.", newRatePlan, len(msisdns))
+	if len(msisdns) > 1 {
+		details += fmt.Sprintf(" MSISDNs: %v", msisdns)
+	}
+
+	s.NewLog(c).
+		Entity(models.EntitySIM, entityID).
+		Action(models.ActionRatePlan).
+		Change("rate_plan", oldRatePlan, newRatePlan).
+		Queued().
+		SetDetails(details).
+		SaveAsync()
+}
+
+// LogChangeSetQueued - логирование постановки в очередь набора изменений (CHANGE_SET).
+// В ChangeSet попадает весь набор: действие, поле, старое (для одной SIM) и новое значение.
+func (s *AuditService) LogChangeSetQueued(c *fiber.Ctx, msisdns []string, changes []provider.Change, oldValues map[string]string, batchID string) {
+	entityID := ""
+	if len(msisdns) == 1 {
+		entityID = msisdns[0]
+	}
+
+	set := make([]map[string]string, 0, len(changes))
+	names := make([]string, 0, len(changes))
+	for _, ch := range changes {
+		field := provider.ChangeField(ch.ActionType)
+		if field == "" {
+			field = strings.ToLower(ch.ActionType)
+		}
+		set = append(set, map[string]string{
+			"action_type": ch.ActionType,
+			"field":       field,
+			"old_value":   oldValues[field],
+			"new_value":   ch.TargetValue,
+		})
+		names = append(names, ch.ActionType)
+	}
+
+	details := fmt.Sprintf("Change set [%s] for %d SIMs queued.", strings.Join(names, ", "), len(msisdns))
+	if len(msisdns) > 1 {
+		details += fmt.Sprintf(" MSISDNs: %v", msisdns)
+	}
+
+	entry := s.NewLog(c).
+		Entity(models.EntitySIM, entityID).
+		Action(models.ActionChangeSet).
+		ChangeSet(set).
+		Queued().
+		SetDetails(details)
+	if batchID != "" {
+		entry.Batch(batchID)
+	}
+	entry.SaveAsync()
+}
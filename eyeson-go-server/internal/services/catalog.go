@@ -0,0 +1,335 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+
+	"gorm.io/gorm"
+)
+
+// ═══════════════════════════════════════════════════════════
+// PROVISIONING PARAMETER CATALOG
+// ═══════════════════════════════════════════════════════════
+
+// Уровни доступа к параметру (getProvisioningParameterList, PDF v1.5.2 раздел 4.1)
+const (
+	PermissionReadOnly          = "READ-ONLY"
+	PermissionReadWrite         = "READ-WRITE"
+	PermissionReadWriteFromList = "READ-WRITE_FROM_LIST"
+)
+
+// Логические поля каталога, которые мы изменяем через updateProvisioningData
+const (
+	CatalogFieldStatus   = "SIM_STATUS_CHANGE"
+	CatalogFieldRatePlan = "RATE_PLAN_FULL_NAME"
+)
+
+// catalogFieldAliases - имена, под которыми провайдер может вернуть поле.
+// Спецификация использует SIM_STATUS_NAME / RATE_PLAN, getProvisioningData и симулятор -
+// SIM_STATUS_CHANGE / RATE_PLAN_FULL_NAME.
+var catalogFieldAliases = map[string][]string{
+	CatalogFieldStatus:   {"SIM_STATUS_CHANGE", "SIM_STATUS_NAME", "SIM_STATE_CHANGE"},
+	CatalogFieldRatePlan: {"RATE_PLAN_FULL_NAME", "RATE_PLAN", "RATE_PLAN_CHANGE"},
+}
+
+// catalogSettingKey - префикс ключа SystemSetting; каталог хранится по аккаунтам:
+// provisioning.catalog.<account_id>
+const catalogSettingKey = "provisioning.catalog"
+
+// CatalogSnapshot - закэшированный ответ getProvisioningParameterList
+type CatalogSnapshot struct {
+	Parameters []models.ProvisioningParameter `json:"parameters"`
+	UpdatedAt  time.Time                      `json:"updated_at"`
+}
+
+// catalogEntry - каталог одного аккаунта
+type catalogEntry struct {
+	snapshot *CatalogSnapshot
+	unknown  map[string]bool // Поля вне каталога, о которых уже предупредили (сброс при Store)
+}
+
+// CatalogService - кэш каталогов параметров провизионирования по аккаунтам
+// (хранятся в SystemSetting). У аккаунтов разные договоры, поэтому допустимые тарифы
+// и статусы проверяются по каталогу аккаунта, которому принадлежит SIM.
+type CatalogService struct {
+	mu      sync.RWMutex
+	entries map[uint]*catalogEntry // Account ID -> каталог (загружается из БД при первом обращении)
+}
+
+// Catalog - глобальный экземпляр каталога
+var Catalog = &CatalogService{}
+
+// ErrCatalogValidation - значение не прошло проверку по каталогу
+var ErrCatalogValidation = errors.New("catalog validation failed")
+
+// catalogAccount разворачивает accountID = 0 в аккаунт по умолчанию
+func catalogAccount(accountID uint) (uint, bool) {
+	if accountID != 0 {
+		return accountID, true
+	}
+	account, err := Accounts.Default()
+	if err != nil {
+		return 0, false
+	}
+	return account.ID, true
+}
+
+func catalogKey(accountID uint) string {
+	return fmt.Sprintf("%s.%d", catalogSettingKey, accountID)
+}
+
+// Store сохраняет свежий ответ провайдера аккаунта в кэш и в SystemSetting.
+// accountID = 0 - аккаунт по умолчанию.
+func (s *CatalogService) Store(accountID uint, resp *models.GetProvisioningParameterListResponse) error {
+	if resp == nil {
+		return fmt.Errorf("empty parameter list")
+	}
+	accountID, ok := catalogAccount(accountID)
+	if !ok {
+		return ErrAccountNotFound
+	}
+
+	snapshot := &CatalogSnapshot{
+		Parameters: resp.Parameters,
+		UpdatedAt:  time.Now(),
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	setting := models.SystemSetting{Key: catalogKey(accountID), Value: string(data)}
+	if err := database.DB.Save(&setting).Error; err != nil {
+		return fmt.Errorf("failed to persist catalog: %w", err)
+	}
+
+	s.mu.Lock()
+	if s.entries == nil {
+		s.entries = make(map[uint]*catalogEntry)
+	}
+	s.entries[accountID] = &catalogEntry{snapshot: snapshot}
+	s.mu.Unlock()
+
+	log.Printf("[Catalog] Account #%d: stored %d provisioning parameters", accountID, len(snapshot.Parameters))
+	return nil
+}
+
+// Get возвращает закэшированный каталог аккаунта (nil, если провайдер аккаунта ещё
+// ни разу не ответил). accountID = 0 - аккаунт по умолчанию.
+func (s *CatalogService) Get(accountID uint) *CatalogSnapshot {
+	entry := s.entry(accountID)
+	if entry == nil {
+		return nil
+	}
+	return entry.snapshot
+}
+
+func (s *CatalogService) entry(accountID uint) *catalogEntry {
+	accountID, ok := catalogAccount(accountID)
+	if !ok {
+		return nil
+	}
+
+	s.mu.RLock()
+	entry, loaded := s.entries[accountID]
+	s.mu.RUnlock()
+	if loaded {
+		return entry
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, loaded = s.entries[accountID]; !loaded {
+		entry = &catalogEntry{snapshot: loadCatalogSetting(catalogKey(accountID))}
+		if s.entries == nil {
+			s.entries = make(map[uint]*catalogEntry)
+		}
+		s.entries[accountID] = entry
+	}
+	return entry
+}
+
+func loadCatalogSetting(key string) *CatalogSnapshot {
+	var setting models.SystemSetting
+	if err := database.DB.Where("key = ?", key).First(&setting).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("[Catalog] WARNING: could not read cached catalog %s: %v", key, err)
+		}
+		return nil
+	}
+
+	var snapshot CatalogSnapshot
+	if err := json.Unmarshal([]byte(setting.Value), &snapshot); err != nil {
+		log.Printf("[Catalog] WARNING: cached catalog %s is corrupted: %v", key, err)
+		return nil
+	}
+	return &snapshot
+}
+
+// Parameter возвращает параметр каталога аккаунта по логическому имени поля
+func (s *CatalogService) Parameter(accountID uint, fieldName string) *models.ProvisioningParameter {
+	snapshot := s.Get(accountID)
+	if snapshot == nil {
+		return nil
+	}
+
+	names := catalogFieldAliases[fieldName]
+	if len(names) == 0 {
+		names = []string{fieldName}
+	}
+	resp := models.GetProvisioningParameterListResponse{Parameters: snapshot.Parameters}
+	return resp.FindParameter(names...)
+}
+
+// AllowedValues возвращает допустимые значения для поля READ-WRITE_FROM_LIST
+func (s *CatalogService) AllowedValues(accountID uint, fieldName string) []string {
+	param := s.Parameter(accountID, fieldName)
+	if param == nil {
+		return nil
+	}
+	values := make([]string, 0, len(param.AvailableValues))
+	for _, v := range param.AvailableValues {
+		values = append(values, v.Name)
+	}
+	return values
+}
+
+// Validate проверяет, что значение поля можно записать согласно каталогу аккаунта.
+// Проверяются только поля, которые каталог описывает: пока каталог не загружен или поля
+// в нём нет, проверка пропускается - решение остаётся за провайдером.
+func (s *CatalogService) Validate(accountID uint, fieldName, value string) error {
+	snapshot := s.Get(accountID)
+	if snapshot == nil || len(snapshot.Parameters) == 0 {
+		return nil
+	}
+
+	param := s.Parameter(accountID, fieldName)
+	if param == nil {
+		s.warnUnknown(accountID, fieldName)
+		return nil
+	}
+
+	switch strings.ToUpper(param.PermissionLevel) {
+	case PermissionReadOnly:
+		return fmt.Errorf("%w: field %s is READ-ONLY, changes are not allowed", ErrCatalogValidation, param.FieldName)
+	case PermissionReadWriteFromList:
+		allowed := s.AllowedValues(accountID, fieldName)
+		for _, v := range allowed {
+			if v == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: value %q is not allowed for %s (allowed: %s)",
+			ErrCatalogValidation, value, param.FieldName, strings.Join(allowed, ", "))
+	default:
+		return nil
+	}
+}
+
+// warnUnknown один раз на каталог сообщает о поле, которого в каталоге нет
+func (s *CatalogService) warnUnknown(accountID uint, fieldName string) {
+	entry := s.entry(accountID)
+	if entry == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry.unknown[fieldName] {
+		return
+	}
+	if entry.unknown == nil {
+		entry.unknown = make(map[string]bool)
+	}
+	entry.unknown[fieldName] = true
+	log.Printf("[Catalog] WARNING: field %s is not in the provisioning catalog, skipping validation", fieldName)
+}
+
+// ValidateFor проверяет значение по каталогам всех аккаунтов, которым принадлежат SIM
+func (s *CatalogService) ValidateFor(msisdns []string, fieldName, value string) error {
+	for _, accountID := range Accounts.AccountsForMSISDNs(msisdns) {
+		if err := s.Validate(accountID, fieldName, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LabelCatalogField конвертирует поле метки (label_1, CUSTOMER_LABEL_1) в имя поля каталога
+func LabelCatalogField(field string) string {
+	switch field {
+	case "label_1", "CUSTOMER_LABEL_1":
+		return "CUSTOMER_LABEL_1"
+	case "label_2", "CUSTOMER_LABEL_2":
+		return "CUSTOMER_LABEL_2"
+	case "label_3", "CUSTOMER_LABEL_3":
+		return "CUSTOMER_LABEL_3"
+	default:
+		return strings.ToUpper(field)
+	}
+}
+
+// ValidateChangeSet проверяет набор действий CHANGE_SET: не пустой, каждое действие
+// встречается один раз, значения проходят проверку по каталогу. SIM_SWAP в набор не входит -
+// он меняет ICCID одного абонента и ставится отдельной задачей.
+func (s *CatalogService) ValidateChangeSet(accountID uint, changes []provider.Change) error {
+	if len(changes) == 0 {
+		return fmt.Errorf("%w: change set is empty", ErrCatalogValidation)
+	}
+
+	seen := make(map[string]bool, len(changes))
+	for _, ch := range changes {
+		if seen[ch.ActionType] {
+			return fmt.Errorf("%w: action %s is repeated in the change set", ErrCatalogValidation, ch.ActionType)
+		}
+		seen[ch.ActionType] = true
+
+		var err error
+		switch {
+		case ch.ActionType == provider.ActionSimStateChange:
+			if ch.TargetValue == "" {
+				return fmt.Errorf("%w: %s requires a target value", ErrCatalogValidation, ch.ActionType)
+			}
+			err = s.Validate(accountID, CatalogFieldStatus, ch.TargetValue)
+		case ch.ActionType == provider.ActionRatePlanChange:
+			if ch.TargetValue == "" {
+				return fmt.Errorf("%w: %s requires a target value", ErrCatalogValidation, ch.ActionType)
+			}
+			err = s.Validate(accountID, CatalogFieldRatePlan, ch.TargetValue)
+		case ch.ActionType == provider.ActionCustomerLabel1,
+			ch.ActionType == provider.ActionCustomerLabel2,
+			ch.ActionType == provider.ActionCustomerLabel3:
+			err = s.Validate(accountID, LabelCatalogField(ch.ActionType), ch.TargetValue)
+		default:
+			return fmt.Errorf("%w: action %s is not supported in a change set", ErrCatalogValidation, ch.ActionType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateChangeSetFor проверяет набор действий по каталогам всех аккаунтов, которым принадлежат SIM
+func (s *CatalogService) ValidateChangeSetFor(msisdns []string, changes []provider.Change) error {
+	for _, accountID := range Accounts.AccountsForMSISDNs(msisdns) {
+		if err := s.ValidateChangeSet(accountID, changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
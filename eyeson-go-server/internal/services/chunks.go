@@ -0,0 +1,260 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package services
+
+import (
+	"log"
+	"time"
+
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+
+	"gorm.io/gorm"
+)
+
+// ═══════════════════════════════════════════════════════════
+// BULK TASK CHUNKS
+// ═══════════════════════════════════════════════════════════
+
+// chunkSizeFor - сколько абонентов в одной части для аккаунта (EYESON_API_BULK_CHUNK_SIZE)
+func chunkSizeFor(accountID uint) int {
+	prov, err := Accounts.Provider(accountID)
+	if err != nil {
+		return provider.DefaultMaxSubscribersPerAction
+	}
+	return provider.MaxSubscribers(prov)
+}
+
+// newChunks делит абонентов задачи на части
+func newChunks(task *models.SyncTaskExtended, msisdns []string, size int) []models.TaskChunk {
+	parts := provider.SplitSubscribers(msisdns, size)
+	chunks := make([]models.TaskChunk, len(parts))
+	for i, part := range parts {
+		chunks[i] = models.TaskChunk{
+			TaskID:     task.ID,
+			BatchID:    task.BatchID,
+			ChunkIndex: i,
+			Status:     models.ChunkStatusPending,
+		}
+		chunks[i].SetMSISDNs(part)
+	}
+	return chunks
+}
+
+// TaskChunks возвращает части задачи по порядку
+func (s *QueueService) TaskChunks(taskID uint) ([]models.TaskChunk, error) {
+	var chunks []models.TaskChunk
+	err := database.DB.Where("task_id = ?", taskID).Order("chunk_index ASC").Find(&chunks).Error
+	return chunks, err
+}
+
+// EnsureChunks возвращает части задачи, создавая их при первом выполнении
+// (задачи, поставленные не через CreateBulk)
+func (s *QueueService) EnsureChunks(task *models.SyncTaskExtended, msisdns []string, size int) ([]models.TaskChunk, error) {
+	chunks, err := s.TaskChunks(task.ID)
+	if err != nil || len(chunks) > 0 {
+		return chunks, err
+	}
+
+	chunks = newChunks(task, msisdns, size)
+	if len(chunks) == 0 {
+		return chunks, nil
+	}
+	if err := database.DB.Create(&chunks).Error; err != nil {
+		return nil, err
+	}
+	log.Printf("[Queue] Task #%d: split %d SIMs into %d chunks", task.ID, len(msisdns), len(chunks))
+	return chunks, nil
+}
+
+// CloseChunks завершает неотправленные части задачи (отмена или окончательный провал задачи).
+// SUBMITTED-части провайдер уже принял - закрывать их отказом нельзя, их job подтверждается.
+func (s *QueueService) CloseChunks(taskID uint, status models.ChunkStatus, reason string) {
+	now := time.Now()
+	result := database.DB.Model(&models.TaskChunk{}).
+		Where("task_id = ? AND status = ?", taskID, models.ChunkStatusPending).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"failed":       gorm.Expr("size"),
+			"succeeded":    0,
+			"last_error":   reason,
+			"completed_at": &now,
+		})
+	if result.Error != nil {
+		log.Printf("[Queue] Task #%d: failed to close chunks: %v", taskID, result.Error)
+	}
+}
+
+// RetryFailedChunks возвращает в PENDING части, которые не прошли, а абонентов, отклонённых
+// провайдером в подтверждённых частях (COMPLETED с отказами), переносит в новые PENDING-части.
+// Принятые провайдером абоненты повторно не отправляются. Возвращает число частей к отправке.
+func (s *QueueService) RetryFailedChunks(taskID uint) (int64, error) {
+	var retried int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TaskChunk{}).
+			Where("task_id = ? AND status IN ?", taskID,
+				[]models.ChunkStatus{models.ChunkStatusFailed, models.ChunkStatusCancelled}).
+			Updates(reopenChunk())
+		if result.Error != nil {
+			return result.Error
+		}
+		retried = result.RowsAffected
+
+		var partial []models.TaskChunk
+		if err := tx.Where("task_id = ? AND status = ? AND failed > 0", taskID, models.ChunkStatusCompleted).
+			Order("chunk_index ASC").Find(&partial).Error; err != nil {
+			return err
+		}
+		if len(partial) == 0 {
+			return nil
+		}
+
+		rejectedAt := rejections(tx, taskID)
+		var next int
+		if err := tx.Model(&models.TaskChunk{}).Where("task_id = ?", taskID).
+			Select("COALESCE(MAX(chunk_index), -1) + 1").Scan(&next).Error; err != nil {
+			return err
+		}
+
+		for _, ch := range partial {
+			var kept, rejected []string
+			for _, m := range ch.MSISDNList() {
+				if RejectedSince(rejectedAt, m, ch.SubmittedAt) {
+					rejected = append(rejected, m)
+				} else {
+					kept = append(kept, m)
+				}
+			}
+			switch {
+			case len(rejected) == 0:
+				log.Printf("[Queue] Task #%d chunk %d: rejected SIMs not found in history, nothing to retry", taskID, ch.ChunkIndex)
+				continue
+			case len(kept) == 0:
+				// Отклонены все абоненты части - отправляем её заново целиком
+				if err := tx.Model(&ch).Updates(reopenChunk()).Error; err != nil {
+					return err
+				}
+				retried++
+				continue
+			}
+
+			// Принятые абоненты остаются в части, отклонённые уходят в новую
+			ch.SetMSISDNs(kept)
+			if err := tx.Model(&ch).Updates(map[string]interface{}{
+				"msisdns":   ch.Msisdns,
+				"size":      ch.Size,
+				"failed":    0,
+				"succeeded": ch.Size,
+			}).Error; err != nil {
+				return err
+			}
+			retry := models.TaskChunk{
+				TaskID:     ch.TaskID,
+				BatchID:    ch.BatchID,
+				ChunkIndex: next,
+				Status:     models.ChunkStatusPending,
+			}
+			retry.SetMSISDNs(rejected)
+			if err := tx.Create(&retry).Error; err != nil {
+				return err
+			}
+			log.Printf("[Queue] Task #%d chunk %d: %d rejected SIMs re-queued as chunk %d", taskID, ch.ChunkIndex, len(rejected), next)
+			next++
+			retried++
+		}
+		return nil
+	})
+	return retried, err
+}
+
+// reopenChunk - обновление, возвращающее часть в PENDING
+func reopenChunk() map[string]interface{} {
+	return map[string]interface{}{
+		"status":              models.ChunkStatusPending,
+		"attempt":             0,
+		"failed":              0,
+		"succeeded":           0,
+		"provider_request_id": 0,
+		"provider_status":     "",
+		"completed_at":        nil,
+	}
+}
+
+// Rejections возвращает, когда провайдер последний раз отклонил каждого абонента задачи
+// (история PROVIDER_REJECTED, которую пишет ProviderReconciler)
+func (s *QueueService) Rejections(taskID uint) map[string]time.Time {
+	return rejections(database.DB, taskID)
+}
+
+func rejections(tx *gorm.DB, taskID uint) map[string]time.Time {
+	var rows []models.SimHistory
+	tx.Select("msisdn", "created_at").
+		Where("task_id = ? AND action = ?", taskID, "PROVIDER_REJECTED").Find(&rows)
+	last := make(map[string]time.Time, len(rows))
+	for _, h := range rows {
+		if h.CreatedAt.After(last[h.MSISDN]) {
+			last[h.MSISDN] = h.CreatedAt
+		}
+	}
+	return last
+}
+
+// RejectedSince - отказ по абоненту записан после отправки (части или задачи). Отказы до
+// повторной отправки относятся к прошлой попытке; submittedAt == nil - учитывается любой отказ.
+func RejectedSince(rejections map[string]time.Time, msisdn string, submittedAt *time.Time) bool {
+	at, ok := rejections[msisdn]
+	if !ok {
+		return false
+	}
+	return submittedAt == nil || !at.Before(*submittedAt)
+}
+
+// ActiveChunkedTasks возвращает для SIM из списка активную bulk-задачу, в часть которой они входят
+func (s *QueueService) ActiveChunkedTasks(msisdns []string) map[string]models.SyncTaskExtended {
+	found := make(map[string]models.SyncTaskExtended)
+	if len(msisdns) == 0 {
+		return found
+	}
+	wanted := make(map[string]bool, len(msisdns))
+	for _, m := range msisdns {
+		wanted[m] = true
+	}
+
+	var chunks []models.TaskChunk
+	database.DB.Where("status IN ?", []models.ChunkStatus{models.ChunkStatusPending, models.ChunkStatusSubmitted}).
+		Find(&chunks)
+	if len(chunks) == 0 {
+		return found
+	}
+
+	members := make(map[uint][]string)
+	for _, ch := range chunks {
+		for _, m := range ch.MSISDNList() {
+			if wanted[m] {
+				members[ch.TaskID] = append(members[ch.TaskID], m)
+			}
+		}
+	}
+	if len(members) == 0 {
+		return found
+	}
+
+	ids := make([]uint, 0, len(members))
+	for id := range members {
+		ids = append(ids, id)
+	}
+	var tasks []models.SyncTaskExtended
+	database.DB.Where("id IN ? AND status IN ?", ids, []models.TaskStatus{
+		models.TaskStatusPending, models.TaskStatusProcessing, models.TaskStatusAwaitingProvider,
+	}).Find(&tasks)
+	for _, t := range tasks {
+		for _, m := range members[t.ID] {
+			found[m] = t
+		}
+	}
+	return found
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+)
+
+// ═══════════════════════════════════════════════════════════
+// КОНФЛИКТЫ СИНХРОНИЗАЦИИ С ЗАДАЧАМИ
+// ═══════════════════════════════════════════════════════════
+//
+// Worker пишет новое значение в SimCard сразу после отправки запроса, а Pelephone ещё
+// какое-то время отдаёт старое (eventual consistency). Синхронизация в этом окне вернула бы
+// старое значение и записала бы ложный CHANGE_*. Пока задача активна или завершилась меньше
+// ConflictWindow назад, поле, которое она изменила, не перезаписывается значением провайдера,
+// отличным от ожидаемого; конфликт виден в SimCard.SyncConflict. Проваленные и отменённые
+// задачи не защищаются - там прав провайдер.
+
+// DefaultConflictWindow - сколько после завершения задачи ждать, пока провайдер отдаст новое значение
+const DefaultConflictWindow = 10 * time.Minute
+
+// ExpectedChange - значение поля, которое задача записала в SIM и ждёт от провайдера
+type ExpectedChange struct {
+	TaskID uint
+	Field  string // Ключ поля SimCard: STATUS, LABEL_1...
+	Value  string
+}
+
+func (s *QueueService) conflictWindow() time.Duration {
+	if s.ConflictWindow > 0 {
+		return s.ConflictWindow
+	}
+	return DefaultConflictWindow
+}
+
+// ExpectedIndex - ожидаемые значения полей по MSISDN на весь цикл синхронизации.
+// Активные и недавно завершённые задачи читаются и разбираются один раз, а не на каждую
+// страницу; следующие страницы дочитывают только задачи, созданные после прошлого чтения.
+type ExpectedIndex struct {
+	mu      sync.Mutex
+	queue   *QueueService
+	changes map[string][]ExpectedChange // MSISDN -> значения в порядке создания задач
+	lastID  uint                        // Последняя прочитанная задача
+}
+
+// ExpectedIndex собирает ожидаемые значения от активных и недавно завершённых задач
+func (s *QueueService) ExpectedIndex() *ExpectedIndex {
+	idx := &ExpectedIndex{queue: s, changes: make(map[string][]ExpectedChange)}
+	idx.load()
+	return idx
+}
+
+// load дочитывает задачи с ID больше lastID
+func (x *ExpectedIndex) load() {
+	var tasks []models.SyncTaskExtended
+	database.DB.Where("id > ?", x.lastID).
+		Where("status IN ? OR (status = ? AND completed_at >= ?)",
+			[]models.TaskStatus{models.TaskStatusPending, models.TaskStatusProcessing, models.TaskStatusAwaitingProvider},
+			models.TaskStatusCompleted, time.Now().Add(-x.queue.conflictWindow())).
+		Order("id").Find(&tasks)
+
+	for _, t := range tasks {
+		x.lastID = t.ID
+		changes, targets := expectedValues(t)
+		for _, m := range targets {
+			for _, ch := range changes {
+				ch.TaskID = t.ID
+				x.changes[m] = append(x.changes[m], ch)
+			}
+		}
+	}
+}
+
+// For - ожидаемые значения для MSISDN страницы (в порядке создания задач: более поздняя
+// задача важнее). Задачи, созданные после прошлого вызова, дочитываются.
+func (x *ExpectedIndex) For(msisdns []string) map[string][]ExpectedChange {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.load()
+
+	found := make(map[string][]ExpectedChange)
+	for _, m := range msisdns {
+		if changes, ok := x.changes[m]; ok {
+			found[m] = changes
+		}
+	}
+	return found
+}
+
+// expectedValues - поля, которые задача меняет локально, и её абоненты
+func expectedValues(t models.SyncTaskExtended) ([]ExpectedChange, []string) {
+	var p struct {
+		Msisdns []string          `json:"msisdns"`
+		Msisdn  string            `json:"msisdn"`
+		Status  string            `json:"status"`
+		Field   string            `json:"field"`
+		Value   string            `json:"value"`
+		Changes []provider.Change `json:"changes"`
+	}
+	_ = json.Unmarshal([]byte(t.Payload), &p)
+
+	var changes []ExpectedChange
+	add := func(field, value string) {
+		if f, ok := models.LookupSimField(field); ok {
+			changes = append(changes, ExpectedChange{Field: f.Key, Value: value})
+		}
+	}
+	switch t.Type {
+	case models.TaskTypeStatusChange, models.TaskTypeBulkChange, "CHANGE_STATUS":
+		status := p.Status
+		if status == "" {
+			status = t.NewStatus
+		}
+		add("status", status)
+	case models.TaskTypeLabelUpdate:
+		field := t.LabelField
+		if provider.IsLabelAction(field) {
+			field = provider.LabelField(field)
+		}
+		add(field, t.LabelValue)
+	case "UPDATE_SIM":
+		add(p.Field, p.Value)
+	case models.TaskTypeChangeSet:
+		for _, ch := range p.Changes {
+			// Future план локально не пишется (см. applyChangeSet)
+			if field := provider.ChangeField(ch.ActionType); field != "" && ch.ActionType != provider.ActionRatePlanChange {
+				add(field, ch.TargetValue)
+			}
+		}
+	}
+	// RATE_PLAN_CHANGE и SIM_SWAP локальную SIM не меняют - защищать нечего
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	targets := p.Msisdns
+	if p.Msisdn != "" {
+		targets = append(targets, p.Msisdn)
+	}
+	if t.TargetMSISDN != "" {
+		targets = append(targets, t.TargetMSISDN)
+	}
+	return changes, targets
+}
+
+// HoldBack оставляет в next локальные значения полей, которые задача записала, а провайдер ещё
+// не подтвердил. Возвращает описание конфликта для SimCard.SyncConflict (пусто - конфликта нет).
+func HoldBack(next *models.SimCard, local models.SimCard, expected []ExpectedChange) string {
+	latest := make(map[string]ExpectedChange)
+	var fields []string
+	for _, e := range expected {
+		if _, ok := latest[e.Field]; !ok {
+			fields = append(fields, e.Field)
+		}
+		latest[e.Field] = e
+	}
+
+	var conflicts []string
+	for _, field := range fields {
+		e := latest[field]
+		upstream := next.FieldValue(field)
+		// Провайдер уже отдаёт новое значение, или локально не оптимистичное значение задачи
+		if upstream == e.Value || local.FieldValue(field) != e.Value {
+			continue
+		}
+		next.CopyField(field, local)
+		conflicts = append(conflicts, fmt.Sprintf("%s: provider %q, task #%d expects %q", field, upstream, e.TaskID, e.Value))
+	}
+	return strings.Join(conflicts, "; ")
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"eyeson-go-server/internal/database"
+	"eyeson-go-server/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ═══════════════════════════════════════════════════════════
+// SPARE SIM INVENTORY SERVICE
+// ═══════════════════════════════════════════════════════════
+
+// InventoryService - сервис локального склада запасных SIM (ICCID) для SIM_SWAP
+type InventoryService struct {
+	mu sync.Mutex
+}
+
+// Inventory - глобальный экземпляр сервиса склада
+var Inventory = &InventoryService{}
+
+// ErrNoSpareSim - на складе нет свободных ICCID
+var ErrNoSpareSim = errors.New("no available spare SIM in inventory")
+
+// ErrSwapNotQueued - задачу SIM_SWAP не удалось записать в очередь (резерв отменён)
+var ErrSwapNotQueued = errors.New("failed to queue task")
+
+// AddSpares добавляет ICCID на склад. Уже существующие ICCID пропускаются.
+func (s *InventoryService) AddSpares(iccids []string, notes string) (int, error) {
+	added := 0
+	for _, iccid := range iccids {
+		iccid = strings.TrimSpace(iccid)
+		if iccid == "" {
+			continue
+		}
+		spare := models.SpareSim{
+			ICCID:  iccid,
+			Status: models.SpareSimAvailable,
+			Notes:  notes,
+		}
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&spare)
+		if result.Error != nil {
+			return added, fmt.Errorf("failed to add ICCID %s: %w", iccid, result.Error)
+		}
+		added += int(result.RowsAffected)
+	}
+
+	log.Printf("[Inventory] Added %d spare SIMs (%d submitted)", added, len(iccids))
+	return added, nil
+}
+
+// List возвращает ICCID склада, опционально отфильтрованные по статусу
+func (s *InventoryService) List(status models.SpareSimStatus, limit int) ([]models.SpareSim, error) {
+	var spares []models.SpareSim
+	query := database.DB.Model(&models.SpareSim{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at ASC").Limit(limit).Find(&spares).Error
+	return spares, err
+}
+
+// Counts возвращает количество ICCID по статусам
+func (s *InventoryService) Counts() map[models.SpareSimStatus]int64 {
+	counts := make(map[models.SpareSimStatus]int64)
+	for _, st := range []models.SpareSimStatus{models.SpareSimAvailable, models.SpareSimReserved, models.SpareSimInUse, models.SpareSimRetired} {
+		var n int64
+		database.DB.Model(&models.SpareSim{}).Where("status = ?", st).Count(&n)
+		counts[st] = n
+	}
+	return counts
+}
+
+// Reserve резервирует свободный ICCID под SIM_SWAP абонента.
+// Если preferredICCID задан - резервируется именно он (должен быть AVAILABLE).
+func (s *InventoryService) Reserve(msisdn, preferredICCID string) (*models.SpareSim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var spare *models.SpareSim
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		spare, err = reserveTx(tx, msisdn, preferredICCID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Inventory] Reserved ICCID %s for %s", spare.ICCID, msisdn)
+	return spare, nil
+}
+
+// QueueSwap резервирует ICCID, создаёт задачу SIM_SWAP и связывает их одной транзакцией:
+// worker не увидит задачу, пока ICCID не привязан к ней. req.NewICCID задаётся из склада.
+func (s *InventoryService) QueueSwap(req CreateTaskRequest, preferredICCID string) (*models.SyncTaskExtended, *models.SpareSim, error) {
+	Queue.mu.Lock()
+	defer Queue.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var task *models.SyncTaskExtended
+	var spare *models.SpareSim
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if spare, err = reserveTx(tx, req.MSISDN, preferredICCID); err != nil {
+			return err
+		}
+
+		req.Type = models.TaskTypeSimSwap
+		req.NewICCID = spare.ICCID
+		task = newTask(req)
+		if err := tx.Create(task).Error; err != nil {
+			return fmt.Errorf("%w: %v", ErrSwapNotQueued, err)
+		}
+
+		spare.TaskID = &task.ID
+		return tx.Model(&models.SpareSim{}).Where("id = ?", spare.ID).Update("task_id", task.ID).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Printf("[Inventory] Reserved ICCID %s for %s (task #%d)", spare.ICCID, req.MSISDN, task.ID)
+	return task, spare, nil
+}
+
+// reserveTx переводит свободный ICCID в RESERVED в рамках транзакции tx
+func reserveTx(tx *gorm.DB, msisdn, preferredICCID string) (*models.SpareSim, error) {
+	var spare models.SpareSim
+	query := tx.Where("status = ?", models.SpareSimAvailable)
+	if preferredICCID != "" {
+		query = query.Where("icc_id = ?", strings.TrimSpace(preferredICCID))
+	}
+	if err := query.Order("created_at ASC").First(&spare).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if preferredICCID != "" {
+				return nil, fmt.Errorf("ICCID %s is not available in inventory", preferredICCID)
+			}
+			return nil, ErrNoSpareSim
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	result := tx.Model(&models.SpareSim{}).
+		Where("id = ? AND status = ?", spare.ID, models.SpareSimAvailable).
+		Updates(map[string]interface{}{
+			"status":      models.SpareSimReserved,
+			"msisdn":      msisdn,
+			"reserved_at": &now,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("ICCID %s was reserved concurrently", spare.ICCID)
+	}
+	spare.Status = models.SpareSimReserved
+	spare.MSISDN = msisdn
+	spare.ReservedAt = &now
+	return &spare, nil
+}
+
+// Release возвращает зарезервированный ICCID на склад
+func (s *InventoryService) Release(iccid string) error {
+	result := database.DB.Model(&models.SpareSim{}).
+		Where("icc_id = ? AND status = ?", iccid, models.SpareSimReserved).
+		Updates(map[string]interface{}{
+			"status":      models.SpareSimAvailable,
+			"msisdn":      "",
+			"task_id":     nil,
+			"reserved_at": nil,
+		})
+	if result.RowsAffected > 0 {
+		log.Printf("[Inventory] Released ICCID %s back to inventory", iccid)
+	}
+	return result.Error
+}
+
+// ReleaseForTask возвращает на склад ICCID, зарезервированный задачей (отмена/провал)
+func (s *InventoryService) ReleaseForTask(taskID uint) error {
+	var spares []models.SpareSim
+	if err := database.DB.Where("task_id = ? AND status = ?", taskID, models.SpareSimReserved).Find(&spares).Error; err != nil {
+		return err
+	}
+	for _, spare := range spares {
+		if err := s.Release(spare.ICCID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CompleteSwap фиксирует успешный SIM_SWAP: новый ICCID -> IN_USE, старый -> RETIRED.
+// Старый ICCID заводится на склад, если его там ещё не было.
+func (s *InventoryService) CompleteSwap(msisdn, oldICCID, newICCID string, taskID uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SpareSim{}).
+			Where("icc_id = ?", newICCID).
+			Updates(map[string]interface{}{
+				"status":      models.SpareSimInUse,
+				"msisdn":      msisdn,
+				"task_id":     taskID,
+				"assigned_at": &now,
+			}).Error; err != nil {
+			return err
+		}
+
+		oldICCID = strings.TrimSpace(oldICCID)
+		if oldICCID == "" || oldICCID == newICCID {
+			return nil
+		}
+
+		retired := models.SpareSim{
+			ICCID:     oldICCID,
+			Status:    models.SpareSimRetired,
+			MSISDN:    msisdn,
+			TaskID:    &taskID,
+			RetiredAt: &now,
+			Notes:     "Retired by SIM_SWAP",
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "icc_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "msisdn", "task_id", "retired_at", "updated_at"}),
+		}).Create(&retired).Error
+	})
+}
@@ -6,6 +6,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -14,6 +15,7 @@ import (
 
 	"eyeson-go-server/internal/database"
 	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
 
 	"github.com/google/uuid"
 )
@@ -25,6 +27,14 @@ import (
 // QueueService - сервис для управления очередью задач
 type QueueService struct {
 	mu sync.RWMutex
+
+	// Задачи, которые worker выполняет прямо сейчас: отмена прерывает их HTTP-запрос
+	inflightMu sync.Mutex
+	inflight   map[uint]context.CancelFunc
+
+	// ConflictWindow - сколько после завершения задачи синхронизация не перезаписывает
+	// изменённые ею поля старым значением провайдера (0 - DefaultConflictWindow, conflicts.go)
+	ConflictWindow time.Duration
 }
 
 // Queue - глобальный экземпляр сервиса очереди
@@ -46,6 +56,70 @@ type CreateTaskRequest struct {
 	Username   string
 	IPAddress  string
 	RequestID  string // Correlation ID от frontend
+
+	// Для RATE_PLAN_CHANGE
+	OldRatePlan string
+	NewRatePlan string
+
+	// Для SIM_SWAP
+	OldICCID string
+	NewICCID string
+
+	// Для bulk-задач (CreateBulk): все абоненты задачи
+	Msisdns []string
+
+	// Для CHANGE_SET: действия, отправляемые одним запросом
+	Changes []provider.Change
+}
+
+// buildPayload формирует JSON payload задачи (важно: должен быть совместим с worker)
+func buildPayload(req CreateTaskRequest) string {
+	payload := map[string]interface{}{
+		"type": req.Type,
+	}
+	id := req.MSISDN
+	if id == "" {
+		id = req.CLI
+	}
+	msisdns := req.Msisdns
+	if len(msisdns) == 0 {
+		msisdns = []string{id}
+	}
+	switch req.Type {
+	case models.TaskTypeStatusChange, models.TaskTypeBulkChange:
+		// Worker ожидает для смены статуса: {"msisdns": [...], "status": "..."}
+		payload["msisdns"] = msisdns
+		payload["status"] = req.NewStatus
+		payload["old_status"] = req.OldStatus
+		payload["new_status"] = req.NewStatus
+		payload["cli"] = req.CLI
+		payload["msisdn"] = req.MSISDN
+	case models.TaskTypeRatePlanChange:
+		// Worker ожидает для смены тарифа: {"msisdns": [...], "rate_plan": "..."}
+		payload["msisdns"] = msisdns
+		payload["rate_plan"] = req.NewRatePlan
+		payload["old_rate_plan"] = req.OldRatePlan
+		payload["cli"] = req.CLI
+		payload["msisdn"] = req.MSISDN
+	case models.TaskTypeChangeSet:
+		// Worker ожидает: {"msisdns": [...], "changes": [{"action_type", "target_value"}]}
+		payload["msisdns"] = msisdns
+		payload["changes"] = req.Changes
+		payload["cli"] = req.CLI
+		payload["msisdn"] = req.MSISDN
+	case models.TaskTypeSimSwap:
+		payload["msisdn"] = id
+		payload["cli"] = req.CLI
+		payload["old_iccid"] = req.OldICCID
+		payload["new_iccid"] = req.NewICCID
+	default:
+		payload["msisdn"] = req.MSISDN
+		payload["cli"] = req.CLI
+		payload["old_status"] = req.OldStatus
+		payload["new_status"] = req.NewStatus
+	}
+	payloadJSON, _ := json.Marshal(payload)
+	return string(payloadJSON)
 }
 
 // CreateTask создаёт одну задачу в очереди
@@ -53,6 +127,19 @@ func (s *QueueService) CreateTask(req CreateTaskRequest) (*models.SyncTaskExtend
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	task := newTask(req)
+	if err := database.DB.Create(task).Error; err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+
+	log.Printf("[Queue] Created task #%d: %s %s → %s for %s (request_id: %s)",
+		task.ID, task.Type, task.OldStatus, task.NewStatus, task.TargetMSISDN, task.RequestID)
+
+	return task, nil
+}
+
+// newTask собирает задачу PENDING из запроса (без записи в БД)
+func newTask(req CreateTaskRequest) *models.SyncTaskExtended {
 	// Генерируем request_id если не передан
 	if req.RequestID == "" {
 		req.RequestID = uuid.New().String()
@@ -74,10 +161,15 @@ func (s *QueueService) CreateTask(req CreateTaskRequest) (*models.SyncTaskExtend
 		Status:       models.TaskStatusPending,
 		TargetMSISDN: targetMSISDN,
 		TargetCLI:    req.CLI,
+		AccountID:    Accounts.AccountForMSISDN(targetMSISDN),
 		OldStatus:    req.OldStatus,
 		NewStatus:    req.NewStatus,
 		LabelField:   req.LabelField,
 		LabelValue:   req.LabelValue,
+		OldRatePlan:  req.OldRatePlan,
+		NewRatePlan:  req.NewRatePlan,
+		OldICCID:     req.OldICCID,
+		NewICCID:     req.NewICCID,
 		UserID:       &req.UserID,
 		Username:     req.Username,
 		IPAddress:    req.IPAddress,
@@ -86,43 +178,13 @@ func (s *QueueService) CreateTask(req CreateTaskRequest) (*models.SyncTaskExtend
 		Attempt:      0,
 	}
 
-	// Payload для дополнительных данных (важно: должен быть совместим с worker)
-	payload := map[string]interface{}{
-		"type": req.Type,
-	}
-	// Worker ожидает для смены статуса: {"msisdns": [...], "status": "..."}
-	if req.Type == models.TaskTypeStatusChange || req.Type == models.TaskTypeBulkChange {
-		id := req.MSISDN
-		if id == "" {
-			id = req.CLI
-		}
-		payload["msisdns"] = []string{id}
-		payload["status"] = req.NewStatus
-		payload["old_status"] = req.OldStatus
-		payload["new_status"] = req.NewStatus
-		payload["cli"] = req.CLI
-		payload["msisdn"] = req.MSISDN
-	} else {
-		payload["msisdn"] = req.MSISDN
-		payload["cli"] = req.CLI
-		payload["old_status"] = req.OldStatus
-		payload["new_status"] = req.NewStatus
-	}
-	payloadJSON, _ := json.Marshal(payload)
-	task.Payload = string(payloadJSON)
+	// Payload для дополнительных данных
+	task.Payload = buildPayload(req)
 
 	// Время первого запуска - сразу
 	now := time.Now()
 	task.NextRunAt = &now
-
-	if err := database.DB.Create(task).Error; err != nil {
-		return nil, fmt.Errorf("failed to create task: %w", err)
-	}
-
-	log.Printf("[Queue] Created task #%d: %s %s → %s for %s (request_id: %s)",
-		task.ID, task.Type, task.OldStatus, task.NewStatus, task.TargetMSISDN, task.RequestID)
-
-	return task, nil
+	return task
 }
 
 // CreateBatch создаёт группу задач (batch операция)
@@ -146,38 +208,18 @@ func (s *QueueService) CreateBatch(items []CreateTaskRequest) (string, []uint, e
 			req.Priority = models.PriorityHigh
 		}
 
-		// Создаём payload для worker
-		payload := map[string]interface{}{
-			"type": req.Type,
-		}
-		if req.Type == models.TaskTypeStatusChange || req.Type == models.TaskTypeBulkChange {
-			id := req.MSISDN
-			if id == "" {
-				id = req.CLI
-			}
-			payload["msisdns"] = []string{id}
-			payload["status"] = req.NewStatus
-			payload["old_status"] = req.OldStatus
-			payload["new_status"] = req.NewStatus
-			payload["cli"] = req.CLI
-			payload["msisdn"] = req.MSISDN
-		} else {
-			payload["msisdn"] = req.MSISDN
-			payload["cli"] = req.CLI
-			payload["old_status"] = req.OldStatus
-			payload["new_status"] = req.NewStatus
-		}
-		payloadJSON, _ := json.Marshal(payload)
-
 		task := &models.SyncTaskExtended{
 			Type:         req.Type,
 			Priority:     req.Priority,
 			Status:       models.TaskStatusPending,
 			TargetMSISDN: req.MSISDN,
 			TargetCLI:    req.CLI,
+			AccountID:    Accounts.AccountForMSISDN(req.MSISDN),
 			OldStatus:    req.OldStatus,
 			NewStatus:    req.NewStatus,
-			Payload:      string(payloadJSON),
+			OldRatePlan:  req.OldRatePlan,
+			NewRatePlan:  req.NewRatePlan,
+			Payload:      buildPayload(req),
 			UserID:       &req.UserID,
 			Username:     req.Username,
 			IPAddress:    req.IPAddress,
@@ -209,6 +251,88 @@ func (s *QueueService) CreateBatch(items []CreateTaskRequest) (string, []uint, e
 	return batchID, taskIDs, nil
 }
 
+// CreateBulk ставит большой список SIM как bulk-задачи: одна задача на аккаунт провайдера,
+// абоненты которой разбиты на части (TaskChunk) по размеру, который принимает провайдер.
+// Поля MSISDN/CLI/OldStatus запроса не используются. Прогресс - GetBatchProgress по batch_id.
+func (s *QueueService) CreateBulk(req CreateTaskRequest, msisdns []string) (string, []uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(msisdns) == 0 {
+		return "", nil, fmt.Errorf("no subscribers")
+	}
+	if req.Priority == 0 {
+		req.Priority = models.PriorityHigh
+	}
+
+	// Группируем по аккаунту, сохраняя порядок
+	var accountOrder []uint
+	groups := make(map[uint][]string)
+	for _, msisdn := range msisdns {
+		accountID := Accounts.AccountForMSISDN(msisdn)
+		if _, ok := groups[accountID]; !ok {
+			accountOrder = append(accountOrder, accountID)
+		}
+		groups[accountID] = append(groups[accountID], msisdn)
+	}
+
+	batchID := uuid.New().String()
+	taskIDs := make([]uint, 0, len(accountOrder))
+
+	tx := database.DB.Begin()
+
+	for i, accountID := range accountOrder {
+		group := groups[accountID]
+		taskReq := req
+		taskReq.MSISDN, taskReq.CLI, taskReq.OldStatus, taskReq.OldRatePlan = "", "", "", ""
+		taskReq.Msisdns = group
+
+		now := time.Now()
+		task := &models.SyncTaskExtended{
+			Type:         req.Type,
+			Priority:     req.Priority,
+			Status:       models.TaskStatusPending,
+			TargetMSISDN: group[0],
+			AccountID:    accountID,
+			NewStatus:    req.NewStatus,
+			NewRatePlan:  req.NewRatePlan,
+			Payload:      buildPayload(taskReq),
+			UserID:       &req.UserID,
+			Username:     req.Username,
+			IPAddress:    req.IPAddress,
+			BatchID:      batchID,
+			BatchTotal:   len(accountOrder),
+			BatchIndex:   i + 1,
+			MaxAttempts:  5,
+			NextRunAt:    &now,
+		}
+		if i == 0 {
+			task.RequestID = req.RequestID
+		}
+
+		if err := tx.Create(task).Error; err != nil {
+			tx.Rollback()
+			return "", nil, fmt.Errorf("failed to create bulk task: %w", err)
+		}
+
+		chunks := newChunks(task, group, chunkSizeFor(accountID))
+		if err := tx.Create(&chunks).Error; err != nil {
+			tx.Rollback()
+			return "", nil, fmt.Errorf("failed to create chunks: %w", err)
+		}
+
+		log.Printf("[Queue] Created bulk task #%d: %s for %d SIMs in %d chunks (account %d)",
+			task.ID, task.Type, len(group), len(chunks), accountID)
+		taskIDs = append(taskIDs, task.ID)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return "", nil, fmt.Errorf("failed to commit bulk: %w", err)
+	}
+
+	return batchID, taskIDs, nil
+}
+
 // ─── ПОЛУЧЕНИЕ ЗАДАЧ ───────────────────────────────────────
 
 // GetPendingTasks возвращает задачи готовые к выполнению
@@ -269,7 +393,8 @@ func (s *QueueService) GetBatchTasks(batchID string) ([]models.SyncTaskExtended,
 	return tasks, err
 }
 
-// GetBatchProgress возвращает прогресс выполнения batch
+// GetBatchProgress возвращает прогресс выполнения batch.
+// Обычные задачи считаются по одной; bulk-задачи - по SIM в их частях.
 func (s *QueueService) GetBatchProgress(batchID string) (*models.BatchProgress, error) {
 	var tasks []models.SyncTaskExtended
 	if err := database.DB.Where("batch_id = ?", batchID).Find(&tasks).Error; err != nil {
@@ -282,15 +407,42 @@ func (s *QueueService) GetBatchProgress(batchID string) (*models.BatchProgress,
 
 	progress := &models.BatchProgress{
 		BatchID: batchID,
-		Total:   len(tasks),
+	}
+
+	if err := database.DB.Where("batch_id = ?", batchID).
+		Order("task_id ASC, chunk_index ASC").
+		Find(&progress.Chunks).Error; err != nil {
+		return nil, err
+	}
+	chunked := make(map[uint]bool)
+	for _, ch := range progress.Chunks {
+		chunked[ch.TaskID] = true
+		progress.Total += ch.Size
+		switch ch.Status {
+		case models.ChunkStatusCompleted:
+			progress.Completed += ch.Size - ch.Failed
+			progress.Failed += ch.Failed
+		case models.ChunkStatusFailed, models.ChunkStatusCancelled:
+			progress.Failed += ch.Size
+		case models.ChunkStatusSubmitted:
+			progress.AwaitingProvider += ch.Size
+		default:
+			progress.Pending += ch.Size
+		}
 	}
 
 	for _, t := range tasks {
+		if chunked[t.ID] {
+			continue
+		}
+		progress.Total++
 		switch t.Status {
 		case models.TaskStatusCompleted:
 			progress.Completed++
 		case models.TaskStatusFailed, models.TaskStatusCancelled:
 			progress.Failed++
+		case models.TaskStatusAwaitingProvider:
+			progress.AwaitingProvider++
 		default:
 			progress.Pending++
 		}
@@ -345,28 +497,81 @@ func (s *QueueService) MarkFailed(task *models.SyncTaskExtended, errMsg string)
 
 // CancelTask отменяет задачу (только свои задачи)
 func (s *QueueService) CancelTask(taskID, userID uint) error {
-	result := database.DB.Model(&models.SyncTaskExtended{}).
-		Where("id = ? AND user_id = ? AND status IN ?", taskID, userID,
-			[]models.TaskStatus{models.TaskStatusPending, models.TaskStatusProcessing}).
-		Update("status", models.TaskStatusCancelled)
-
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("task not found or cannot be cancelled")
-	}
-	return result.Error
+	return s.cancelTask(taskID, "user_id = ?", userID)
 }
 
 // CancelTaskAdmin отменяет задачу (для админа - любую)
 func (s *QueueService) CancelTaskAdmin(taskID uint) error {
-	result := database.DB.Model(&models.SyncTaskExtended{}).
-		Where("id = ? AND status IN ?", taskID,
-			[]models.TaskStatus{models.TaskStatusPending, models.TaskStatusProcessing}).
-		Update("status", models.TaskStatusCancelled)
+	return s.cancelTask(taskID, "1 = 1")
+}
 
-	if result.RowsAffected == 0 {
+// cancelTask отменяет задачу PENDING или PROCESSING, удовлетворяющую scope
+func (s *QueueService) cancelTask(taskID uint, scope string, args ...interface{}) error {
+	var cancelledFrom models.TaskStatus
+	for _, status := range []models.TaskStatus{models.TaskStatusPending, models.TaskStatusProcessing} {
+		result := database.DB.Model(&models.SyncTaskExtended{}).
+			Where("id = ? AND status = ?", taskID, status).
+			Where(scope, args...).
+			Update("status", models.TaskStatusCancelled)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			cancelledFrom = status
+			break
+		}
+	}
+	if cancelledFrom == "" {
 		return fmt.Errorf("task not found or cannot be cancelled")
 	}
-	return result.Error
+
+	s.abortInFlight(taskID)
+	s.CloseChunks(taskID, models.ChunkStatusCancelled, "task cancelled")
+
+	// ICCID отменённого SIM_SWAP возвращаем на склад, только если запрос ещё не уходил:
+	// задача PROCESSING могла уже выполниться у провайдера - ICCID остаётся RESERVED до сверки
+	if cancelledFrom != models.TaskStatusPending {
+		log.Printf("[Queue] Task #%d cancelled while PROCESSING: reserved ICCID (if any) is kept", taskID)
+		return nil
+	}
+	if err := Inventory.ReleaseForTask(taskID); err != nil {
+		log.Printf("[Queue] Task #%d: failed to release reserved ICCID: %v", taskID, err)
+	}
+	return nil
+}
+
+// ─── ВЫПОЛНЯЕМЫЕ ЗАДАЧИ ────────────────────────────────────
+
+// TrackInFlight регистрирует задачу, которую worker начал выполнять.
+// cancel прерывает её запрос к провайдеру, если задачу отменят.
+func (s *QueueService) TrackInFlight(taskID uint, cancel context.CancelFunc) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+
+	if s.inflight == nil {
+		s.inflight = make(map[uint]context.CancelFunc)
+	}
+	s.inflight[taskID] = cancel
+}
+
+// UntrackInFlight снимает задачу с учёта после завершения обработки
+func (s *QueueService) UntrackInFlight(taskID uint) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+
+	delete(s.inflight, taskID)
+}
+
+// abortInFlight прерывает выполнение отменённой задачи, если worker её обрабатывает
+func (s *QueueService) abortInFlight(taskID uint) {
+	s.inflightMu.Lock()
+	cancel, ok := s.inflight[taskID]
+	s.inflightMu.Unlock()
+
+	if ok {
+		log.Printf("[Queue] Task #%d: aborting in-flight provider request", taskID)
+		cancel()
+	}
 }
 
 // ─── СТАТИСТИКА ────────────────────────────────────────────
@@ -382,6 +587,8 @@ func (s *QueueService) GetStats() (*models.QueueStats, error) {
 		models.TaskStatusCompleted:  &stats.Completed,
 		models.TaskStatusFailed:     &stats.Failed,
 		models.TaskStatusCancelled:  &stats.Cancelled,
+
+		models.TaskStatusAwaitingProvider: &stats.AwaitingProvider,
 	}
 
 	for status, counter := range statuses {
@@ -6,12 +6,19 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"eyeson-go-server/internal/config"
 	"eyeson-go-server/internal/database"
 	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
 
 	"gorm.io/gorm"
 )
@@ -77,3 +84,216 @@ func ResolveUpstreamBaseURL(cfg *config.Config, selected UpstreamSelection) stri
 	}
 	return strings.TrimSpace(cfg.ApiBaseUrl)
 }
+
+// ═══════════════════════════════════════════════════════════
+// ПЕРЕКЛЮЧЕНИЕ UPSTREAM НА ЛЕТУ
+// ═══════════════════════════════════════════════════════════
+
+// UpstreamDrainTimeout - сколько переключение ждёт задачу worker'а, прежде чем прервать её
+// (прерванная задача возвращается в PENDING и выполнится уже на новом upstream)
+const UpstreamDrainTimeout = 30 * time.Second
+
+// upstreamGate разделяет обращения к upstream и его переключение. Задачи worker'а,
+// циклы syncer'а и reconciler'а входят через Enter; Switch ждёт, пока они выйдут,
+// а новые обращения ждут окончания переключения.
+type upstreamGate struct {
+	mu        sync.RWMutex
+	switching atomic.Bool
+
+	usesMu  sync.Mutex
+	uses    map[uint64]gateUse
+	nextUse uint64
+}
+
+type gateUse struct {
+	cancel context.CancelFunc
+	drain  bool
+}
+
+// UpstreamGate - общий шлюз переключения upstream
+var UpstreamGate = &upstreamGate{}
+
+// Enter начинает работу с upstream и возвращает её контекст и release.
+// drain=true - переключение дождётся окончания (задача worker'а); false - прервёт
+// её сразу (цикл синхронизации повторится по расписанию).
+func (g *upstreamGate) Enter(ctx context.Context, drain bool) (context.Context, func()) {
+	g.mu.RLock()
+	ctx, cancel := context.WithCancel(ctx)
+
+	g.usesMu.Lock()
+	if g.uses == nil {
+		g.uses = make(map[uint64]gateUse)
+	}
+	g.nextUse++
+	id := g.nextUse
+	g.uses[id] = gateUse{cancel: cancel, drain: drain}
+	g.usesMu.Unlock()
+
+	// Переключение уже началось - прерываемой работе начинать незачем
+	if !drain && g.switching.Load() {
+		cancel()
+	}
+
+	return ctx, func() {
+		g.usesMu.Lock()
+		delete(g.uses, id)
+		g.usesMu.Unlock()
+		cancel()
+		g.mu.RUnlock()
+	}
+}
+
+// Switching - идёт переключение upstream
+func (g *upstreamGate) Switching() bool {
+	return g.switching.Load()
+}
+
+// cancelUses прерывает текущие обращения; drained=true - включая задачи worker'а
+func (g *upstreamGate) cancelUses(drained bool) int {
+	g.usesMu.Lock()
+	defer g.usesMu.Unlock()
+
+	n := 0
+	for _, use := range g.uses {
+		if use.drain && !drained {
+			continue
+		}
+		use.cancel()
+		n++
+	}
+	return n
+}
+
+// Switch выполняет fn, когда с upstream никто не работает: циклы syncer'а/reconciler'а
+// прерываются сразу, задача worker'а - после drainTimeout. Возвращает, пришлось ли прерывать задачи.
+func (g *upstreamGate) Switch(drainTimeout time.Duration, fn func() error) (bool, error) {
+	g.switching.Store(true)
+	defer g.switching.Store(false)
+
+	g.cancelUses(false)
+
+	locked := make(chan struct{})
+	go func() {
+		g.mu.Lock()
+		close(locked)
+	}()
+
+	aborted := false
+	timer := time.NewTimer(drainTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+wait:
+	for {
+		select {
+		case <-locked:
+			break wait
+		case <-timer.C:
+			log.Printf("[Upstream] Drain timeout (%s) - aborting in-flight tasks", drainTimeout)
+			aborted = g.cancelUses(true) > 0 || aborted
+		case <-ticker.C:
+			// Прерываемая работа могла войти уже после начала переключения
+			g.cancelUses(aborted)
+		}
+	}
+	defer g.mu.Unlock()
+
+	return aborted, fn()
+}
+
+// UpstreamSwitchResult - итог переключения upstream
+type UpstreamSwitchResult struct {
+	From       UpstreamSelection `json:"from"`
+	To         UpstreamSelection `json:"to"`
+	BaseURL    string            `json:"base_url"`
+	Provider   string            `json:"provider"`
+	Aborted    bool              `json:"aborted_in_flight"` // Задачи прерваны по таймауту и вернулись в очередь
+	LoggedIn   bool              `json:"logged_in"`
+	LoginError string            `json:"login_error,omitempty"`
+	Catalog    bool              `json:"catalog_refreshed"`
+	DurationMs int64             `json:"duration_ms"`
+}
+
+// SwitchUpstream переключает upstream без перезапуска: дожидается текущей задачи worker'а,
+// сохраняет выбор, пересоздаёт клиентов аккаунтов на новом адресе и заново входит аккаунтом
+// по умолчанию. Возвращает нового провайдера аккаунта по умолчанию.
+func SwitchUpstream(ctx context.Context, cfg *config.Config, selected UpstreamSelection) (*UpstreamSwitchResult, error) {
+	from, err := GetUpstreamSelected()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result := &UpstreamSwitchResult{From: from, To: selected, BaseURL: ResolveUpstreamBaseURL(cfg, selected)}
+
+	aborted, err := UpstreamGate.Switch(UpstreamDrainTimeout, func() error {
+		if err := SetUpstreamSelected(selected); err != nil {
+			return fmt.Errorf("failed to save upstream selection: %w", err)
+		}
+		Accounts.SetBaseURL(result.BaseURL)
+
+		// Хэши страниц инкрементальной синхронизации относятся к старому upstream:
+		// без них следующий плановый цикл обойдёт каждый аккаунт полностью
+		if err := database.DB.Where("1 = 1").Delete(&models.SyncPage{}).Error; err != nil {
+			log.Printf("[Upstream] Failed to reset sync page state: %v", err)
+		}
+
+		p, err := Accounts.Provider(0)
+		if err != nil {
+			return fmt.Errorf("failed to create upstream client: %w", err)
+		}
+		result.Provider = p.Name()
+
+		// Глобальный провайдер (статус API, каталог, jobs) - клиент нового аккаунта по умолчанию.
+		// Меняется, пока шлюз закрыт, поэтому новые обращения сразу идут к новому upstream.
+		provider.SetActive(p)
+
+		if session, ok := p.(provider.Session); ok {
+			if err := session.Login(ctx); err != nil {
+				// Не фатально: клиент войдёт при первом запросе, когда upstream ответит
+				result.LoginError = err.Error()
+				log.Printf("[Upstream] Login after switch failed: %v", err)
+			} else {
+				result.LoggedIn = true
+			}
+		}
+
+		// Каталоги параметров у upstream'ов разные - иначе проверки шли бы по старым
+		result.Catalog = refreshCatalogs(ctx)
+		return nil
+	})
+	result.Aborted = aborted
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		return result, err
+	}
+
+	log.Printf("[Upstream] Switched %s -> %s (BaseURL=%s) in %dms", from, selected, result.BaseURL, result.DurationMs)
+	return result, nil
+}
+
+// refreshCatalogs перечитывает каталог каждого включённого аккаунта; false - хотя бы
+// один аккаунт остался со старой копией
+func refreshCatalogs(ctx context.Context) bool {
+	accounts, err := Accounts.EnabledAccounts()
+	if err != nil {
+		log.Printf("[Upstream] Catalog refresh after switch failed: %v", err)
+		return false
+	}
+
+	ok := true
+	for _, acc := range accounts {
+		p, err := Accounts.Provider(acc.ID)
+		if err == nil {
+			var params *models.GetProvisioningParameterListResponse
+			if params, err = p.GetParameters(ctx); err == nil {
+				err = Catalog.Store(acc.ID, params)
+			}
+		}
+		if err != nil {
+			log.Printf("[Upstream] Account #%d: catalog refresh after switch failed, keeping cached copy: %v", acc.ID, err)
+			ok = false
+		}
+	}
+	return ok
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package syncer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════
+// CRON-ВЫРАЖЕНИЯ РАСПИСАНИЯ
+// ═══════════════════════════════════════════════════════════
+//
+// Поддерживается стандартный формат из 5 полей "минута час день месяц день_недели":
+// "*", "*/n", "a-b", "a-b/n", "a/n" и списки через запятую; день недели 0-7 (0 и 7 - воскресенье).
+// Если ограничены и день месяца, и день недели, подходит любой из них (как в cron).
+// Дополнительно: "@every <duration>" (например, "@every 5m"), @hourly, @daily.
+
+// cronSearchLimit - дальше этого срока следующий запуск не ищется (выражение вроде "0 0 30 2 *")
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+type cronSchedule struct {
+	every time.Duration // @every; поля ниже не используются
+
+	minute, hour, dom, month, dow uint64 // Битовые маски допустимых значений
+	domAny, dowAny                bool
+}
+
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+}
+
+// parseCron разбирает выражение расписания
+func parseCron(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	if strings.HasPrefix(strings.ToLower(expr), "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(expr[len("@every "):]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid @every interval: %w", err)
+		}
+		if every < time.Minute {
+			return nil, fmt.Errorf("@every interval must be at least 1m")
+		}
+		return &cronSchedule{every: every}, nil
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields (minute hour day month weekday), got %d", len(fields))
+	}
+
+	sched := &cronSchedule{}
+	var err error
+	if sched.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if sched.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if sched.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if sched.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if sched.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if sched.dow&(1<<7) != 0 {
+		sched.dow |= 1 // 7 - тоже воскресенье
+	}
+	// Как в cron: поле, начинающееся с "*" (в т.ч. "*/2"), не ограничивает день для правила "или"
+	sched.domAny = strings.HasPrefix(fields[2], "*") || fields[2] == "?"
+	sched.dowAny = strings.HasPrefix(fields[4], "*") || fields[4] == "?"
+	return sched, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangePart = part[:i]
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			a, errA := strconv.Atoi(bounds[0])
+			b, errB := strconv.Atoi(bounds[1])
+			if errA != nil || errB != nil || a > b {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+			lo, hi = a, b
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", rangePart)
+			}
+			lo = n
+			if step == 1 {
+				hi = n // "5" - одно значение, "5/10" - с 5 до конца шагом 10
+			}
+		}
+		if lo < min || hi > max {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+// Next - первый момент расписания строго после after (в часовом поясе after);
+// нулевое время - подходящего момента нет
+func (c *cronSchedule) Next(after time.Time) time.Time {
+	if c.every > 0 {
+		return after.Add(c.every).Truncate(time.Minute)
+	}
+
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(cronSearchLimit)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches - правило cron: если одно из полей дня начинается с "*", должны совпасть оба
+// (так "*/10" по-прежнему ограничивает день месяца), иначе достаточно любого
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	domOK := c.dom&(1<<uint(t.Day())) != 0
+	dowOK := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package syncer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"empty", ""},
+		{"four fields", "* * * *"},
+		{"six fields", "0 * * * * *"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "0 24 * * *"},
+		{"day of month zero", "0 0 0 * *"},
+		{"weekday out of range", "0 0 * * 8"},
+		{"zero step", "*/0 * * * *"},
+		{"reversed range", "30-10 * * * *"},
+		{"not a number", "a * * * *"},
+		{"every too short", "@every 30s"},
+		{"every invalid", "@every soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseCron(tt.expr); err == nil {
+				t.Fatalf("parseCron(%q): expected error", tt.expr)
+			}
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Пятница, 16 октября 2026
+	after := time.Date(2026, 10, 16, 10, 17, 30, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", at(10, 16, 10, 30)},
+		{"10/20 * * * *", at(10, 16, 10, 30)},
+		{"0 * * * *", at(10, 16, 11, 0)},
+		{"@hourly", at(10, 16, 11, 0)},
+		{"@daily", at(10, 17, 0, 0)},
+		{"30 2 * * *", at(10, 17, 2, 30)},
+		{"17 10 * * *", at(10, 17, 10, 17)}, // Строго после after
+		{"0,45 10-11 * * *", at(10, 16, 10, 45)},
+		{"0 9 * * 1-5", at(10, 19, 9, 0)}, // Пятница 9:00 прошла - понедельник
+		{"0 0 * * 0", at(10, 18, 0, 0)},
+		{"0 0 * * 7", at(10, 18, 0, 0)}, // 7 - тоже воскресенье
+		{"0 0 1 * *", at(11, 1, 0, 0)},
+		{"0 0 1 * 5", at(10, 23, 0, 0)}, // День месяца или день недели
+		{"0 0 */10 * *", at(10, 21, 0, 0)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}}, // Такого дня нет
+		{"@every 5m", at(10, 16, 10, 22)},
+		{"@every 2h", at(10, 16, 12, 17)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			sched, err := parseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("parseCron(%q): %v", tt.expr, err)
+			}
+			if got := sched.Next(after); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", after.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestCronNextKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("IST", 2*60*60)
+	sched, err := parseCron("0 3 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := time.Date(2026, 10, 16, 12, 0, 0, 0, loc)
+	want := time.Date(2026, 10, 17, 3, 0, 0, 0, loc)
+	if got := sched.Next(after); !got.Equal(want) || got.Location() != loc {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
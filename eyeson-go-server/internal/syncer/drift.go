@@ -0,0 +1,180 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package syncer
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/models"
+)
+
+// ═══════════════════════════════════════════════════════════
+// ОТЧЁТ О РАСХОЖДЕНИЯХ (DRY-RUN СИНХРОНИЗАЦИИ)
+// ═══════════════════════════════════════════════════════════
+//
+// Drift делает полный обход провайдера той же загрузкой страниц (walkPages) и той же
+// сверкой (compareBatch), что и синхронизация, но ничего не пишет: ни SimCard, ни SimHistory,
+// ни SyncRun/SyncPage. Поля задач не защищаются - отчёт показывает значения провайдера как
+// есть. SIM, отсутствующие у провайдера, ищутся только по аккаунтам с полным обходом.
+
+// DriftSim - SIM, которая есть только с одной стороны
+type DriftSim struct {
+	MSISDN    string `json:"msisdn"`
+	AccountID uint   `json:"account_id"`
+	Status    string `json:"status"`
+	ICCID     string `json:"iccid,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+// DriftMismatch - поле SIM, значение которого в локальной БД отличается от провайдера
+type DriftMismatch struct {
+	MSISDN    string            `json:"msisdn"`
+	AccountID uint              `json:"account_id"`
+	Field     string            `json:"field"`  // Ключ поля: STATUS, RATE_PLAN, LABEL_1...
+	Policy    models.DiffPolicy `json:"policy"` // history - синхронизация запишет историю, update - нет
+	Local     string            `json:"local"`
+	Upstream  string            `json:"upstream"`
+}
+
+// DriftReport - результат сверки локальной БД с провайдером
+type DriftReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	DurationMs  int64     `json:"duration_ms"`
+	// Complete - все аккаунты обойдены полностью; иначе MissingUpstream неполон
+	Complete bool   `json:"complete"`
+	Accounts int    `json:"accounts"`
+	Errors   int    `json:"errors"`
+	Error    string `json:"error,omitempty"`
+
+	UpstreamSIMs int `json:"upstream_sims"` // SIM у провайдера
+	Matching     int `json:"matching"`      // Совпадают по всем полям
+
+	MissingLocally  []DriftSim      `json:"missing_locally"`
+	MissingUpstream []DriftSim      `json:"missing_upstream"`
+	Mismatches      []DriftMismatch `json:"mismatches"`
+}
+
+// Drift сверяет локальную БД с провайдером без записи (dry-run полного обхода)
+func (s *Syncer) Drift(ctx context.Context) (*DriftReport, error) {
+	targets := s.targets()
+	if len(targets) == 0 {
+		return nil, &eyesont.APIError{Kind: eyesont.ErrKindCircuitOpen, Op: "Drift", Message: "no upstream accounts available", Retryable: true}
+	}
+
+	ctx = eyesont.WithBulk(ctx)
+	report := &DriftReport{
+		GeneratedAt:     time.Now(),
+		Complete:        true,
+		Accounts:        len(targets),
+		MissingLocally:  []DriftSim{},
+		MissingUpstream: []DriftSim{},
+		Mismatches:      []DriftMismatch{},
+	}
+	// Временный цикл: собирает ошибки и метрики upstream, в БД не сохраняется
+	run := &syncRun{SyncRun: models.SyncRun{Mode: models.SyncModeFull, StartedAt: report.GeneratedAt}}
+
+	for _, t := range targets {
+		if err := s.driftAccount(ctx, t, run, report); err != nil {
+			report.Complete = false
+			if eyesont.IsCanceled(err) {
+				return nil, err
+			}
+		}
+	}
+
+	report.Errors = run.Errors
+	report.Error = run.Error
+	report.DurationMs = time.Since(report.GeneratedAt).Milliseconds()
+	sort.Slice(report.Mismatches, func(i, j int) bool {
+		a, b := report.Mismatches[i], report.Mismatches[j]
+		if a.MSISDN != b.MSISDN {
+			return a.MSISDN < b.MSISDN
+		}
+		return a.Field < b.Field
+	})
+	log.Printf("[Syncer] Drift: %d upstream SIMs, %d missing locally, %d missing upstream, %d field mismatches",
+		report.UpstreamSIMs, len(report.MissingLocally), len(report.MissingUpstream), len(report.Mismatches))
+	return report, nil
+}
+
+// driftAccount сверяет один аккаунт; ошибка - обход неполный
+func (s *Syncer) driftAccount(ctx context.Context, t syncTarget, run *syncRun, report *DriftReport) error {
+	seen := make(map[string]bool)
+	var batchErr error
+
+	_, err := s.walkPages(ctx, t, run, func(page walkedPage) {
+		for _, d := range page.Data {
+			seen[d.MSISDN] = true
+		}
+		report.UpstreamSIMs += len(page.Data)
+
+		plan, err := s.compareBatch(page.Data, t, nil)
+		if err != nil {
+			run.AddError(err)
+			batchErr = err
+			return
+		}
+		for _, sim := range plan.toCreate {
+			report.MissingLocally = append(report.MissingLocally, driftSim(sim, ""))
+		}
+		revived := make(map[string]bool, len(plan.revived))
+		for _, sim := range plan.revived {
+			revived[sim.MSISDN] = true
+			report.MissingLocally = append(report.MissingLocally, driftSim(sim, "deleted locally"))
+		}
+		mismatched := 0
+		for msisdn, diff := range plan.diffs {
+			if revived[msisdn] {
+				continue // Удалённая SIM уже в MissingLocally
+			}
+			mismatched++
+			for _, ch := range diff {
+				report.Mismatches = append(report.Mismatches, DriftMismatch{
+					MSISDN:    msisdn,
+					AccountID: t.AccountID,
+					Field:     ch.Key,
+					Policy:    ch.Policy,
+					Local:     ch.Old,
+					Upstream:  ch.New,
+				})
+			}
+		}
+		report.Matching += len(page.Data) - len(plan.toCreate) - len(plan.revived) - mismatched
+	})
+	if err == nil {
+		err = batchErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// Обход полный: локальные SIM аккаунта, которых не было ни на одной странице
+	var local []models.SimCard
+	if err := s.DB.Select("id", "msisdn", "account_id", "status", "icc_id", "missing_since").
+		Where("account_id = ?", t.AccountID).Find(&local).Error; err != nil {
+		run.AddError(err)
+		return err
+	}
+	for _, sim := range local {
+		if seen[sim.MSISDN] {
+			continue
+		}
+		note := ""
+		if sim.MissingSince != nil {
+			note = "missing since " + sim.MissingSince.Format(time.RFC3339)
+		}
+		report.MissingUpstream = append(report.MissingUpstream, driftSim(sim, note))
+	}
+	return nil
+}
+
+func driftSim(sim models.SimCard, note string) DriftSim {
+	return DriftSim{MSISDN: sim.MSISDN, AccountID: sim.AccountID, Status: sim.Status, ICCID: sim.ICCID, Note: note}
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package syncer
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+)
+
+// ═══════════════════════════════════════════════════════════
+// ПАРАЛЛЕЛЬНАЯ ЗАГРУЗКА СТРАНИЦ
+// ═══════════════════════════════════════════════════════════
+//
+// Полный обход запрашивает первую страницу, узнаёт из Count число абонентов и раздаёт
+// остальные страницы пулу загрузчиков. Темп запросов по-прежнему задаёт rate limiter
+// клиента (bulk-полоса), пул лишь не даёт ждать каждый ответ по очереди. walkPages отдаёт
+// страницы одному обработчику строго по порядку (в syncAccount - writer в БД), поэтому
+// processBatch, recordPage, seen и счётчики цикла остаются однопоточными. Страница, которая
+// не загрузилась и после повторов, записывается в ошибки цикла; остальные страницы
+// обрабатываются дальше.
+
+const (
+	// DefaultFetchWorkers - загрузчиков страниц на аккаунт по умолчанию
+	DefaultFetchWorkers = 4
+
+	// pageRetries - повторы страницы после ошибки (пауза растёт: 2s, 4s)
+	pageRetries = 2
+)
+
+var (
+	fetchMu      sync.RWMutex
+	fetchWorkers = DefaultFetchWorkers
+)
+
+// ConfigureFetch задаёт число загрузчиков страниц на аккаунт (< 1 - одна страница за раз)
+func ConfigureFetch(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	fetchMu.Lock()
+	fetchWorkers = workers
+	fetchMu.Unlock()
+}
+
+func currentFetchWorkers() int {
+	fetchMu.RLock()
+	defer fetchMu.RUnlock()
+	return fetchWorkers
+}
+
+// walkedPage - страница обхода для обработчика walkPages
+type walkedPage struct {
+	Start int
+	Size  int // Шаг страниц (фактический лимит API)
+	Total int // Count из первого ответа
+	Data  []models.SimData
+}
+
+// walkPages обходит все страницы аккаунта и вызывает handle для непустых страниц по порядку.
+// Возвращает конец полученных данных и ошибку: отмену, сбой первой страницы или последний
+// сбой страницы после повторов (обход тогда неполный).
+func (s *Syncer) walkPages(ctx context.Context, t syncTarget, run *syncRun, handle func(walkedPage)) (int, error) {
+	limit := 200 // Fetch 200 at a time, as API might have its own cap
+
+	// Первая страница: Count - сколько всего абонентов, размер ответа - фактический лимит API
+	first, err := s.fetchPageRetry(ctx, t, 0, limit, run)
+	if err != nil {
+		if eyesont.IsCanceled(err) {
+			log.Printf("[Syncer] Account #%d: full sync cancelled", t.AccountID)
+		} else {
+			log.Println("[Syncer] API unavailable - will retry on next cycle")
+			run.AddError(err)
+		}
+		return 0, err
+	}
+
+	pageSize := limit
+	if n := len(first.Data); n > 0 && n < limit && first.Count > n {
+		pageSize = n // API отдаёт меньше запрошенного - шагаем по фактическому размеру страницы
+	}
+	total := first.Count
+	if total <= 0 && len(first.Data) == pageSize {
+		total = -1 // Count не пришёл - страницы идут до первой пустой
+	}
+
+	end := 0
+	var lastErr error
+	emit := func(start int, data []models.SimData) {
+		handle(walkedPage{Start: start, Size: pageSize, Total: first.Count, Data: data})
+		end = start + len(data)
+	}
+
+	if len(first.Data) > 0 {
+		emit(0, first.Data)
+	}
+	if len(first.Data) < pageSize || (total >= 0 && pageSize >= total) {
+		return end, nil
+	}
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	order, wait := s.fetchPages(fetchCtx, t, pageSize, pageSize, total, run)
+	// Сначала отмена, потом ожидание: при раннем выходе раздача и загрузчики ещё ждут writer
+	defer func() {
+		cancel()
+		wait()
+	}()
+	for result := range order {
+		page := <-result
+		if s.IsPaused() {
+			break
+		}
+		if page.err != nil {
+			if err := ctx.Err(); err != nil {
+				log.Printf("[Syncer] Account #%d: full sync cancelled after %d records", t.AccountID, end)
+				return end, err
+			}
+			log.Printf("[Syncer] Account #%d: page at %d failed: %v", t.AccountID, page.start, page.err)
+			run.AddError(page.err)
+			lastErr = page.err
+			if !provider.IsAvailable(t.Provider) {
+				log.Println("[Syncer] API unavailable - will retry on next cycle")
+				break
+			}
+			continue // Остальные страницы обрабатываем; обход не будет полным
+		}
+		if len(page.resp.Data) == 0 {
+			break // Абонентов стало меньше, чем в Count - дальше пусто
+		}
+		emit(page.start, page.resp.Data)
+	}
+	return end, lastErr
+}
+
+// fetchedPage - результат загрузки одной страницы
+type fetchedPage struct {
+	start int
+	resp  *models.GetProvisioningDataResponse
+	err   error
+}
+
+// pageJob - страница для загрузчика; результат приходит в result (буфер 1, загрузчик не ждёт writer)
+type pageJob struct {
+	start  int
+	result chan fetchedPage
+}
+
+// fetchPages загружает страницы начиная с first шагом pageSize до total (total < 0 - до пустой
+// страницы, тогда по одной). Каналы результатов выдаются в порядке страниц; загрузка опережает
+// writer не больше чем на workers страниц. Отмена ctx останавливает раздачу и загрузчиков;
+// wait дожидается их завершения.
+func (s *Syncer) fetchPages(ctx context.Context, t syncTarget, first, pageSize, total int, run *syncRun) (order <-chan chan fetchedPage, wait func()) {
+	workers := currentFetchWorkers()
+	if total < 0 {
+		workers = 1 // Конец неизвестен: без опережения, чтобы не запрашивать страницы за концом
+	}
+
+	jobs := make(chan pageJob)
+	results := make(chan chan fetchedPage, workers)
+
+	go func() {
+		defer close(jobs)
+		defer close(results)
+		for start := first; total < 0 || start < total; start += pageSize {
+			job := pageJob{start: start, result: make(chan fetchedPage, 1)}
+			select {
+			case results <- job.result:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				job.result <- fetchedPage{start: start, err: ctx.Err()}
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				resp, err := s.fetchPageRetry(ctx, t, job.start, pageSize, run)
+				job.result <- fetchedPage{start: job.start, resp: resp, err: err}
+			}
+		}()
+	}
+	return results, wg.Wait
+}
+
+// fetchPageRetry - fetchPage с повторами: разовый сбой страницы не прерывает обход
+func (s *Syncer) fetchPageRetry(ctx context.Context, t syncTarget, start, limit int, run *syncRun) (*models.GetProvisioningDataResponse, error) {
+	var lastErr error
+	for attempt := 0; attempt <= pageRetries; attempt++ {
+		if attempt > 0 {
+			log.Printf("[Syncer] Account #%d: retrying page at %d (attempt %d/%d)", t.AccountID, start, attempt, pageRetries)
+			eyesont.SleepContext(ctx, time.Duration(attempt)*2*time.Second)
+		}
+		resp, err := s.fetchPage(ctx, t, start, limit, run)
+		if err == nil {
+			return resp, nil
+		}
+		if eyesont.IsCanceled(err) || ctx.Err() != nil {
+			return nil, err
+		}
+		if !provider.IsAvailable(t.Provider) {
+			return nil, err // Circuit breaker открыт - повторы бессмысленны
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package syncer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ═══════════════════════════════════════════════════════════
+// ИНКРЕМЕНТАЛЬНАЯ СИНХРОНИЗАЦИЯ
+// ═══════════════════════════════════════════════════════════
+//
+// Полный обход getProvisioningData каждые 5 минут тяжёл для портала за WAF на больших
+// парках. Поэтому полный обход идёт раз в FullInterval, а плановые циклы между ними
+// инкрементальные: каждая страница хранит хэш отслеживаемых полей (models.SyncPage), и цикл
+// запрашивает страницы, которые недавно менялись. Страница, не менявшаяся stablePageChecks
+// проверок подряд, считается стабильной: такие страницы проверяются по очереди, каждая не
+// реже раза в stableRecheckCycles циклов, - правка в портале не ждёт полного обхода.
+// Первая страница запрашивается всегда - по ней видно, не изменилось ли число абонентов.
+
+const (
+	// DefaultInterval - период плановой синхронизации по умолчанию
+	DefaultInterval = 5 * time.Minute
+
+	// stablePageChecks - после стольких проверок без изменений страница считается стабильной
+	stablePageChecks = 2
+
+	// stableRecheckCycles - за столько инкрементальных циклов перепроверяются все стабильные страницы
+	stableRecheckCycles = 3
+)
+
+// errFullRequired - инкрементальный цикл для аккаунта невозможен, нужен полный обход
+type errFullRequired struct{ reason string }
+
+func (e *errFullRequired) Error() string { return "full sync required: " + e.reason }
+
+func (s *Syncer) interval() time.Duration {
+	if s.Interval > 0 {
+		return s.Interval
+	}
+	return DefaultInterval
+}
+
+// tracksPages - состояние страниц ведёт только плановая синхронизация активного upstream;
+// ручная (явный Provider, всегда Pelephone) может смотреть в другой upstream
+func (s *Syncer) tracksPages() bool {
+	return s.Provider == nil
+}
+
+// nextMode выбирает режим планового цикла: полный, если прошло FullInterval с последнего
+// полного планового обхода (или его не было), иначе инкрементальный. Ручные обходы не в
+// счёт - они не ведут состояние страниц.
+func (s *Syncer) nextMode() (string, string) {
+	if s.FullInterval <= 0 {
+		return models.SyncModeFull, "incremental sync disabled"
+	}
+
+	var last models.SyncRun
+	err := s.DB.Where("sync_trigger = ? AND complete = ?", models.SyncTriggerScheduled, true).
+		Order("started_at DESC").First(&last).Error
+	if err != nil {
+		return models.SyncModeFull, "no previous full sweep"
+	}
+	if time.Since(last.StartedAt) >= s.FullInterval {
+		return models.SyncModeFull, fmt.Sprintf("full sweep interval elapsed (%v)", s.FullInterval)
+	}
+	return models.SyncModeIncremental, "scheduled"
+}
+
+// syncIncremental запрашивает первую и нестабильные страницы аккаунта. Если состояние
+// страниц не годится (его нет, изменилось число абонентов, сдвинулись границы) -
+// аккаунт синхронизируется полным обходом.
+func (s *Syncer) syncIncremental(ctx context.Context, t syncTarget, run *syncRun) (int, error) {
+	processed, err := s.syncChangedPages(ctx, t, run)
+	var full *errFullRequired
+	if !errors.As(err, &full) {
+		log.Printf("[Syncer] Account #%d: incremental sync processed %d records", t.AccountID, processed)
+		return processed, err
+	}
+
+	log.Printf("[Syncer] Account #%d: %v - running full sweep", t.AccountID, err)
+	run.FullFallbacks++
+	fullProcessed, err := s.syncAccount(ctx, t, run)
+	return processed + fullProcessed, err
+}
+
+func (s *Syncer) syncChangedPages(ctx context.Context, t syncTarget, run *syncRun) (int, error) {
+	var pages []models.SyncPage
+	if err := s.DB.Where("account_id = ?", t.AccountID).Order("page_start").Find(&pages).Error; err != nil {
+		return 0, err
+	}
+	if len(pages) == 0 || pages[0].PageStart != 0 {
+		return 0, &errFullRequired{"no page state"}
+	}
+
+	recheck := stableRotation(pages)
+	processed := 0
+	for i, page := range pages {
+		if s.IsPaused() {
+			break
+		}
+		if i > 0 && page.Unchanged >= stablePageChecks && !recheck[page.ID] {
+			run.PagesSkipped++
+			continue
+		}
+
+		resp, err := s.fetchPage(ctx, t, page.PageStart, page.PageSize, run)
+		if err != nil {
+			run.AddError(err)
+			return processed, err
+		}
+		run.PagesFetched++
+
+		if resp.Count != page.Total {
+			return processed, &errFullRequired{fmt.Sprintf("subscriber count changed (%d -> %d)", page.Total, resp.Count)}
+		}
+		if len(resp.Data) == 0 {
+			return processed, &errFullRequired{fmt.Sprintf("page at %d is empty", page.PageStart)}
+		}
+		if pageHash(resp.Data) == page.Hash {
+			run.PagesUnchanged++
+			s.DB.Model(&models.SyncPage{}).Where("id = ?", page.ID).Updates(map[string]interface{}{
+				"unchanged":  gorm.Expr("unchanged + 1"),
+				"checked_at": time.Now(),
+			})
+			continue
+		}
+		if resp.Data[0].MSISDN != page.FirstMSISDN || resp.Data[len(resp.Data)-1].MSISDN != page.LastMSISDN {
+			return processed, &errFullRequired{fmt.Sprintf("page boundaries at %d shifted", page.PageStart)}
+		}
+
+		if err := s.processBatch(resp.Data, t, run); err != nil {
+			log.Printf("[Syncer] Error processing batch: %v", err)
+			run.AddError(err)
+			return processed, err
+		}
+		processed += len(resp.Data)
+		s.recordPage(t.AccountID, page.PageStart, page.PageSize, resp.Count, resp.Data)
+
+		// Small pause to be nice to the API
+		eyesont.SleepContext(ctx, 100*time.Millisecond)
+	}
+	return processed, nil
+}
+
+// stableRotation выбирает стабильные страницы, которые цикл всё же перепроверит: дольше всех
+// не проверявшиеся, по 1/stableRecheckCycles от числа стабильных (не меньше одной)
+func stableRotation(pages []models.SyncPage) map[uint]bool {
+	var stable []models.SyncPage
+	for i, page := range pages {
+		if i > 0 && page.Unchanged >= stablePageChecks {
+			stable = append(stable, page)
+		}
+	}
+	sort.SliceStable(stable, func(i, j int) bool {
+		return stable[i].CheckedAt.Before(stable[j].CheckedAt)
+	})
+
+	n := (len(stable) + stableRecheckCycles - 1) / stableRecheckCycles
+	recheck := make(map[uint]bool, n)
+	for _, page := range stable[:n] {
+		recheck[page.ID] = true
+	}
+	return recheck
+}
+
+// fetchPage запрашивает страницу, пропуская вперёд пользовательские задачи (как полный обход)
+func (s *Syncer) fetchPage(ctx context.Context, t syncTarget, start, limit int, run *syncRun) (*models.GetProvisioningDataResponse, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Частичная синхронизация очередь не ждёт: её запускают сразу после задач (и сам worker)
+		var pendingCount int64
+		if t.Search == nil {
+			s.DB.Model(&models.SyncTask{}).Where("status IN ?", []string{"PENDING", "PROCESSING"}).Count(&pendingCount)
+		}
+		if pendingCount > 0 {
+			eyesont.SleepContext(ctx, 2*time.Second)
+			continue
+		}
+
+		resp, err := s.listPage(ctx, t, start, limit, run)
+		if eyesont.IsCanceled(err) {
+			continue // выход - на проверке ctx в начале цикла
+		}
+		if err != nil {
+			log.Printf("[Syncer] Account #%d: error fetching SIMs: %v", t.AccountID, err)
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// recordPage сохраняет хэш и границы страницы; false - содержимое не изменилось с прошлой проверки
+func (s *Syncer) recordPage(accountID uint, start, size, total int, data []models.SimData) bool {
+	if len(data) == 0 {
+		return true
+	}
+	hash := pageHash(data)
+
+	var page models.SyncPage
+	err := s.DB.Where("account_id = ? AND page_start = ?", accountID, start).First(&page).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("[Syncer] Account #%d: failed to read page state: %v", accountID, err)
+		return true
+	}
+
+	changed := page.Hash != hash
+	if changed {
+		page.Unchanged = 0
+	} else {
+		page.Unchanged++
+	}
+	page.AccountID = accountID
+	page.PageStart = start
+	page.PageSize = size
+	page.Total = total
+	page.Hash = hash
+	page.FirstMSISDN = data[0].MSISDN
+	page.LastMSISDN = data[len(data)-1].MSISDN
+	page.CheckedAt = time.Now()
+	if err := s.DB.Save(&page).Error; err != nil {
+		log.Printf("[Syncer] Account #%d: failed to save page state: %v", accountID, err)
+	}
+	return changed
+}
+
+// pageHash - хэш страницы по MSISDN и полям, изменения которых синхронизация пишет в историю
+// (diff:"history"), в порядке выдачи. Usage и сессии меняются постоянно: с ними активная
+// страница никогда не становилась бы стабильной; их обновляют полный обход и изменённые страницы.
+func pageHash(data []models.SimData) string {
+	h := sha256.New()
+	for _, d := range data {
+		raw, _ := json.Marshal(append([]string{d.MSISDN}, mapApiToModel(d).HistoryValues()...))
+		h.Write(raw)
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
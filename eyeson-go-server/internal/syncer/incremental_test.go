@@ -0,0 +1,60 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package syncer
+
+import (
+	"testing"
+	"time"
+
+	"eyeson-go-server/internal/models"
+)
+
+func TestPageHash(t *testing.T) {
+	base := models.SimData{MSISDN: "0501", SimStatusChange: "Activated", CustomerLabel1: "a", MonthlyUsageMB: "10", InSession: "false"}
+
+	tests := []struct {
+		name    string
+		edit    func(d *models.SimData)
+		changed bool
+	}{
+		{"usage", func(d *models.SimData) { d.MonthlyUsageMB = "250" }, false},
+		{"session", func(d *models.SimData) { d.InSession = "true"; d.LastSessionTime = "2026-10-16 10:00:00" }, false},
+		{"status", func(d *models.SimData) { d.SimStatusChange = "Suspended" }, true},
+		{"label", func(d *models.SimData) { d.CustomerLabel1 = "b" }, true},
+		{"msisdn", func(d *models.SimData) { d.MSISDN = "0502" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := base
+			tt.edit(&d)
+			if got := pageHash([]models.SimData{d}) != pageHash([]models.SimData{base}); got != tt.changed {
+				t.Errorf("hash changed = %v, want %v", got, tt.changed)
+			}
+		})
+	}
+}
+
+func TestStableRotation(t *testing.T) {
+	now := time.Now()
+	pages := []models.SyncPage{
+		{ID: 1, Unchanged: 5, CheckedAt: now.Add(-time.Hour)}, // Первая страница запрашивается всегда
+		{ID: 2, Unchanged: 0, CheckedAt: now.Add(-time.Hour)}, // Нестабильная
+		{ID: 3, Unchanged: 2, CheckedAt: now.Add(-10 * time.Minute)},
+		{ID: 4, Unchanged: 3, CheckedAt: now.Add(-30 * time.Minute)},
+		{ID: 5, Unchanged: 2, CheckedAt: now.Add(-5 * time.Minute)},
+		{ID: 6, Unchanged: 4, CheckedAt: now.Add(-20 * time.Minute)},
+	}
+
+	// 4 стабильные страницы, треть с округлением вверх - две самые давние
+	got := stableRotation(pages)
+	if len(got) != 2 || !got[4] || !got[6] {
+		t.Errorf("stableRotation = %v, want pages 4 and 6", got)
+	}
+
+	if got := stableRotation(pages[:2]); len(got) != 0 {
+		t.Errorf("stableRotation without stable pages = %v, want none", got)
+	}
+}
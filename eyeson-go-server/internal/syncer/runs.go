@@ -0,0 +1,189 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package syncer
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// ═══════════════════════════════════════════════════════════
+// ИСТОРИЯ ЦИКЛОВ СИНХРОНИЗАЦИИ (SyncRun)
+// ═══════════════════════════════════════════════════════════
+
+// syncRunRetention - сколько хранятся записи о циклах (плановый цикл - каждые 5 минут)
+const syncRunRetention = 30 * 24 * time.Hour
+
+// syncRun - цикл синхронизации в процессе; счётчики копятся в памяти и пишутся в конце
+type syncRun struct {
+	models.SyncRun
+
+	upstreamMu sync.Mutex // upstream-счётчики пишут параллельные загрузчики страниц (fetch.go)
+
+	expected *services.ExpectedIndex // Ожидаемые значения задач (conflicts.go), собираются при первой странице
+}
+
+// AddError учитывает ошибку страницы или аккаунта; последняя остаётся в Error
+func (r *syncRun) AddError(err error) {
+	if err == nil || eyesont.IsCanceled(err) {
+		return
+	}
+	r.Errors++
+	r.Error = err.Error()
+}
+
+// trigger - ручная синхронизация задаёт Provider явно, плановая берёт аккаунты из services.Accounts;
+// Syncer.Trigger задаёт источник явно
+func (s *Syncer) trigger() string {
+	if s.Trigger != "" {
+		return s.Trigger
+	}
+	if s.Provider != nil {
+		return models.SyncTriggerManual
+	}
+	return models.SyncTriggerScheduled
+}
+
+// startRun создаёт запись RUNNING: незавершённый цикл виден в GET /api/v1/sync/runs
+func (s *Syncer) startRun(mode, reason string, targets []syncTarget) *syncRun {
+	run := &syncRun{SyncRun: models.SyncRun{
+		Trigger:   s.trigger(),
+		Mode:      mode,
+		Reason:    reason,
+		Status:    models.SyncRunRunning,
+		AccountID: s.AccountID,
+		Accounts:  len(targets),
+		Upstream:  targetUpstreams(targets),
+		StartedAt: time.Now(),
+	}}
+	if err := s.DB.Create(&run.SyncRun).Error; err != nil {
+		log.Printf("[Syncer] Failed to record sync run: %v", err)
+	}
+	s.DB.Where("started_at < ?", time.Now().Add(-syncRunRetention)).Delete(&models.SyncRun{})
+	return run
+}
+
+// finishRun дописывает итог цикла. Полный обход без ошибок отмечается Complete -
+// по нему видно, когда локальная БД последний раз совпадала с upstream.
+func (s *Syncer) finishRun(run *syncRun, err error) {
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+	if run.UpstreamCalls > 0 {
+		run.AvgLatencyMs = run.UpstreamLatencyMs / int64(run.UpstreamCalls)
+	}
+
+	switch {
+	case eyesont.IsCanceled(err):
+		run.Status = models.SyncRunCancelled
+	case err != nil || run.Errors > 0:
+		run.Status = models.SyncRunFailed
+		if run.Errors == 0 {
+			run.AddError(err) // Ошибка вне страниц (например, чтение состояния из БД)
+		}
+	default:
+		run.Status = models.SyncRunSuccess
+	}
+	run.Complete = run.Mode == models.SyncModeFull && run.Status == models.SyncRunSuccess && !s.IsPaused()
+
+	if run.ID == 0 {
+		return
+	}
+	if saveErr := s.DB.Save(&run.SyncRun).Error; saveErr != nil {
+		log.Printf("[Syncer] Failed to save sync run #%d: %v", run.ID, saveErr)
+	}
+}
+
+// RecordFailedRun записывает цикл, который не начался: аккаунт не создался или не вошёл
+func RecordFailedRun(db *gorm.DB, trigger string, accountID uint, upstream string, err error) {
+	now := time.Now()
+	run := &syncRun{SyncRun: models.SyncRun{
+		Trigger:    trigger,
+		Mode:       models.SyncModeFull,
+		Reason:     "account unavailable",
+		Status:     models.SyncRunFailed,
+		AccountID:  accountID,
+		Accounts:   1,
+		Upstream:   upstream,
+		StartedAt:  now,
+		FinishedAt: &now,
+	}}
+	run.AddError(err)
+	if createErr := db.Create(&run.SyncRun).Error; createErr != nil {
+		log.Printf("[Syncer] Failed to record sync run: %v", createErr)
+	}
+}
+
+// listPage - getProvisioningData с учётом задержки upstream в метриках цикла
+func (s *Syncer) listPage(ctx context.Context, t syncTarget, start, limit int, run *syncRun) (*models.GetProvisioningDataResponse, error) {
+	begin := time.Now()
+	resp, err := t.Provider.ListSubscribers(ctx, start, limit, t.Search)
+	if eyesont.IsCanceled(err) {
+		return resp, err
+	}
+
+	latency := time.Since(begin).Milliseconds()
+	run.upstreamMu.Lock()
+	defer run.upstreamMu.Unlock()
+	run.UpstreamCalls++
+	run.UpstreamLatencyMs += latency
+	if latency > run.MaxLatencyMs {
+		run.MaxLatencyMs = latency
+	}
+	return resp, err
+}
+
+// closeStaleRuns помечает циклы, оставшиеся RUNNING после остановки сервера
+func (s *Syncer) closeStaleRuns() {
+	s.DB.Model(&models.SyncRun{}).Where("status = ?", models.SyncRunRunning).
+		Update("status", models.SyncRunInterrupted)
+}
+
+// LastRun - последний плановый или ручной цикл синхронизации (nil, если циклов ещё не было).
+// Синхронизации после задач очереди (trigger = task) частые и мелкие - их не показываем.
+func LastRun(db *gorm.DB) *models.SyncRun {
+	var run models.SyncRun
+	if err := db.Where("sync_trigger IN ?", []string{models.SyncTriggerScheduled, models.SyncTriggerManual}).
+		Order("started_at DESC").First(&run).Error; err != nil {
+		return nil
+	}
+	return &run
+}
+
+// LastComplete - последний полный обход без ошибок (nil, если такого не было)
+func LastComplete(db *gorm.DB) *models.SyncRun {
+	var run models.SyncRun
+	if err := db.Where("complete = ?", true).Order("started_at DESC").First(&run).Error; err != nil {
+		return nil
+	}
+	return &run
+}
+
+// targetUpstreams - BaseURL аккаунтов цикла без повторов
+func targetUpstreams(targets []syncTarget) string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, t := range targets {
+		name := t.Provider.Name()
+		if client, ok := t.Provider.(*eyesont.Client); ok {
+			name = client.BaseURL
+		}
+		if !seen[name] {
+			seen[name] = true
+			urls = append(urls, name)
+		}
+	}
+	return strings.Join(urls, ", ")
+}
@@ -0,0 +1,389 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"eyeson-go-server/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ═══════════════════════════════════════════════════════════
+// РАСПИСАНИЕ ПЛАНОВОЙ СИНХРОНИЗАЦИИ
+// ═══════════════════════════════════════════════════════════
+//
+// Расписание хранится в SystemSetting (scheduleKey): cron-выражение, часовой пояс, тихие часы
+// (окна обслуживания провайдера) и пауза. Без настройки - "@every <EYESON_SYNC_INTERVAL_MIN>m".
+// В тихие часы плановые циклы не запускаются; цикл, который до них не закончился, прерывается.
+
+const scheduleKey = "sync.schedule"
+
+// QuietWindow - окно, в которое плановая синхронизация не запускается
+type QuietWindow struct {
+	Start string `json:"start"`          // "HH:MM"
+	End   string `json:"end"`            // "HH:MM"; меньше Start - окно через полночь
+	Days  []int  `json:"days,omitempty"` // Дни начала окна, 0-6 (0 - воскресенье); пусто - каждый день
+}
+
+// Schedule - расписание плановой синхронизации
+type Schedule struct {
+	Cron       string        `json:"cron"`
+	Timezone   string        `json:"timezone,omitempty"` // IANA, например "Asia/Jerusalem"; пусто - время сервера
+	QuietHours []QuietWindow `json:"quiet_hours,omitempty"`
+	Paused     bool          `json:"paused"`
+}
+
+// ScheduleStatus - расписание и ближайший плановый запуск (для GET /api/v1/sync/status)
+type ScheduleStatus struct {
+	Schedule
+	Source       string     `json:"source"` // setting | default
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+	Running      bool       `json:"running"`
+	InQuietHours bool       `json:"in_quiet_hours"`
+	QuietUntil   *time.Time `json:"quiet_until,omitempty"`
+}
+
+// quietWindow - разобранное окно: минуты от начала суток и длительность
+type quietWindow struct {
+	start    int
+	duration time.Duration
+	days     map[time.Weekday]bool
+}
+
+// compiledSchedule - расписание, готовое к вычислению запусков
+type compiledSchedule struct {
+	Schedule
+	source string
+	cron   *cronSchedule
+	loc    *time.Location
+	quiet  []quietWindow
+}
+
+// compile проверяет расписание; ошибка - текст для ответа API
+func (sch Schedule) compile() (*compiledSchedule, error) {
+	sch.Cron = strings.TrimSpace(sch.Cron)
+	if sch.Cron == "" {
+		return nil, errors.New("cron expression is required")
+	}
+	cron, err := parseCron(sch.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", sch.Cron, err)
+	}
+
+	loc := time.Local
+	if tz := strings.TrimSpace(sch.Timezone); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+	}
+
+	compiled := &compiledSchedule{Schedule: sch, cron: cron, loc: loc}
+	for i, w := range sch.QuietHours {
+		start, errStart := parseClock(w.Start)
+		end, errEnd := parseClock(w.End)
+		if errStart != nil || errEnd != nil {
+			return nil, fmt.Errorf("quiet_hours[%d]: start and end must be HH:MM", i)
+		}
+		if start == end {
+			return nil, fmt.Errorf("quiet_hours[%d]: start and end must differ", i)
+		}
+		minutes := (end - start + 24*60) % (24 * 60)
+
+		window := quietWindow{start: start, duration: time.Duration(minutes) * time.Minute}
+		if len(w.Days) > 0 {
+			window.days = make(map[time.Weekday]bool, len(w.Days))
+			for _, d := range w.Days {
+				if d < 0 || d > 6 {
+					return nil, fmt.Errorf("quiet_hours[%d]: days must be 0-6 (0 = Sunday)", i)
+				}
+				window.days[time.Weekday(d)] = true
+			}
+		}
+		compiled.quiet = append(compiled.quiet, window)
+	}
+	return compiled, nil
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// quietUntil - конец тихого окна, в которое попадает t (нулевое время - t вне окон)
+func (c *compiledSchedule) quietUntil(t time.Time) time.Time {
+	t = t.In(c.loc)
+	var until time.Time
+	for _, w := range c.quiet {
+		// Окно могло начаться вчера и перейти через полночь
+		for offset := -1; offset <= 0; offset++ {
+			day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, c.loc)
+			if w.days != nil && !w.days[day.Weekday()] {
+				continue
+			}
+			start := day.Add(time.Duration(w.start) * time.Minute)
+			end := start.Add(w.duration)
+			if !t.Before(start) && t.Before(end) && end.After(until) {
+				until = end
+			}
+		}
+	}
+	return until
+}
+
+// nextQuietStart - ближайшее начало тихого окна после t (нулевое время - окон нет)
+func (c *compiledSchedule) nextQuietStart(t time.Time) time.Time {
+	t = t.In(c.loc)
+	var next time.Time
+	for _, w := range c.quiet {
+		for offset := 0; offset <= 7; offset++ {
+			day := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, c.loc)
+			if w.days != nil && !w.days[day.Weekday()] {
+				continue
+			}
+			start := day.Add(time.Duration(w.start) * time.Minute)
+			if start.After(t) {
+				if next.IsZero() || start.Before(next) {
+					next = start
+				}
+				break
+			}
+		}
+	}
+	return next
+}
+
+// next - ближайший запуск после t вне тихих часов
+func (c *compiledSchedule) next(t time.Time) time.Time {
+	candidate := c.cron.Next(t.In(c.loc))
+	for i := 0; i < 1000 && !candidate.IsZero(); i++ {
+		until := c.quietUntil(candidate)
+		if until.IsZero() {
+			return candidate
+		}
+		if c.cron.every > 0 {
+			candidate = until // @every - сразу после окна
+			continue
+		}
+		candidate = c.cron.Next(until.Add(-time.Nanosecond))
+	}
+	return time.Time{}
+}
+
+// defaultSchedule - расписание без настройки: интервал EYESON_SYNC_INTERVAL_MIN
+func (s *Syncer) defaultSchedule() *compiledSchedule {
+	compiled, _ := Schedule{Cron: fmt.Sprintf("@every %v", s.interval())}.compile()
+	compiled.source = "default"
+	return compiled
+}
+
+// loadSchedule читает расписание из SystemSetting; повреждённая настройка - расписание по умолчанию
+func (s *Syncer) loadSchedule() {
+	compiled := s.defaultSchedule()
+
+	var setting models.SystemSetting
+	err := s.DB.Where("key = ?", scheduleKey).First(&setting).Error
+	switch {
+	case err == nil:
+		var sch Schedule
+		if err := json.Unmarshal([]byte(setting.Value), &sch); err != nil {
+			log.Printf("[Syncer] WARNING: stored schedule is corrupted, using default: %v", err)
+			break
+		}
+		stored, err := sch.compile()
+		if err != nil {
+			log.Printf("[Syncer] WARNING: stored schedule is invalid, using default: %v", err)
+			// Паузу сохраняем и при неверном расписании
+			compiled.Paused = sch.Paused
+			break
+		}
+		stored.source = "setting"
+		compiled = stored
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		log.Printf("[Syncer] WARNING: could not read schedule, using default: %v", err)
+	}
+
+	s.schedMu.Lock()
+	s.schedule = compiled
+	s.schedMu.Unlock()
+	s.setPaused(compiled.Paused)
+	log.Printf("[Syncer] Schedule: %q (%s), quiet windows: %d, paused: %t", compiled.Cron, compiled.source, len(compiled.quiet), compiled.Paused)
+}
+
+func (s *Syncer) saveSchedule(sch Schedule) error {
+	data, err := json.Marshal(sch)
+	if err != nil {
+		return err
+	}
+	return s.DB.Save(&models.SystemSetting{Key: scheduleKey, Value: string(data)}).Error
+}
+
+func (s *Syncer) setPaused(paused bool) {
+	var v int32
+	if paused {
+		v = 1
+	}
+	atomic.StoreInt32(&s.paused, v)
+}
+
+// currentSchedule - действующее расписание (по умолчанию, если Start ещё не загрузил его)
+func (s *Syncer) currentSchedule() *compiledSchedule {
+	s.schedMu.Lock()
+	defer s.schedMu.Unlock()
+	if s.schedule == nil {
+		s.schedule = s.defaultSchedule()
+	}
+	return s.schedule
+}
+
+// wakeUp будит цикл Start, чтобы он пересчитал следующий запуск
+func (s *Syncer) wakeUp() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ─── Управление (admin API) ───
+
+// Pause останавливает плановую синхронизацию; текущий цикл прерывается на следующей странице
+func (s *Syncer) Pause() error {
+	return s.updatePaused(true)
+}
+
+// Resume возобновляет плановую синхронизацию по расписанию
+func (s *Syncer) Resume() error {
+	return s.updatePaused(false)
+}
+
+func (s *Syncer) updatePaused(paused bool) error {
+	current := s.currentSchedule()
+	sch := current.Schedule
+	sch.Paused = paused
+	if err := s.saveSchedule(sch); err != nil {
+		return fmt.Errorf("failed to save schedule: %w", err)
+	}
+
+	s.schedMu.Lock()
+	updated := *current
+	updated.Paused = paused
+	updated.source = "setting"
+	s.schedule = &updated
+	s.schedMu.Unlock()
+
+	s.setPaused(paused)
+	s.planNext(time.Now())
+	s.wakeUp()
+	log.Printf("[Syncer] Scheduled sync paused=%t", paused)
+	return nil
+}
+
+// Reschedule проверяет и сохраняет новое расписание; пауза не меняется
+func (s *Syncer) Reschedule(sch Schedule) (*ScheduleStatus, error) {
+	sch.Cron = strings.TrimSpace(sch.Cron)
+	sch.Timezone = strings.TrimSpace(sch.Timezone)
+	sch.Paused = s.IsPaused()
+	compiled, err := sch.compile()
+	if err != nil {
+		return nil, err
+	}
+	if compiled.next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("schedule %q never runs outside quiet hours", sch.Cron)
+	}
+	if err := s.saveSchedule(sch); err != nil {
+		return nil, fmt.Errorf("failed to save schedule: %w", err)
+	}
+	compiled.source = "setting"
+
+	s.schedMu.Lock()
+	s.schedule = compiled
+	s.schedMu.Unlock()
+
+	s.planNext(time.Now())
+	s.wakeUp()
+	log.Printf("[Syncer] Rescheduled: %q, quiet windows: %d", sch.Cron, len(compiled.quiet))
+	status := s.ScheduleStatus()
+	return &status, nil
+}
+
+// ScheduleStatus - расписание, ближайший запуск и состояние тихих часов
+func (s *Syncer) ScheduleStatus() ScheduleStatus {
+	current := s.currentSchedule()
+	now := time.Now()
+
+	s.schedMu.Lock()
+	status := ScheduleStatus{
+		Schedule: current.Schedule,
+		Source:   current.source,
+		Running:  s.running,
+	}
+	if !s.nextRun.IsZero() && !s.IsPaused() {
+		next := s.nextRun
+		status.NextRunAt = &next
+	}
+	s.schedMu.Unlock()
+
+	status.Paused = s.IsPaused()
+	if until := current.quietUntil(now); !until.IsZero() {
+		status.InQuietHours = true
+		status.QuietUntil = &until
+	}
+	return status
+}
+
+// ─── Планировщик ───
+
+// planNext вычисляет и запоминает следующий плановый запуск (нулевое время - на паузе)
+func (s *Syncer) planNext(now time.Time) time.Time {
+	var next time.Time
+	if !s.IsPaused() {
+		next = s.currentSchedule().next(now)
+	}
+	s.schedMu.Lock()
+	s.nextRun = next
+	s.schedMu.Unlock()
+	return next
+}
+
+// runScheduled выполняет плановый цикл; начало тихих часов прерывает его
+func (s *Syncer) runScheduled(ctx context.Context) {
+	if until := s.currentSchedule().quietUntil(time.Now()); !until.IsZero() {
+		log.Printf("[Syncer] Skipping scheduled sync - quiet hours until %s", until.Format(time.RFC3339))
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if quietStart := s.currentSchedule().nextQuietStart(time.Now()); !quietStart.IsZero() {
+		stop := time.AfterFunc(time.Until(quietStart), func() {
+			log.Println("[Syncer] Quiet hours started - stopping scheduled sync")
+			cancel()
+		})
+		defer stop.Stop()
+	}
+
+	s.schedMu.Lock()
+	s.running = true
+	s.schedMu.Unlock()
+	defer func() {
+		s.schedMu.Lock()
+		s.running = false
+		s.schedMu.Unlock()
+	}()
+
+	s.syncGated(runCtx)
+}
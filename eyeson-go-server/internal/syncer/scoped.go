@@ -0,0 +1,260 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"eyeson-go-server/internal/eyesont"
+	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+)
+
+// ═══════════════════════════════════════════════════════════
+// ЧАСТИЧНАЯ СИНХРОНИЗАЦИЯ (SCOPED)
+// ═══════════════════════════════════════════════════════════
+//
+// Обновляет часть абонентов: список MSISDN, один CUSTOMER_NUMBER или произвольный фильтр
+// getProvisioningData. Страницы идут через тот же mapApiToModel и processBatch, что и полный
+// обход (diff-теги, история, защита полей задач), поэтому частичное обновление так же полно.
+// Хэши страниц и поиск удалённых SIM не трогаются - они имеют смысл только для полного обхода.
+// Синхронизация после задач worker'а (syncSimsFromAPI) - это scoped-цикл по MSISDN.
+
+// MaxScopeMSISDNs - сколько MSISDN можно передать за раз (по одному запросу на номер)
+const MaxScopeMSISDNs = 1000
+
+// scopeBatch - сколько найденных SIM сверяется одной транзакцией processBatch
+const scopeBatch = 200
+
+// ErrInvalidScope - выборка не задана или задана несколькими способами
+var ErrInvalidScope = errors.New("invalid sync scope")
+
+// Scope - выборка абонентов частичной синхронизации; задаётся ровно один способ
+type Scope struct {
+	MSISDNs        []string             `json:"msisdns,omitempty"`
+	CustomerNumber string               `json:"customer_number,omitempty"`
+	Search         []models.SearchParam `json:"search,omitempty"`
+}
+
+// Validate проверяет выборку и нормализует MSISDN
+func (sc *Scope) Validate() error {
+	ways := 0
+	if len(sc.MSISDNs) > 0 {
+		ways++
+	}
+	if sc.CustomerNumber = strings.TrimSpace(sc.CustomerNumber); sc.CustomerNumber != "" {
+		ways++
+	}
+	if len(sc.Search) > 0 {
+		ways++
+	}
+	if ways != 1 {
+		return fmt.Errorf("%w: set exactly one of msisdns, customer_number or search", ErrInvalidScope)
+	}
+
+	if len(sc.MSISDNs) > MaxScopeMSISDNs {
+		return fmt.Errorf("%w: at most %d msisdns per request", ErrInvalidScope, MaxScopeMSISDNs)
+	}
+	seen := make(map[string]bool, len(sc.MSISDNs))
+	msisdns := make([]string, 0, len(sc.MSISDNs)) // Копия: срез вызывающего не меняем
+	for _, m := range sc.MSISDNs {
+		m = eyesont.NormalizeMSISDN(strings.TrimSpace(m))
+		if m != "" && !seen[m] {
+			seen[m] = true
+			msisdns = append(msisdns, m)
+		}
+	}
+	sc.MSISDNs = msisdns
+	if ways == 1 && len(msisdns) == 0 && sc.CustomerNumber == "" && len(sc.Search) == 0 {
+		return fmt.Errorf("%w: msisdns are empty", ErrInvalidScope)
+	}
+
+	search := make([]models.SearchParam, 0, len(sc.Search))
+	for i, p := range sc.Search {
+		p = models.SearchParam{FieldName: strings.TrimSpace(p.FieldName), FieldValue: strings.TrimSpace(p.FieldValue)}
+		if p.FieldName == "" || p.FieldValue == "" {
+			return fmt.Errorf("%w: search parameter %d needs fieldName and fieldValue", ErrInvalidScope, i+1)
+		}
+		search = append(search, p)
+	}
+	if len(search) > 0 {
+		sc.Search = search
+	}
+	return nil
+}
+
+// String - описание выборки для SyncRun.Reason
+func (sc Scope) String() string {
+	var reason string
+	switch {
+	case len(sc.MSISDNs) > 0:
+		reason = fmt.Sprintf("msisdns: %d", len(sc.MSISDNs))
+	case sc.CustomerNumber != "":
+		reason = "customer_number: " + sc.CustomerNumber
+	default:
+		parts := make([]string, 0, len(sc.Search))
+		for _, p := range sc.Search {
+			parts = append(parts, p.FieldName+"="+p.FieldValue)
+		}
+		reason = "search: " + strings.Join(parts, ", ")
+	}
+	if len(reason) > 128 {
+		reason = reason[:125] + "..."
+	}
+	return reason
+}
+
+// search - фильтр getProvisioningData для обхода по страницам
+func (sc Scope) search() []models.SearchParam {
+	if sc.CustomerNumber != "" {
+		return []models.SearchParam{{FieldName: "CUSTOMER_NUMBER", FieldValue: sc.CustomerNumber}}
+	}
+	return sc.Search
+}
+
+// ScopeResult - итог частичной синхронизации
+type ScopeResult struct {
+	Run      *models.SyncRun `json:"run"`
+	NotFound []string        `json:"not_found,omitempty"` // MSISDN, которых нет ни в одном аккаунте
+}
+
+// SyncScoped синхронизирует выборку абонентов. Цикл записывается в SyncRun с mode = scoped.
+func (s *Syncer) SyncScoped(ctx context.Context, scope Scope) (*ScopeResult, error) {
+	if err := scope.Validate(); err != nil {
+		return nil, err
+	}
+
+	targets := s.targets()
+	if len(targets) == 0 {
+		return nil, &eyesont.APIError{Kind: eyesont.ErrKindCircuitOpen, Op: "SyncScoped", Message: "no upstream accounts available", Retryable: true}
+	}
+
+	ctx = eyesont.WithBulk(ctx)
+	run := s.startRun(models.SyncModeScoped, scope.String(), targets)
+	result := &ScopeResult{Run: &run.SyncRun}
+
+	found := make(map[string]bool)
+	var lastErr error
+	for _, t := range targets {
+		if ctx.Err() != nil {
+			lastErr = ctx.Err()
+			break
+		}
+		var err error
+		if len(scope.MSISDNs) > 0 {
+			err = s.syncMSISDNs(ctx, t, scope.MSISDNs, run, found)
+		} else {
+			t.Search = scope.search()
+			err = s.syncSearch(ctx, t, scope, run)
+		}
+		if err != nil {
+			lastErr = err
+		}
+	}
+
+	for _, m := range scope.MSISDNs {
+		if !found[m] {
+			result.NotFound = append(result.NotFound, m)
+		}
+	}
+
+	s.finishRun(run, lastErr)
+	log.Printf("[Syncer] Scoped sync (%s): processed %d, created %d, updated %d, not found %d",
+		run.Reason, run.Processed, run.Created, run.Updated, len(result.NotFound))
+	return result, lastErr
+}
+
+// syncMSISDNs запрашивает каждый MSISDN отдельно и сверяет найденные пачками
+func (s *Syncer) syncMSISDNs(ctx context.Context, t syncTarget, msisdns []string, run *syncRun, found map[string]bool) error {
+	var batch []models.SimData
+	var lastErr error
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.processBatch(batch, t, run); err != nil {
+			log.Printf("[Syncer] Error processing batch: %v", err)
+			run.AddError(err)
+			lastErr = err
+		}
+		batch = batch[:0]
+	}
+
+	for _, m := range msisdns {
+		if found[m] {
+			continue // Уже нашёлся в другом аккаунте
+		}
+		if err := ctx.Err(); err != nil {
+			flush()
+			return err
+		}
+
+		st := t
+		st.Search = []models.SearchParam{{FieldName: "MSISDN", FieldValue: m}}
+		resp, err := s.fetchPageRetry(ctx, st, 0, 10, run)
+		if err != nil {
+			if eyesont.IsCanceled(err) {
+				flush()
+				return err
+			}
+			log.Printf("[Syncer] Account #%d: SIM %s: %v", t.AccountID, m, err)
+			run.AddError(err)
+			lastErr = err
+			if !provider.IsAvailable(t.Provider) {
+				break
+			}
+			continue
+		}
+		run.PagesFetched++
+
+		// Поиск провайдера может быть по подстроке - берём только точное совпадение
+		for _, d := range resp.Data {
+			if d.MSISDN == m {
+				found[m] = true
+				batch = append(batch, d)
+				break
+			}
+		}
+		if len(batch) >= scopeBatch {
+			flush()
+		}
+	}
+	flush()
+	return lastErr
+}
+
+// syncSearch обходит страницы с фильтром; CUSTOMER_NUMBER дополнительно сверяется на нашей стороне
+func (s *Syncer) syncSearch(ctx context.Context, t syncTarget, scope Scope, run *syncRun) error {
+	var batchErr error
+	_, err := s.walkPages(ctx, t, run, func(page walkedPage) {
+		run.PagesFetched++
+		data := page.Data
+		if scope.CustomerNumber != "" {
+			data = make([]models.SimData, 0, len(page.Data))
+			for _, d := range page.Data {
+				if d.CustomerNumber == scope.CustomerNumber {
+					data = append(data, d)
+				}
+			}
+		}
+		if len(data) == 0 {
+			return
+		}
+		if err := s.processBatch(data, t, run); err != nil {
+			log.Printf("[Syncer] Error processing batch: %v", err)
+			run.AddError(err)
+			batchErr = err
+		}
+	})
+	if err == nil {
+		err = batchErr
+	}
+	return err
+}
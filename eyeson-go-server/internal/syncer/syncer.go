@@ -6,50 +6,142 @@
 package syncer
 
 import (
+	"context"
 	"log"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"eyeson-go-server/internal/eyesont"
 	"eyeson-go-server/internal/models"
+	"eyeson-go-server/internal/provider"
+	"eyeson-go-server/internal/services"
 
 	"gorm.io/gorm"
 )
 
 type Syncer struct {
-	DB     *gorm.DB
-	Client *eyesont.Client
+	DB *gorm.DB
+
+	// Provider/AccountID задают один аккаунт явно (ручная синхронизация).
+	// Provider = nil - синхронизируются все включённые аккаунты (services.Accounts).
+	Provider  provider.Provider
+	AccountID uint
+
+	// Trigger - кто запустил цикл (models.SyncTrigger*); пусто - manual при явном Provider, иначе scheduled
+	Trigger string
+
+	// Interval - период плановой синхронизации, пока расписание не задано (0 - DefaultInterval).
+	// FullInterval - как часто плановый цикл делает полный обход; между ними циклы
+	// инкрементальные (см. incremental.go). 0 - каждый цикл полный.
+	Interval     time.Duration
+	FullInterval time.Duration
+
 	paused int32
+
+	// Расписание плановой синхронизации (schedule.go)
+	schedMu  sync.Mutex
+	schedule *compiledSchedule
+	nextRun  time.Time
+	running  bool
+	wake     chan struct{}
 }
 
 func New(db *gorm.DB) *Syncer {
-	// We assume eyesont.Instance is initialized in main
 	return &Syncer{
-		DB:     db,
-		Client: eyesont.Instance,
+		DB:   db,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// syncTarget - аккаунт и его клиент в рамках одного цикла синхронизации
+type syncTarget struct {
+	AccountID uint
+	Provider  provider.Provider
+
+	// Search - фильтр getProvisioningData частичной синхронизации (scoped.go); nil - все абоненты
+	Search []models.SearchParam
+}
+
+// targets возвращает аккаунты для синхронизации; недоступные аккаунты пропускаются
+func (s *Syncer) targets() []syncTarget {
+	if s.Provider != nil {
+		if !provider.IsAvailable(s.Provider) {
+			return nil
+		}
+		return []syncTarget{{AccountID: s.AccountID, Provider: s.Provider}}
+	}
+
+	accounts, err := services.Accounts.EnabledAccounts()
+	if err != nil {
+		log.Printf("[Syncer] Failed to load upstream accounts: %v", err)
+		return nil
+	}
+
+	targets := make([]syncTarget, 0, len(accounts))
+	for _, acc := range accounts {
+		p, err := services.Accounts.Provider(acc.ID)
+		if err != nil {
+			log.Printf("[Syncer] Account #%d %q skipped: %v", acc.ID, acc.Name, err)
+			continue
+		}
+		if !provider.IsAvailable(p) {
+			log.Printf("[Syncer] Account #%d %q skipped: upstream unavailable (circuit breaker open)", acc.ID, acc.Name)
+			continue
+		}
+		targets = append(targets, syncTarget{AccountID: acc.ID, Provider: p})
 	}
+	return targets
 }
 
 func (s *Syncer) IsPaused() bool {
 	return atomic.LoadInt32(&s.paused) == 1
 }
 
-func (s *Syncer) Start() {
+// Start запускает синхронизацию по расписанию (schedule.go); отмена ctx прерывает текущий
+// цикл и останавливает сервис. Pause/Resume/Reschedule будят цикл для пересчёта запуска.
+func (s *Syncer) Start(ctx context.Context) {
 	log.Println("[Syncer] Starting background synchronization service...")
+	s.closeStaleRuns()
+	s.loadSchedule()
 	go func() {
 		// Check if we should sync initially
 		if !s.shouldSync() {
-			log.Println("[Syncer] Skipping initial sync - API unavailable or simulator DOWN")
+			log.Println("[Syncer] Skipping initial sync - API unavailable, simulator DOWN or paused")
 		} else {
 			// Initial sync
-			s.SyncFull()
+			s.runScheduled(ctx)
 		}
 
-		ticker := time.NewTicker(5 * time.Minute)
-		for range ticker.C {
+		for {
+			var fire <-chan time.Time
+			var timer *time.Timer
+			if next := s.planNext(time.Now()); !next.IsZero() {
+				timer = time.NewTimer(time.Until(next))
+				fire = timer.C
+			}
+
+			woken := false
+			select {
+			case <-ctx.Done():
+			case <-s.wake:
+				woken = true
+			case <-fire:
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			if ctx.Err() != nil {
+				log.Println("[Syncer] Stopped")
+				return
+			}
+			if woken {
+				continue
+			}
+
 			if s.shouldSync() {
-				s.SyncFull()
+				s.runScheduled(ctx)
 			} else {
 				log.Println("[Syncer] Skipping scheduled sync - API unavailable")
 			}
@@ -57,109 +149,160 @@ func (s *Syncer) Start() {
 	}()
 }
 
+// syncGated - плановый цикл синхронизации; переключение upstream прерывает его,
+// следующий цикл пойдёт уже в новый upstream
+func (s *Syncer) syncGated(ctx context.Context) {
+	syncCtx, release := services.UpstreamGate.Enter(ctx, false)
+	defer release()
+	mode, reason := s.nextMode()
+	s.Sync(syncCtx, mode, reason)
+}
+
+// RefreshCatalog загружает getProvisioningParameterList аккаунта и обновляет его кэш каталога.
+// Ошибки не прерывают синхронизацию - остаётся предыдущий закэшированный каталог.
+func (s *Syncer) RefreshCatalog(ctx context.Context, t syncTarget) error {
+	params, err := t.Provider.GetParameters(ctx)
+	if err != nil {
+		log.Printf("[Syncer] Account #%d: catalog refresh failed, keeping cached copy: %v", t.AccountID, err)
+		return err
+	}
+	return services.Catalog.Store(t.AccountID, params)
+}
+
 // shouldSync checks if we should attempt to sync with API
 func (s *Syncer) shouldSync() bool {
 	if s.IsPaused() {
 		return false
 	}
 
-	if s.Client == nil {
-		return false
-	}
+	// Upstream доступен, если хотя бы у одного аккаунта breaker не открыт
+	return len(s.targets()) > 0
+}
 
-	// Check API connectivity with a simple ping/check
-	// For now, we'll try a small request - if it fails, skip sync
-	return true
+// SyncFull загружает все SIM каждого аккаунта постранично. При отмене ctx возвращает
+// количество уже обработанных записей и ctx.Err(); обработанные страницы остаются в БД.
+// Ошибка одного аккаунта не останавливает синхронизацию остальных.
+func (s *Syncer) SyncFull(ctx context.Context) (int, error) {
+	return s.Sync(ctx, models.SyncModeFull, "requested")
 }
 
-func (s *Syncer) SyncFull() (int, error) {
+// Sync выполняет цикл синхронизации в заданном режиме и записывает его в SyncRun.
+// Инкрементальный режим обходит только изменявшиеся страницы; аккаунт, для которого
+// это невозможно (нет состояния страниц, сдвинулись границы), синхронизируется полностью.
+func (s *Syncer) Sync(ctx context.Context, mode, reason string) (int, error) {
 	if s.IsPaused() {
 		return 0, nil
 	}
 
-	if s.Client == nil {
-		log.Println("[Syncer] Error: API Client not initialized")
+	targets := s.targets()
+	if len(targets) == 0 && s.Provider != nil {
+		return 0, &eyesont.APIError{Kind: eyesont.ErrKindCircuitOpen, Op: "SyncFull", Message: "upstream unavailable (circuit breaker open)", Retryable: true}
+	}
+	if len(targets) == 0 {
+		log.Println("[Syncer] Error: no upstream accounts available")
 		return 0, nil
 	}
 
-	log.Println("[Syncer] Starting full sync cycle...")
+	log.Printf("[Syncer] Starting %s sync cycle (%d account(s), reason: %s)...", mode, len(targets), reason)
+
+	// Страницы синхронизации уступают лимитер интерактивным запросам пользователей
+	ctx = eyesont.WithBulk(ctx)
 	startTime := time.Now()
+	run := s.startRun(mode, reason, targets)
 
-	start := 0
-	limit := 200 // Fetch 200 at a time, as API might have its own cap
 	totalProcessed := 0
 	var lastErr error
-	totalAvailable := 0 // Total records reported by API
-
-	firstRequest := true
-
-	for {
-		if s.IsPaused() {
+	for _, t := range targets {
+		if ctx.Err() != nil || s.IsPaused() {
 			break
 		}
+		// Каталог параметров обновляем каждый цикл - от него зависит валидация изменений
+		s.RefreshCatalog(ctx, t)
 
-		// Low Priority: Check for high priority user tasks (SyncTask)
-		var pendingCount int64
-		s.DB.Model(&models.SyncTask{}).Where("status IN ?", []string{"PENDING", "PROCESSING"}).Count(&pendingCount)
-		if pendingCount > 0 {
-			// Yield to User Tasks
-			// log.Printf("[Syncer] Pausing full sync for %d pending user tasks...", pendingCount)
-			time.Sleep(2 * time.Second)
-			continue
+		var processed int
+		var err error
+		if mode == models.SyncModeIncremental {
+			processed, err = s.syncIncremental(ctx, t, run)
+		} else {
+			processed, err = s.syncAccount(ctx, t, run)
 		}
-
-		// Fetch from API
-		resp, err := s.Client.GetSims(start, limit, nil, "", "")
+		totalProcessed += processed
 		if err != nil {
-			log.Printf("[Syncer] Error fetching SIMs: %v", err)
-			log.Println("[Syncer] API unavailable - will retry on next cycle")
 			lastErr = err
-			break // Stop sync on error but don't crash
 		}
+	}
 
-		// On the first request, get the total count
-		if firstRequest {
-			totalAvailable = resp.Count
-			firstRequest = false
-		}
+	duration := time.Since(startTime)
+	log.Printf("[Syncer] %s sync completed in %v. Processed %d records (pages: %d fetched, %d unchanged, %d skipped).",
+		mode, duration, totalProcessed, run.PagesFetched, run.PagesUnchanged, run.PagesSkipped)
+	s.finishRun(run, lastErr)
+	return totalProcessed, lastErr
+}
 
-		if len(resp.Data) == 0 {
-			break // No more data
-		}
+// syncAccount загружает все SIM одного аккаунта (полный обход)
+func (s *Syncer) syncAccount(ctx context.Context, t syncTarget, run *syncRun) (int, error) {
+	seen := make(map[string]bool) // MSISDN, найденные обходом - для поиска удалённых у провайдера
+	totalProcessed := 0
+	var batchErr error
 
-		// Process batch
-		if err := s.processBatch(resp.Data); err != nil {
+	// Единственный writer: страницы приходят сюда строго по порядку
+	end, err := s.walkPages(ctx, t, run, func(page walkedPage) {
+		run.PagesFetched++
+		for _, d := range page.Data {
+			seen[d.MSISDN] = true
+		}
+		if err := s.processBatch(page.Data, t, run); err != nil {
 			log.Printf("[Syncer] Error processing batch: %v", err)
-			lastErr = err
+			run.AddError(err)
+			batchErr = err
+		} else if s.tracksPages() {
+			if !s.recordPage(t.AccountID, page.Start, page.Size, page.Total, page.Data) {
+				run.PagesUnchanged++
+			}
 		}
+		totalProcessed += len(page.Data)
+	})
+	if err == nil {
+		err = batchErr
+	}
 
-		totalProcessed += len(resp.Data)
-		start += len(resp.Data) // Important: increment by actual number received
-
-		// If we've processed all available records, stop.
-		if totalAvailable > 0 && totalProcessed >= totalAvailable {
-			break
+	// Полный обход завершён: страниц за его концом больше нет, SIM вне seen - удалены у провайдера
+	if err == nil && !s.IsPaused() {
+		if s.tracksPages() {
+			s.DB.Where("account_id = ? AND page_start >= ?", t.AccountID, end).Delete(&models.SyncPage{})
 		}
-
-		// Small pause to be nice to the API
-		time.Sleep(100 * time.Millisecond)
+		s.detectMissing(t, seen, run)
 	}
 
-	duration := time.Since(startTime)
-	log.Printf("[Syncer] Full sync completed in %v. Processed %d records.", duration, totalProcessed)
-	return totalProcessed, lastErr
+	log.Printf("[Syncer] Account #%d: processed %d records", t.AccountID, totalProcessed)
+	return totalProcessed, err
+}
+
+// batchPlan - результат сверки страницы с локальной БД (compareBatch), ещё не записанный
+type batchPlan struct {
+	toCreate  []models.SimCard
+	toUpdate  []models.SimCard
+	histories []models.SimHistory
+	restored  int
+	conflicts int
+
+	// Для отчёта о расхождениях (drift.go)
+	diffs   map[string]models.SimDiff // MSISDN -> изменения полей
+	revived []models.SimCard          // Удалённые локально SIM, которые есть у провайдера
 }
 
-func (s *Syncer) processBatch(sims []models.SimData) error {
+// compareBatch сверяет страницу провайдера с локальной БД, ничего не записывая.
+// expected = nil - поля задач не защищаются (отчёт показывает значения провайдера как есть).
+func (s *Syncer) compareBatch(sims []models.SimData, t syncTarget, expected *services.ExpectedIndex) (*batchPlan, error) {
 	var msisdns []string
 	for _, s := range sims {
 		msisdns = append(msisdns, s.MSISDN)
 	}
 
-	// 1. Fetch Existing
+	// 1. Fetch Existing (включая удалённые: SIM, вернувшаяся к провайдеру, восстанавливается)
 	var existingSims []models.SimCard
-	if err := s.DB.Where("msisdn IN ?", msisdns).Find(&existingSims).Error; err != nil {
-		return err
+	if err := s.DB.Unscoped().Where("msisdn IN ?", msisdns).Find(&existingSims).Error; err != nil {
+		return nil, err
 	}
 
 	existingMap := make(map[string]models.SimCard)
@@ -167,83 +310,86 @@ func (s *Syncer) processBatch(sims []models.SimData) error {
 		existingMap[sim.MSISDN] = sim
 	}
 
-	var toCreate []models.SimCard
-	var toUpdate []models.SimCard
-	var histories []models.SimHistory
+	// Значения, которые задачи записали оптимистично и провайдер ещё может не отдавать
+	var pending map[string][]services.ExpectedChange
+	if expected != nil {
+		pending = expected.For(msisdns)
+	}
+
+	plan := &batchPlan{diffs: make(map[string]models.SimDiff)}
 
 	// 2. Compare API vs DB
 	for _, apiSim := range sims {
 		newSim := mapApiToModel(apiSim)
+		newSim.Provider = t.Provider.Name()
+		newSim.AccountID = t.AccountID
 		existing, found := existingMap[newSim.MSISDN]
 
 		if !found {
 			// Create New
-			toCreate = append(toCreate, newSim)
+			plan.toCreate = append(plan.toCreate, newSim)
 			// History: Created
-			histories = append(histories, models.SimHistory{
-				MSISDN:   newSim.MSISDN,
-				Action:   "CREATED",
-				Source:   "SYNC_DISCOVERY",
-				NewValue: "Detected by Sync",
-			})
+			plan.histories = append(plan.histories, models.NewSimHistory(0, newSim.MSISDN, "SYNC_DISCOVERY").
+				Event("CREATED", "", "", "Detected by Sync").Entries()...)
 		} else {
 			// Update Existing - Check Diff
 			newSim.ID = existing.ID
 			newSim.CreatedAt = existing.CreatedAt
 			changesFound := false
 
-			// Compare fields (Status, IP, IMEI, Usage)
-			if newSim.Status != existing.Status {
-				changesFound = true
-				histories = append(histories, createHistory(existing, "STATUS", existing.Status, newSim.Status))
-			}
-			if newSim.IP != existing.IP {
-				changesFound = true
-				histories = append(histories, createHistory(existing, "IP", existing.IP, newSim.IP))
-			}
-			if newSim.IMEI != existing.IMEI {
-				changesFound = true // Don't log history for IMEI changes usually, but let's do it for tracking
-				histories = append(histories, createHistory(existing, "IMEI", existing.IMEI, newSim.IMEI))
-			}
-			if newSim.ICCID != existing.ICCID {
+			// SIM снова есть у провайдера: снимаем отметку об отсутствии / восстанавливаем удалённую
+			if existing.DeletedAt.Valid {
 				changesFound = true
-				histories = append(histories, createHistory(existing, "ICCID", existing.ICCID, newSim.ICCID)) // SimSwap
-			}
-			// Usage Changes - Update but don't spam History unless jump is big?
-			// For now, let's just update usage without history to avoid clutter,
-			// History is mainly for state/identity changes.
-			if newSim.UsageMB != existing.UsageMB || newSim.AllocatedMB != existing.AllocatedMB {
-				changesFound = true
-			}
-			if newSim.InSession != existing.InSession {
-				changesFound = true
-			}
-			if !newSim.LastSession.Equal(existing.LastSession) {
+				plan.restored++
+				plan.revived = append(plan.revived, newSim)
+				plan.histories = append(plan.histories, models.NewSimHistory(existing.ID, existing.MSISDN, "SYNC_DISCOVERY").
+					Event("RESTORED", "", "Deleted", "Found upstream again").Entries()...)
+			} else if existing.MissingSince != nil {
 				changesFound = true
 			}
 
-			// Compare Labels
-			if newSim.Label1 != existing.Label1 {
-				changesFound = true
-				histories = append(histories, createHistory(existing, "LABEL_1", existing.Label1, newSim.Label1))
-			}
-			if newSim.Label2 != existing.Label2 {
-				changesFound = true
-				histories = append(histories, createHistory(existing, "LABEL_2", existing.Label2, newSim.Label2))
+			// Поля активных и недавних задач не откатываем к старому значению провайдера
+			if expected != nil {
+				newSim.SyncConflict = services.HoldBack(&newSim, existing, pending[newSim.MSISDN])
+				if newSim.SyncConflict != "" {
+					plan.conflicts++
+				}
+				if newSim.SyncConflict != existing.SyncConflict {
+					changesFound = true
+				}
 			}
-			if newSim.Label3 != existing.Label3 {
+
+			// Compare fields: политика каждого поля - тег diff в models.SimCard
+			diff := models.DiffSimCards(existing, newSim)
+			if diff.Changed() {
 				changesFound = true
-				histories = append(histories, createHistory(existing, "LABEL_3", existing.Label3, newSim.Label3))
+				plan.diffs[newSim.MSISDN] = diff
+				plan.histories = append(plan.histories, models.NewSimHistory(existing.ID, existing.MSISDN, "SYNC_PROVIDER").
+					Diff(diff).Entries()...)
 			}
 
 			if changesFound {
-				toUpdate = append(toUpdate, newSim)
+				plan.toUpdate = append(plan.toUpdate, newSim)
 			}
 		}
 	}
+	return plan, nil
+}
+
+// processBatch сверяет страницу с локальной БД и пишет изменения; счётчики - в run
+func (s *Syncer) processBatch(sims []models.SimData, t syncTarget, run *syncRun) error {
+	// Задачи разбираются один раз на цикл, а не на каждую страницу
+	if run.expected == nil {
+		run.expected = services.Queue.ExpectedIndex()
+	}
+	plan, err := s.compareBatch(sims, t, run.expected)
+	if err != nil {
+		return err
+	}
+	toCreate, toUpdate, histories := plan.toCreate, plan.toUpdate, plan.histories
 
 	// 3. Execute Updates in Transaction
-	return s.DB.Transaction(func(tx *gorm.DB) error {
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
 		if len(toCreate) > 0 {
 			if err := tx.Create(&toCreate).Error; err != nil {
 				return err
@@ -256,7 +402,8 @@ func (s *Syncer) processBatch(sims []models.SimData) error {
 			// Upsert is better.
 			// Re-using generic Upsert for all 'toUpdate'
 			// Note: We already built 'toUpdate' with ID populated.
-			if err := tx.Save(&toUpdate).Error; err != nil {
+			// Unscoped - чтобы Save обновил и восстановленные (DeletedAt/MissingSince обнуляются)
+			if err := tx.Unscoped().Save(&toUpdate).Error; err != nil {
 				return err
 			}
 		}
@@ -268,18 +415,16 @@ func (s *Syncer) processBatch(sims []models.SimData) error {
 		}
 		return nil
 	})
-}
-
-func createHistory(sim models.SimCard, field, oldVal, newVal string) models.SimHistory {
-	return models.SimHistory{
-		SimID:    sim.ID,
-		MSISDN:   sim.MSISDN,
-		Action:   "CHANGE_" + field,
-		Field:    field,
-		OldValue: oldVal,
-		NewValue: newVal,
-		Source:   "SYNC_PROVIDER",
+	if err == nil {
+		run.Processed += len(sims)
+		run.Created += len(toCreate)
+		run.Updated += len(toUpdate)
+		run.Unchanged += len(sims) - len(toCreate) - len(toUpdate)
+		run.HistoryRows += len(histories)
+		run.Restored += plan.restored
+		run.Conflicts += plan.conflicts
 	}
+	return err
 }
 
 func derefStr(s *string) string {
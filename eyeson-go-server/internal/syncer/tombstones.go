@@ -0,0 +1,158 @@
+// Copyright (c) 2026 Alexander G.
+// Author: Alexander G. (Samsonix)
+// License: MIT
+// Project: EyesOn SIM Management System
+
+package syncer
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"eyeson-go-server/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ═══════════════════════════════════════════════════════════
+// SIM, УДАЛЁННЫЕ У ПРОВАЙДЕРА
+// ═══════════════════════════════════════════════════════════
+//
+// processBatch только создаёт и обновляет SIM. После полного обхода аккаунта SIM, которых
+// не было ни на одной странице, получают MissingSince; если SIM не появилась за grace-период,
+// она удаляется (soft delete) с записью SimHistory и событием SIM_DELETED. SIM, вернувшаяся
+// к провайдеру, восстанавливается в processBatch.
+
+// DefaultDeleteGrace - сколько SIM может отсутствовать у провайдера до удаления
+const DefaultDeleteGrace = 24 * time.Hour
+
+// tombstoneBatch - SIM в одном UPDATE/DELETE (лимит параметров SQLite)
+const tombstoneBatch = 500
+
+// DeletedListener получает SIM, удалённые после grace-периода
+type DeletedListener func(sims []models.SimCard)
+
+var (
+	tombstoneMu     sync.RWMutex
+	deleteGrace     = DefaultDeleteGrace
+	deletedListener DeletedListener
+)
+
+// ConfigureTombstones задаёт grace-период; 0 - SIM только отмечаются отсутствующими и не удаляются
+func ConfigureTombstones(grace time.Duration) {
+	tombstoneMu.Lock()
+	deleteGrace = grace
+	tombstoneMu.Unlock()
+}
+
+// SetDeletedListener подписывает listener на удаление SIM (для события SIM_DELETED)
+func SetDeletedListener(listener DeletedListener) {
+	tombstoneMu.Lock()
+	deletedListener = listener
+	tombstoneMu.Unlock()
+}
+
+// detectMissing сравнивает SIM аккаунта в локальной БД с найденными полным обходом
+func (s *Syncer) detectMissing(t syncTarget, seen map[string]bool, run *syncRun) {
+	// Пустой ответ провайдера - скорее сбой, чем удаление всего парка
+	if len(seen) == 0 {
+		return
+	}
+
+	var local []models.SimCard
+	if err := s.DB.Select("id", "msisdn", "cli", "status", "account_id", "missing_since").
+		Where("account_id = ?", t.AccountID).Find(&local).Error; err != nil {
+		log.Printf("[Syncer] Account #%d: failed to load SIMs for deletion check: %v", t.AccountID, err)
+		return
+	}
+
+	tombstoneMu.RLock()
+	grace, listener := deleteGrace, deletedListener
+	tombstoneMu.RUnlock()
+
+	now := time.Now()
+	var newlyMissing []uint
+	var expired []models.SimCard
+	for _, sim := range local {
+		if seen[sim.MSISDN] {
+			continue
+		}
+		switch {
+		case sim.MissingSince == nil:
+			newlyMissing = append(newlyMissing, sim.ID)
+		case grace > 0 && now.Sub(*sim.MissingSince) >= grace:
+			expired = append(expired, sim)
+		}
+	}
+
+	for _, ids := range chunkIDs(newlyMissing) {
+		if err := s.DB.Model(&models.SimCard{}).Where("id IN ?", ids).Update("missing_since", now).Error; err != nil {
+			log.Printf("[Syncer] Account #%d: failed to mark missing SIMs: %v", t.AccountID, err)
+			return
+		}
+	}
+	run.Missing += len(newlyMissing)
+	if len(newlyMissing) > 0 && grace > 0 {
+		log.Printf("[Syncer] Account #%d: %d SIM(s) not found upstream, deleting after %v", t.AccountID, len(newlyMissing), grace)
+	} else if len(newlyMissing) > 0 {
+		log.Printf("[Syncer] Account #%d: %d SIM(s) not found upstream (deletion disabled)", t.AccountID, len(newlyMissing))
+	}
+
+	if len(expired) == 0 {
+		return
+	}
+	deleted := s.tombstone(expired)
+	run.Deleted += len(deleted)
+	log.Printf("[Syncer] Account #%d: deleted %d SIM(s) missing upstream for over %v", t.AccountID, len(deleted), grace)
+	if listener != nil && len(deleted) > 0 {
+		listener(deleted)
+	}
+}
+
+// tombstone удаляет SIM (soft delete) и пишет историю; возвращает удалённые
+func (s *Syncer) tombstone(sims []models.SimCard) []models.SimCard {
+	var deleted []models.SimCard
+	for start := 0; start < len(sims); start += tombstoneBatch {
+		end := start + tombstoneBatch
+		if end > len(sims) {
+			end = len(sims)
+		}
+		part := sims[start:end]
+
+		ids := make([]uint, len(part))
+		var histories []models.SimHistory
+		for i, sim := range part {
+			ids[i] = sim.ID
+			histories = append(histories, models.NewSimHistory(sim.ID, sim.MSISDN, "SYNC_PROVIDER").
+				Change("DELETED", "status", sim.Status, fmt.Sprintf("Missing upstream since %s", sim.MissingSince.Format(time.RFC3339))).
+				Entries()...)
+		}
+
+		err := s.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("id IN ?", ids).Delete(&models.SimCard{}).Error; err != nil {
+				return err
+			}
+			return tx.Create(&histories).Error
+		})
+		if err != nil {
+			log.Printf("[Syncer] Failed to delete missing SIMs: %v", err)
+			continue
+		}
+		deleted = append(deleted, part...)
+	}
+	return deleted
+}
+
+func chunkIDs(ids []uint) [][]uint {
+	var chunks [][]uint
+	for start := 0; start < len(ids); start += tombstoneBatch {
+		end := start + tombstoneBatch
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[start:end])
+	}
+	return chunks
+}
@@ -176,7 +176,7 @@ function App() {
   const [apiStatusLoading, setApiStatusLoading] = useState(false);
   const [isAdmin, setIsAdmin] = useState(false);
 
-  // Upstream selection (Admin only, persisted on server; applies after restart)
+  // Upstream selection (Admin only, persisted on server; switched at runtime)
   const [upstreamCfg, setUpstreamCfg] = useState<any>(null);
   const [upstreamLoading, setUpstreamLoading] = useState(false);
   const [upstreamSaving, setUpstreamSaving] = useState(false);
@@ -2620,7 +2620,7 @@ function App() {
                         </div>
 
                         <div className="mt-2">
-                          <label className="form-label text-muted small mb-1">Select provider (applies after restart)</label>
+                          <label className="form-label text-muted small mb-1">Select provider (applies immediately)</label>
                           <select
                             className="form-select form-select-sm"
                             value={upstreamSelectedDraft}
@@ -2651,8 +2651,12 @@ function App() {
                                 try {
                                   const saved = await SetUpstream(upstreamSelectedDraft);
                                   setUpstreamCfg(saved);
-                                  setUpstreamRestartRequired(true);
-                                  showToast('Upstream selection saved. Restart server to apply.', 'warning');
+                                  setUpstreamRestartRequired(saved.restart_required);
+                                  if (saved.switch?.logged_in) {
+                                    showToast(`Upstream switched to ${saved.selected}.`, 'success');
+                                  } else {
+                                    showToast(`Upstream switched to ${saved.selected}, but login failed: ${saved.switch?.login_error || 'unknown error'}`, 'warning');
+                                  }
                                 } catch (e: any) {
                                   showToast(e.message || 'Failed to save upstream selection', 'danger');
                                 } finally {
@@ -2660,7 +2664,7 @@ function App() {
                                 }
                               }}
                             >
-                              {upstreamSaving ? 'Saving...' : 'Apply (Restart Required)'}
+                              {upstreamSaving ? 'Switching...' : 'Apply'}
                             </button>
                           </div>
 
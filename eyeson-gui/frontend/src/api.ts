@@ -418,6 +418,18 @@ export interface UpstreamConfigResponse {
         simulator: { base_url: string };
     };
     restart_required: boolean;
+    // PUT /upstream: result of the runtime switch
+    switch?: {
+        from: UpstreamSelected;
+        to: UpstreamSelected;
+        base_url: string;
+        provider: string;
+        aborted_in_flight: boolean;
+        logged_in: boolean;
+        login_error?: string;
+        catalog_refreshed: boolean;
+        duration_ms: number;
+    };
 }
 
 export const GetUpstream = async (): Promise<UpstreamConfigResponse | null> => {
@@ -529,10 +529,19 @@ func handleGetProvisioningJobList(c *fiber.Ctx) error {
 				var completionTime int64
 				_ = arows.Scan(&neID, &aStatus, &completionTime, &reqType, &initialValue, &targetValue, &errorMsg, &errorDesc)
 				action := fiber.Map{
-					"neId":        neID,
-					"status":      aStatus,
-					"actionType":  reqType,
-					"targetValue": targetValue,
+					"neId":           neID,
+					"status":         aStatus,
+					"completionTime": completionTime,
+					"actionType":     reqType,
+					"requestType":    reqType,
+					"initialValue":   initialValue,
+					"targetValue":    targetValue,
+				}
+				if errorMsg != "" {
+					action["errorMsg"] = errorMsg
+				}
+				if errorDesc != "" {
+					action["errorDesc"] = errorDesc
 				}
 				actions = append(actions, action)
 			}
@@ -540,10 +549,11 @@ func handleGetProvisioningJobList(c *fiber.Ctx) error {
 		}
 
 		job := fiber.Map{
-			"jobId":       id,
-			"status":      status,
-			"requestTime": requestTime,
-			"actions":     actions,
+			"jobId":          id,
+			"status":         status,
+			"requestTime":    requestTime,
+			"lastActionTime": lastActionTime,
+			"actions":        actions,
 		}
 		jobs = append(jobs, job)
 	}
@@ -662,13 +672,13 @@ func handleUpdateProvisioningData(c *fiber.Ctx) error {
 			}
 
 			// Find current values to populate "initialValue".
-			var cli, status, ratePlan, label1, label2, label3 string
+			var cli, status, ratePlan, label1, label2, label3, simSwap string
 			row := db.QueryRow(
-				"SELECT cli, status, rate_plan, customer_label_1, customer_label_2, customer_label_3 FROM sim_cards WHERE cli = ? OR msisdn = ? LIMIT 1",
+				"SELECT cli, status, rate_plan, customer_label_1, customer_label_2, customer_label_3, COALESCE(sim_swap, '') FROM sim_cards WHERE cli = ? OR msisdn = ? LIMIT 1",
 				neID,
 				neID,
 			)
-			scanErr := row.Scan(&cli, &status, &ratePlan, &label1, &label2, &label3)
+			scanErr := row.Scan(&cli, &status, &ratePlan, &label1, &label2, &label3, &simSwap)
 
 			aStatus := "SUCCESS"
 			errorDesc := ""
@@ -701,6 +711,23 @@ func handleUpdateProvisioningData(c *fiber.Ctx) error {
 						errorMsg = "FAILED"
 						anyFailure = true
 					}
+				case strings.EqualFold(actionType, "SIM_SWAP"):
+					initialValue = simSwap
+					newICCID := strings.TrimSpace(action.TargetValue)
+					if newICCID == "" {
+						aStatus = "INVALID_REQ"
+						errorDesc = "targetValue (ICCID) is mandatory"
+						errorMsg = "INVALID_REQ"
+						anyFailure = true
+						break
+					}
+					_, err = db.Exec("UPDATE sim_cards SET sim_swap = ?, updated_at = CURRENT_TIMESTAMP WHERE cli = ?", newICCID, cli)
+					if err != nil {
+						aStatus = "FAILED"
+						errorDesc = err.Error()
+						errorMsg = "FAILED"
+						anyFailure = true
+					}
 				case strings.HasPrefix(strings.ToUpper(actionType), "CUSTOMER_LABEL_"):
 					upper := strings.ToUpper(actionType)
 					col := "customer_label_1"